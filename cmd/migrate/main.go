@@ -43,6 +43,22 @@ func main() {
 		&models.ForumPost{},
 		&models.ForumLike{},
 		&models.ForumCategory{},
+		&models.CrisisFlag{},
+		&models.SystemPrompt{},
+		&models.ChatFeedback{},
+		&models.AuthSession{},
+		&models.EmailOutbox{},
+		&models.EmailVerification{},
+		&models.UserIdentity{},
+		&models.OAuthState{},
+		&models.RecoveryCode{},
+		&models.LoginAttempt{},
+		&models.LoginLockout{},
+		&models.UserMoodTag{},
+		&models.ContentActivity{},
+		&models.ReminderPreference{},
+		&models.Notification{},
+		&models.PushSubscription{},
 	}
 
 	switch *action {
@@ -40,6 +40,22 @@ func main() {
 		&models.UserActivity{},
 		&models.LevelConfig{},
 		&models.ExpHistory{},
+		&models.CrisisFlag{},
+		&models.SystemPrompt{},
+		&models.ChatFeedback{},
+		&models.AuthSession{},
+		&models.EmailOutbox{},
+		&models.EmailVerification{},
+		&models.UserIdentity{},
+		&models.OAuthState{},
+		&models.RecoveryCode{},
+		&models.LoginAttempt{},
+		&models.LoginLockout{},
+		&models.UserMoodTag{},
+		&models.ContentActivity{},
+		&models.ReminderPreference{},
+		&models.Notification{},
+		&models.PushSubscription{},
 	); err != nil {
 		log.Printf("⚠️ Failed to drop tables (might not exist): %v", err)
 	}
@@ -61,6 +77,22 @@ func main() {
 		&models.UserActivity{},
 		&models.LevelConfig{},
 		&models.ExpHistory{},
+		&models.CrisisFlag{},
+		&models.SystemPrompt{},
+		&models.ChatFeedback{},
+		&models.AuthSession{},
+		&models.EmailOutbox{},
+		&models.EmailVerification{},
+		&models.UserIdentity{},
+		&models.OAuthState{},
+		&models.RecoveryCode{},
+		&models.LoginAttempt{},
+		&models.LoginLockout{},
+		&models.UserMoodTag{},
+		&models.ContentActivity{},
+		&models.ReminderPreference{},
+		&models.Notification{},
+		&models.PushSubscription{},
 	); err != nil {
 		log.Fatalf("❌ Failed to migrate database: %v", err)
 	}
@@ -68,6 +100,7 @@ func main() {
 	log.Println("🌱 Starting database seeder...")
 
 	seedLevels(db)
+	seedPrompts(db)
 	seedUsers(db)
 	seedArticles(db)
 	seedSongs(db)
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"log"
+	"os"
+	"strings"
+
+	"github.com/Alfian57/ruang-tenang-api/internal/models"
+	"gorm.io/gorm"
+)
+
+// seedPrompts imports prompts/ai_prompt.txt as the first active chat prompt version
+func seedPrompts(db *gorm.DB) {
+	log.Println("📝 Seeding system prompts...")
+
+	content, err := os.ReadFile("prompts/ai_prompt.txt")
+	if err != nil || strings.TrimSpace(string(content)) == "" {
+		log.Println("  ⚠ prompts/ai_prompt.txt not found, skipping")
+		return
+	}
+
+	var existing models.SystemPrompt
+	if db.Where("name = ? AND locale = ?", models.SystemPromptChat, "id").First(&existing).RowsAffected > 0 {
+		return
+	}
+
+	prompt := models.SystemPrompt{
+		Name:     models.SystemPromptChat,
+		Locale:   "id",
+		Version:  1,
+		Content:  strings.TrimSpace(string(content)),
+		Notes:    "Imported from prompts/ai_prompt.txt",
+		IsActive: true,
+		Weight:   100,
+	}
+	db.Create(&prompt)
+	log.Printf("  ✓ Created system prompt: %s/%s v%d", prompt.Name, prompt.Locale, prompt.Version)
+}
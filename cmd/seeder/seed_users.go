@@ -2,6 +2,7 @@ package main
 
 import (
 	"log"
+	"time"
 
 	"github.com/Alfian57/ruang-tenang-api/internal/models"
 	"github.com/Alfian57/ruang-tenang-api/pkg/utils"
@@ -20,12 +21,14 @@ func seedUsers(db *gorm.DB) {
 		log.Fatalf("Failed to hash member password: %v", err)
 	}
 
+	verifiedAt := time.Now()
+
 	users := []models.User{
-		{Name: "Admin", Email: "admin@ruangtenang.id", Password: adminPassword, Role: models.RoleAdmin, Exp: 0},
-		{Name: "John Doe", Email: "john@example.com", Password: memberPassword, Role: models.RoleMember, Exp: 850},
-		{Name: "Alfian Gading Saputra", Email: "alfian@gmail.com", Password: memberPassword, Role: models.RoleMember, Exp: 1200},
-		{Name: "Dery Wahyu", Email: "dery@gmail.com", Password: memberPassword, Role: models.RoleMember, Exp: 2300},
-		{Name: "Andhika Khusna", Email: "andhika@gmail.com", Password: memberPassword, Role: models.RoleMember, Exp: 450},
+		{Name: "Admin", Email: "admin@ruangtenang.id", Password: adminPassword, Role: models.RoleAdmin, Exp: 0, EmailVerifiedAt: &verifiedAt},
+		{Name: "John Doe", Email: "john@example.com", Password: memberPassword, Role: models.RoleMember, Exp: 850, EmailVerifiedAt: &verifiedAt},
+		{Name: "Alfian Gading Saputra", Email: "alfian@gmail.com", Password: memberPassword, Role: models.RoleMember, Exp: 1200, EmailVerifiedAt: &verifiedAt},
+		{Name: "Dery Wahyu", Email: "dery@gmail.com", Password: memberPassword, Role: models.RoleMember, Exp: 2300, EmailVerifiedAt: &verifiedAt},
+		{Name: "Andhika Khusna", Email: "andhika@gmail.com", Password: memberPassword, Role: models.RoleMember, Exp: 450, EmailVerifiedAt: &verifiedAt},
 	}
 
 	for _, user := range users {
@@ -40,8 +43,9 @@ func seedUsers(db *gorm.DB) {
 			}
 		} else {
 			if err := db.Model(&existing).Updates(map[string]interface{}{
-				"password": user.Password,
-				"exp":      user.Exp,
+				"password":          user.Password,
+				"exp":               user.Exp,
+				"email_verified_at": user.EmailVerifiedAt,
 			}).Error; err != nil {
 				log.Printf("  ❌ Failed to update user %s: %v", user.Email, err)
 			} else {
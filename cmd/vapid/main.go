@@ -0,0 +1,19 @@
+// Command vapid generates a VAPID key pair for Web Push notifications
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/Alfian57/ruang-tenang-api/pkg/webpush"
+)
+
+func main() {
+	publicKey, privateKey, err := webpush.GenerateVAPIDKeys()
+	if err != nil {
+		log.Fatalf("Failed to generate VAPID keys: %v", err)
+	}
+
+	fmt.Printf("VAPID_PUBLIC_KEY=%s\n", publicKey)
+	fmt.Printf("VAPID_PRIVATE_KEY=%s\n", privateKey)
+}
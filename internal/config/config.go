@@ -1,6 +1,8 @@
 package config
 
 import (
+	"time"
+
 	"github.com/joho/godotenv"
 	"github.com/spf13/viper"
 )
@@ -17,6 +19,67 @@ type Config struct {
 	JWTExpiryHours int    `mapstructure:"JWT_EXPIRY_HOURS"`
 	ClientOrigin   string `mapstructure:"CLIENT_ORIGIN"`
 	GeminiAPIKey   string `mapstructure:"GEMINI_API_KEY"`
+	LLMProvider    string `mapstructure:"LLM_PROVIDER"`
+	LLMModel       string `mapstructure:"LLM_MODEL"`
+	OpenAIAPIKey   string `mapstructure:"OPENAI_API_KEY"`
+	OpenAIBaseURL  string `mapstructure:"OPENAI_BASE_URL"`
+	STTProvider    string `mapstructure:"STT_PROVIDER"`
+	STTModel       string `mapstructure:"STT_MODEL"`
+
+	LLMTimeout time.Duration `mapstructure:"LLM_TIMEOUT"`
+	STTTimeout time.Duration `mapstructure:"STT_TIMEOUT"`
+
+	JWTAccessTTL  time.Duration `mapstructure:"JWT_ACCESS_TTL"`
+	JWTRefreshTTL time.Duration `mapstructure:"JWT_REFRESH_TTL"`
+
+	CrisisModelClassifier bool `mapstructure:"CRISIS_MODEL_CLASSIFIER"`
+
+	ChatSummaryInterval    int `mapstructure:"CHAT_SUMMARY_INTERVAL"`
+	ChatHistoryTokenBudget int `mapstructure:"CHAT_HISTORY_TOKEN_BUDGET"`
+
+	ChatTrashRetentionDays int           `mapstructure:"CHAT_TRASH_RETENTION_DAYS"`
+	ChatTrashPurgeInterval time.Duration `mapstructure:"CHAT_TRASH_PURGE_INTERVAL"`
+
+	MailDriver         string        `mapstructure:"MAIL_DRIVER"`
+	MailFrom           string        `mapstructure:"MAIL_FROM"`
+	MailFromName       string        `mapstructure:"MAIL_FROM_NAME"`
+	MailFileDir        string        `mapstructure:"MAIL_FILE_DIR"`
+	SMTPHost           string        `mapstructure:"SMTP_HOST"`
+	SMTPPort           int           `mapstructure:"SMTP_PORT"`
+	SMTPUsername       string        `mapstructure:"SMTP_USERNAME"`
+	SMTPPassword       string        `mapstructure:"SMTP_PASSWORD"`
+	SMTPEncryption     string        `mapstructure:"SMTP_ENCRYPTION"`
+	EmailQueueInterval time.Duration `mapstructure:"EMAIL_QUEUE_INTERVAL"`
+	PasswordResetURL   string        `mapstructure:"PASSWORD_RESET_URL"`
+
+	EmailVerificationURL string `mapstructure:"EMAIL_VERIFICATION_URL"`
+
+	OIDCRedirectURL        string `mapstructure:"OIDC_REDIRECT_URL"`
+	OIDCGoogleClientID     string `mapstructure:"OIDC_GOOGLE_CLIENT_ID"`
+	OIDCGoogleClientSecret string `mapstructure:"OIDC_GOOGLE_CLIENT_SECRET"`
+	OIDCGoogleIssuer       string `mapstructure:"OIDC_GOOGLE_ISSUER"`
+
+	TwoFactorRequiredForAdmins bool   `mapstructure:"TWO_FACTOR_REQUIRED_FOR_ADMINS"`
+	TwoFactorEncryptionKey     string `mapstructure:"TWO_FACTOR_ENCRYPTION_KEY"`
+
+	LoginThrottleStore   string        `mapstructure:"LOGIN_THROTTLE_STORE"`
+	LoginMaxFailures     int           `mapstructure:"LOGIN_MAX_FAILURES"`
+	LoginIPMaxFailures   int           `mapstructure:"LOGIN_IP_MAX_FAILURES"`
+	LoginFailureWindow   time.Duration `mapstructure:"LOGIN_FAILURE_WINDOW"`
+	LoginLockoutDuration time.Duration `mapstructure:"LOGIN_LOCKOUT_DURATION"`
+
+	AccountDeletionGracePeriod time.Duration `mapstructure:"ACCOUNT_DELETION_GRACE_PERIOD"`
+
+	NotificationQueueInterval time.Duration `mapstructure:"NOTIFICATION_QUEUE_INTERVAL"`
+	ReminderInterval          time.Duration `mapstructure:"REMINDER_INTERVAL"`
+	MoodCheckInURL            string        `mapstructure:"MOOD_CHECK_IN_URL"`
+	VAPIDPublicKey            string        `mapstructure:"VAPID_PUBLIC_KEY"`
+	VAPIDPrivateKey           string        `mapstructure:"VAPID_PRIVATE_KEY"`
+	VAPIDSubject              string        `mapstructure:"VAPID_SUBJECT"`
+
+	PasswordResetIPLimit     int           `mapstructure:"PASSWORD_RESET_IP_LIMIT"`
+	PasswordResetEmailLimit  int           `mapstructure:"PASSWORD_RESET_EMAIL_LIMIT"`
+	PasswordResetLimitWindow time.Duration `mapstructure:"PASSWORD_RESET_LIMIT_WINDOW"`
 }
 
 var AppConfig *Config
@@ -37,7 +100,35 @@ func LoadConfig() (*Config, error) {
 	viper.SetDefault("DB_NAME", "ruang_tenang")
 	viper.SetDefault("JWT_SECRET", "your-super-secret-jwt-key")
 	viper.SetDefault("JWT_EXPIRY_HOURS", 24)
+	viper.SetDefault("JWT_ACCESS_TTL", "15m")
+	viper.SetDefault("JWT_REFRESH_TTL", "720h")
 	viper.SetDefault("CLIENT_ORIGIN", "http://localhost:3000")
+	viper.SetDefault("LLM_TIMEOUT", "2m")
+	viper.SetDefault("STT_TIMEOUT", "1m")
+	viper.SetDefault("CHAT_SUMMARY_INTERVAL", 10)
+	viper.SetDefault("CHAT_HISTORY_TOKEN_BUDGET", 3000)
+	viper.SetDefault("CHAT_TRASH_RETENTION_DAYS", 30)
+	viper.SetDefault("CHAT_TRASH_PURGE_INTERVAL", "1h")
+	viper.SetDefault("MAIL_DRIVER", "console")
+	viper.SetDefault("MAIL_FROM", "no-reply@ruangtenang.id")
+	viper.SetDefault("MAIL_FROM_NAME", "Ruang Tenang")
+	viper.SetDefault("MAIL_FILE_DIR", "storage/mail")
+	viper.SetDefault("SMTP_PORT", 587)
+	viper.SetDefault("SMTP_ENCRYPTION", "starttls")
+	viper.SetDefault("EMAIL_QUEUE_INTERVAL", "15s")
+	viper.SetDefault("PASSWORD_RESET_IP_LIMIT", 10)
+	viper.SetDefault("PASSWORD_RESET_EMAIL_LIMIT", 3)
+	viper.SetDefault("PASSWORD_RESET_LIMIT_WINDOW", "1h")
+	viper.SetDefault("TWO_FACTOR_REQUIRED_FOR_ADMINS", false)
+	viper.SetDefault("LOGIN_THROTTLE_STORE", "memory")
+	viper.SetDefault("LOGIN_MAX_FAILURES", 5)
+	viper.SetDefault("LOGIN_IP_MAX_FAILURES", 20)
+	viper.SetDefault("LOGIN_FAILURE_WINDOW", "15m")
+	viper.SetDefault("LOGIN_LOCKOUT_DURATION", "15m")
+	viper.SetDefault("ACCOUNT_DELETION_GRACE_PERIOD", "336h")
+	viper.SetDefault("NOTIFICATION_QUEUE_INTERVAL", "15s")
+	viper.SetDefault("REMINDER_INTERVAL", "1m")
+	viper.SetDefault("VAPID_SUBJECT", "mailto:no-reply@ruangtenang.id")
 
 	if err := viper.ReadInConfig(); err != nil {
 		// It's okay if .env doesn't exist, we can read from env vars
@@ -55,6 +146,67 @@ func LoadConfig() (*Config, error) {
 		JWTExpiryHours: viper.GetInt("JWT_EXPIRY_HOURS"),
 		ClientOrigin:   viper.GetString("CLIENT_ORIGIN"),
 		GeminiAPIKey:   viper.GetString("GEMINI_API_KEY"),
+		LLMProvider:    viper.GetString("LLM_PROVIDER"),
+		LLMModel:       viper.GetString("LLM_MODEL"),
+		OpenAIAPIKey:   viper.GetString("OPENAI_API_KEY"),
+		OpenAIBaseURL:  viper.GetString("OPENAI_BASE_URL"),
+		STTProvider:    viper.GetString("STT_PROVIDER"),
+		STTModel:       viper.GetString("STT_MODEL"),
+
+		LLMTimeout: viper.GetDuration("LLM_TIMEOUT"),
+		STTTimeout: viper.GetDuration("STT_TIMEOUT"),
+
+		JWTAccessTTL:  viper.GetDuration("JWT_ACCESS_TTL"),
+		JWTRefreshTTL: viper.GetDuration("JWT_REFRESH_TTL"),
+
+		CrisisModelClassifier: viper.GetBool("CRISIS_MODEL_CLASSIFIER"),
+
+		ChatSummaryInterval:    viper.GetInt("CHAT_SUMMARY_INTERVAL"),
+		ChatHistoryTokenBudget: viper.GetInt("CHAT_HISTORY_TOKEN_BUDGET"),
+
+		ChatTrashRetentionDays: viper.GetInt("CHAT_TRASH_RETENTION_DAYS"),
+		ChatTrashPurgeInterval: viper.GetDuration("CHAT_TRASH_PURGE_INTERVAL"),
+
+		MailDriver:         viper.GetString("MAIL_DRIVER"),
+		MailFrom:           viper.GetString("MAIL_FROM"),
+		MailFromName:       viper.GetString("MAIL_FROM_NAME"),
+		MailFileDir:        viper.GetString("MAIL_FILE_DIR"),
+		SMTPHost:           viper.GetString("SMTP_HOST"),
+		SMTPPort:           viper.GetInt("SMTP_PORT"),
+		SMTPUsername:       viper.GetString("SMTP_USERNAME"),
+		SMTPPassword:       viper.GetString("SMTP_PASSWORD"),
+		SMTPEncryption:     viper.GetString("SMTP_ENCRYPTION"),
+		EmailQueueInterval: viper.GetDuration("EMAIL_QUEUE_INTERVAL"),
+		PasswordResetURL:   viper.GetString("PASSWORD_RESET_URL"),
+
+		EmailVerificationURL: viper.GetString("EMAIL_VERIFICATION_URL"),
+
+		OIDCRedirectURL:        viper.GetString("OIDC_REDIRECT_URL"),
+		OIDCGoogleClientID:     viper.GetString("OIDC_GOOGLE_CLIENT_ID"),
+		OIDCGoogleClientSecret: viper.GetString("OIDC_GOOGLE_CLIENT_SECRET"),
+		OIDCGoogleIssuer:       viper.GetString("OIDC_GOOGLE_ISSUER"),
+
+		TwoFactorRequiredForAdmins: viper.GetBool("TWO_FACTOR_REQUIRED_FOR_ADMINS"),
+		TwoFactorEncryptionKey:     viper.GetString("TWO_FACTOR_ENCRYPTION_KEY"),
+
+		LoginThrottleStore:   viper.GetString("LOGIN_THROTTLE_STORE"),
+		LoginMaxFailures:     viper.GetInt("LOGIN_MAX_FAILURES"),
+		LoginIPMaxFailures:   viper.GetInt("LOGIN_IP_MAX_FAILURES"),
+		LoginFailureWindow:   viper.GetDuration("LOGIN_FAILURE_WINDOW"),
+		LoginLockoutDuration: viper.GetDuration("LOGIN_LOCKOUT_DURATION"),
+
+		AccountDeletionGracePeriod: viper.GetDuration("ACCOUNT_DELETION_GRACE_PERIOD"),
+
+		NotificationQueueInterval: viper.GetDuration("NOTIFICATION_QUEUE_INTERVAL"),
+		ReminderInterval:          viper.GetDuration("REMINDER_INTERVAL"),
+		MoodCheckInURL:            viper.GetString("MOOD_CHECK_IN_URL"),
+		VAPIDPublicKey:            viper.GetString("VAPID_PUBLIC_KEY"),
+		VAPIDPrivateKey:           viper.GetString("VAPID_PRIVATE_KEY"),
+		VAPIDSubject:              viper.GetString("VAPID_SUBJECT"),
+
+		PasswordResetIPLimit:     viper.GetInt("PASSWORD_RESET_IP_LIMIT"),
+		PasswordResetEmailLimit:  viper.GetInt("PASSWORD_RESET_EMAIL_LIMIT"),
+		PasswordResetLimitWindow: viper.GetDuration("PASSWORD_RESET_LIMIT_WINDOW"),
 	}
 
 	AppConfig = config
@@ -12,8 +12,10 @@ import (
 var DB *gorm.DB
 
 func Connect(cfg *config.Config) (*gorm.DB, error) {
+	// Sessions run in UTC; days are counted in each user's own timezone, see
+	// pkg/clock. Every timestamp column is TIMESTAMPTZ since migration 000041.
 	dsn := fmt.Sprintf(
-		"host=%s user=%s password=%s dbname=%s port=%s sslmode=disable TimeZone=Asia/Jakarta",
+		"host=%s user=%s password=%s dbname=%s port=%s sslmode=disable TimeZone=UTC",
 		cfg.DBHost,
 		cfg.DBUser,
 		cfg.DBPassword,
@@ -0,0 +1,99 @@
+package dto
+
+import "time"
+
+// Personal data export. Each type is one JSON file in the ZIP archive.
+
+type DataExportProfileDTO struct {
+	ID                  uint       `json:"id"`
+	Name                string     `json:"name"`
+	Email               string     `json:"email,omitempty"`
+	Pseudonym           string     `json:"pseudonym,omitempty"`
+	IsAnonymous         bool       `json:"is_anonymous"`
+	Role                string     `json:"role"`
+	Avatar              string     `json:"avatar"`
+	Exp                 int64      `json:"exp"`
+	EmailVerifiedAt     *time.Time `json:"email_verified_at"`
+	TwoFactorEnabled    bool       `json:"two_factor_enabled"`
+	LinkedProviders     []string   `json:"linked_providers"`
+	Timezone            string     `json:"timezone"`
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at,omitempty"`
+	CreatedAt           time.Time  `json:"created_at"`
+	ExportedAt          time.Time  `json:"exported_at"`
+}
+
+type DataExportMoodDTO struct {
+	ID            uint      `json:"id"`
+	Mood          string    `json:"mood"`
+	Intensity     *int      `json:"intensity"`
+	Note          string    `json:"note"`
+	SocialContext string    `json:"social_context"`
+	Activities    []string  `json:"activities"`
+	Triggers      []string  `json:"triggers"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+type DataExportChatSessionDTO struct {
+	ID         uint             `json:"id"`
+	Title      string           `json:"title"`
+	IsFavorite bool             `json:"is_favorite"`
+	IsTrash    bool             `json:"is_trash"`
+	CreatedAt  time.Time        `json:"created_at"`
+	Messages   []ChatMessageDTO `json:"messages"`
+}
+
+type DataExportForumDTO struct {
+	ID         uint      `json:"id"`
+	CategoryID *uint     `json:"category_id"`
+	Title      string    `json:"title"`
+	Content    string    `json:"content"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type DataExportForumPostDTO struct {
+	ID        uint      `json:"id"`
+	ForumID   uint      `json:"forum_id"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type DataExportArticleDTO struct {
+	ID         uint      `json:"id"`
+	CategoryID uint      `json:"category_id"`
+	Title      string    `json:"title"`
+	Thumbnail  string    `json:"thumbnail"`
+	Content    string    `json:"content"`
+	Status     string    `json:"status"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type DataExportExpHistoryDTO struct {
+	ActivityType string    `json:"activity_type"`
+	Points       int       `json:"points"`
+	Description  string    `json:"description"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type DataExportContentActivityDTO struct {
+	ContentType string    `json:"content_type"`
+	ContentID   uint      `json:"content_id"`
+	CategoryID  *uint     `json:"category_id"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Account deletion
+
+// DeleteAccountRequest confirms the deletion request. Password is required
+// for accounts that have one, Code when two-factor authentication is on.
+type DeleteAccountRequest struct {
+	Password string `json:"password"`
+	Code     string `json:"code" binding:"max=32"`
+	Locale   string `json:"locale"`
+}
+
+type AccountDeletionDTO struct {
+	Scheduled   bool       `json:"scheduled"`
+	ScheduledAt *time.Time `json:"scheduled_at,omitempty"` // when the account and its data will be erased
+}
@@ -1,49 +1,115 @@
 package dto
 
+import "time"
+
 // Auth DTOs
 type RegisterRequest struct {
 	Name     string `json:"name" binding:"required,min=2,max=100"`
 	Email    string `json:"email" binding:"required,email"`
 	Password string `json:"password" binding:"required,min=6"`
+	Locale   string `json:"locale"`   // verification email language, defaults to Accept-Language
+	Timezone string `json:"timezone"` // IANA name from the browser; unknown names fall back to Asia/Jakarta
+}
+
+// AnonymousRegisterRequest creates an account without name or email. A
+// pseudonym is generated when none is given; it is also the login name.
+type AnonymousRegisterRequest struct {
+	Pseudonym string `json:"pseudonym" binding:"max=30"`
+	Password  string `json:"password" binding:"required,min=6"`
+	Timezone  string `json:"timezone"` // as in RegisterRequest
+}
+
+// UpgradeAccountRequest turns an anonymous account into a full one once the
+// email is verified
+type UpgradeAccountRequest struct {
+	Name   string `json:"name" binding:"required,min=2,max=100"`
+	Email  string `json:"email" binding:"required,email"`
+	Locale string `json:"locale"`
 }
 
+// UpdatePseudonymRequest sets the name shown on community content; empty
+// clears it for full accounts
+type UpdatePseudonymRequest struct {
+	Pseudonym string `json:"pseudonym" binding:"max=30"`
+}
+
+// LoginRequest signs in with email, or with the pseudonym of an anonymous account
 type LoginRequest struct {
-	Email      string `json:"email" binding:"required,email"`
+	Email      string `json:"email" binding:"required_without=Pseudonym,omitempty,email"`
+	Pseudonym  string `json:"pseudonym" binding:"required_without=Email,omitempty,max=30"`
 	Password   string `json:"password" binding:"required"`
 	RememberMe bool   `json:"remember_me"`
+	DeviceName string `json:"device_name" binding:"max=100"` // optional label shown in the device list
+}
+
+// TokenPair is a short-lived access token plus the refresh token that renews it
+type TokenPair struct {
+	Token            string    `json:"token"`
+	ExpiresIn        int       `json:"expires_in"` // access token lifetime in seconds
+	RefreshToken     string    `json:"refresh_token"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+	SessionID        uint      `json:"session_id"`
 }
 
 type LoginResponse struct {
-	Token string  `json:"token"`
-	User  UserDTO `json:"user"`
+	TokenPair
+	User UserDTO `json:"user"`
+
+	// TwoFactor is set instead of tokens when the account needs a second step
+	TwoFactor *TwoFactorChallengeDTO `json:"-"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// AuthSessionDTO is one signed-in device
+type AuthSessionDTO struct {
+	ID         uint      `json:"id"`
+	DeviceName string    `json:"device_name"`
+	UserAgent  string    `json:"user_agent"`
+	IPAddress  string    `json:"ip_address"`
+	Current    bool      `json:"current"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	CreatedAt  time.Time `json:"created_at"`
 }
 
 type UpdateProfileRequest struct {
 	Name   string `json:"name" binding:"required,min=2,max=100"`
-	Email  string `json:"email" binding:"required,email"`
+	Email  string `json:"email" binding:"omitempty,email"` // a new address takes effect once verified; omitted by anonymous accounts
 	Avatar string `json:"avatar"`
+	Locale string `json:"locale"`
+}
+
+// UpdateTimezoneRequest sets where the user's days start, e.g. "Asia/Jayapura"
+type UpdateTimezoneRequest struct {
+	Timezone string `json:"timezone" binding:"required,max=64"`
 }
 
 type UpdatePasswordRequest struct {
 	CurrentPassword string `json:"current_password" binding:"required"`
 	NewPassword     string `json:"new_password" binding:"required,min=6"`
+	Locale          string `json:"locale"` // email language, defaults to Accept-Language
 }
 
 // ForgotPassword & ResetPassword
 type ForgotPasswordRequest struct {
-	Email string `json:"email" binding:"required,email"`
+	Email  string `json:"email" binding:"required,email"`
+	Locale string `json:"locale"` // email language, defaults to Accept-Language
 }
 
 type ResetPasswordRequest struct {
 	Token       string `json:"token" binding:"required"`
 	NewPassword string `json:"new_password" binding:"required,min=6"`
+	Locale      string `json:"locale"`
 }
 
 // User DTO
 type UserDTO struct {
 	ID        uint   `json:"id"`
 	Name      string `json:"name"`
-	Email     string `json:"email"`
+	Email     string `json:"email,omitempty"`
 	Avatar    string `json:"avatar"`
 	Role      string `json:"role"`
 	Exp       int64  `json:"exp"`
@@ -51,4 +117,59 @@ type UserDTO struct {
 	BadgeName string `json:"badge_name"`
 	BadgeIcon string `json:"badge_icon"`
 	CreatedAt string `json:"created_at"`
+
+	EmailVerified    bool   `json:"email_verified"`
+	PendingEmail     string `json:"pending_email,omitempty"` // requested new email awaiting verification
+	TwoFactorEnabled bool   `json:"two_factor_enabled"`
+	Pseudonym        string `json:"pseudonym,omitempty"`
+	IsAnonymous      bool   `json:"is_anonymous"`
+	Timezone         string `json:"timezone"`
+
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at,omitempty"`
+}
+
+// Email verification
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type ResendVerificationRequest struct {
+	Locale string `json:"locale"`
+}
+
+// Two-factor authentication
+
+// TwoFactorChallengeDTO is returned by login when a code is still needed
+type TwoFactorChallengeDTO struct {
+	TwoFactorRequired bool      `json:"two_factor_required"`
+	ChallengeToken    string    `json:"challenge_token"`
+	ExpiresAt         time.Time `json:"expires_at"`
+}
+
+type TwoFactorLoginRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code" binding:"required,max=32"` // authenticator or recovery code
+}
+
+// TwoFactorCodeRequest confirms a sensitive 2FA change with a current code
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" binding:"required,max=32"`
+}
+
+type TwoFactorStatusDTO struct {
+	Enabled                bool       `json:"enabled"`
+	EnabledAt              *time.Time `json:"enabled_at,omitempty"`
+	Required               bool       `json:"required"` // enforced by policy, can't be disabled
+	RecoveryCodesRemaining int        `json:"recovery_codes_remaining"`
+}
+
+// TwoFactorSetupDTO is shown once while enrolling; the URI is rendered as a
+// QR code by the client
+type TwoFactorSetupDTO struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+type RecoveryCodesDTO struct {
+	RecoveryCodes []string `json:"recovery_codes"`
 }
@@ -4,7 +4,7 @@ import "time"
 
 // Chat Session DTOs
 type CreateChatSessionRequest struct {
-	Title string `json:"title" binding:"required,min=1,max=255"`
+	Title string `json:"title" binding:"max=255"` // optional, generated from the first exchange when empty
 }
 
 type ChatSessionDTO struct {
@@ -12,6 +12,8 @@ type ChatSessionDTO struct {
 	Title       string           `json:"title"`
 	IsFavorite  bool             `json:"is_favorite"`
 	IsTrash     bool             `json:"is_trash"`
+	TrashedAt   *time.Time       `json:"trashed_at,omitempty"`
+	PurgeAt     *time.Time       `json:"purge_at,omitempty"` // when a trashed session is permanently deleted
 	LastMessage *ChatMessageDTO  `json:"last_message,omitempty"`
 	Messages    []ChatMessageDTO `json:"messages,omitempty"`
 	CreatedAt   time.Time        `json:"created_at"`
@@ -19,18 +21,22 @@ type ChatSessionDTO struct {
 }
 
 type ChatSessionListDTO struct {
-	ID          uint   `json:"id"`
-	Title       string `json:"title"`
-	IsFavorite  bool   `json:"is_favorite"`
-	IsTrash     bool   `json:"is_trash"`
-	LastMessage string `json:"last_message"`
-	CreatedAt   string `json:"created_at"`
+	ID          uint       `json:"id"`
+	Title       string     `json:"title"`
+	IsFavorite  bool       `json:"is_favorite"`
+	IsTrash     bool       `json:"is_trash"`
+	TrashedAt   *time.Time `json:"trashed_at,omitempty"`
+	PurgeAt     *time.Time `json:"purge_at,omitempty"`
+	LastMessage string     `json:"last_message"`
+	CreatedAt   string     `json:"created_at"`
 }
 
 // Chat Message DTOs
 type SendMessageRequest struct {
-	Content string `json:"content" binding:"required,min=1"`
-	Type    string `json:"type"` // "text" or "audio", defaults to "text"
+	Content  string `json:"content" binding:"required_without=AudioURL"` // ignored for audio, the transcript is used instead
+	Type     string `json:"type" binding:"omitempty,oneof=text audio"`   // defaults to "text"
+	AudioURL string `json:"audio_url" binding:"omitempty,max=500"`       // URL returned by /upload/audio, required for audio
+	Locale   string `json:"locale"`                                      // prompt locale, defaults to the Accept-Language header
 }
 
 type ChatMessageDTO struct {
@@ -38,11 +44,22 @@ type ChatMessageDTO struct {
 	Role       string    `json:"role"`
 	Content    string    `json:"content"`
 	Type       string    `json:"type"`
+	AudioURL   string    `json:"audio_url,omitempty"`
 	IsLiked    bool      `json:"is_liked"`
 	IsDisliked bool      `json:"is_disliked"`
 	CreatedAt  time.Time `json:"created_at"`
 }
 
+// ChatExportDTO is the downloadable JSON export of a chat session
+type ChatExportDTO struct {
+	ID         uint             `json:"id"`
+	Title      string           `json:"title"`
+	CreatedAt  time.Time        `json:"created_at"`
+	ExportedAt time.Time        `json:"exported_at"`
+	Messages   []ChatMessageDTO `json:"messages"`
+	Disclaimer string           `json:"disclaimer"`
+}
+
 // Query params
 type ChatSessionQueryParams struct {
 	Filter string `form:"filter"` // all, bookmarked, favorites
@@ -50,3 +67,7 @@ type ChatSessionQueryParams struct {
 	Page   int    `form:"page,default=1"`
 	Limit  int    `form:"limit,default=20"`
 }
+
+type ChatExportQueryParams struct {
+	Format string `form:"format,default=pdf" binding:"omitempty,oneof=pdf md markdown json"`
+}
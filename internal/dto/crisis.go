@@ -0,0 +1,35 @@
+package dto
+
+import "time"
+
+// Crisis flag DTOs
+type CrisisFlagDTO struct {
+	ID             uint       `json:"id"`
+	UserID         uint       `json:"user_id"`
+	UserName       string     `json:"user_name"`
+	UserEmail      string     `json:"user_email"`
+	ChatSessionID  uint       `json:"chat_session_id"`
+	ChatMessageID  uint       `json:"chat_message_id"`
+	MessageContent string     `json:"message_content"`
+	RiskLevel      string     `json:"risk_level"`
+	MatchedTerms   []string   `json:"matched_terms"`
+	Source         string     `json:"source"`
+	Status         string     `json:"status"`
+	ReviewNotes    string     `json:"review_notes"`
+	ReviewedBy     *uint      `json:"reviewed_by"`
+	ReviewedAt     *time.Time `json:"reviewed_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+type ReviewCrisisFlagRequest struct {
+	Status string `json:"status" binding:"required,oneof=reviewed escalated dismissed"`
+	Notes  string `json:"notes" binding:"max=2000"`
+}
+
+// Query params
+type CrisisFlagQueryParams struct {
+	Status    string `form:"status"`     // open, reviewed, escalated, dismissed
+	RiskLevel string `form:"risk_level"` // low, medium, high
+	Page      int    `form:"page,default=1"`
+	Limit     int    `form:"limit,default=20"`
+}
@@ -0,0 +1,51 @@
+package dto
+
+import "time"
+
+// Chat feedback DTOs
+type MessageFeedbackRequest struct {
+	Reason string `json:"reason" binding:"max=1000"`
+}
+
+type ChatFeedbackDTO struct {
+	ID             uint      `json:"id"`
+	ChatMessageID  uint      `json:"chat_message_id"`
+	UserID         uint      `json:"user_id"`
+	Rating         string    `json:"rating"`
+	Reason         string    `json:"reason"`
+	MessageContent string    `json:"message_content"`
+	SystemPromptID *uint     `json:"system_prompt_id"`
+	Provider       string    `json:"provider"`
+	Model          string    `json:"model"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// FeedbackBucketDTO is the satisfaction of one day, prompt version or model
+type FeedbackBucketDTO struct {
+	Key              string  `json:"key"`
+	Label            string  `json:"label,omitempty"`
+	Likes            int64   `json:"likes"`
+	Dislikes         int64   `json:"dislikes"`
+	Total            int64   `json:"total"`
+	SatisfactionRate float64 `json:"satisfaction_rate"` // likes / total, in percent
+}
+
+type FeedbackAnalyticsDTO struct {
+	Days     int                 `json:"days"`
+	Overall  FeedbackBucketDTO   `json:"overall"`
+	ByDay    []FeedbackBucketDTO `json:"by_day"`
+	ByPrompt []FeedbackBucketDTO `json:"by_prompt"`
+	ByModel  []FeedbackBucketDTO `json:"by_model"`
+}
+
+// Query params
+type FeedbackQueryParams struct {
+	Rating string `form:"rating"` // like, dislike, none
+	Days   int    `form:"days,default=30"`
+	Page   int    `form:"page,default=1"`
+	Limit  int    `form:"limit,default=20"`
+}
+
+type FeedbackAnalyticsQueryParams struct {
+	Days int `form:"days,default=30"`
+}
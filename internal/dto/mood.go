@@ -3,15 +3,32 @@ package dto
 import "time"
 
 // User Mood DTOs
+
+// CreateMoodRequest logs a mood check-in. Activities and triggers are free
+// tags, e.g. "olahraga" or "deadline".
 type CreateMoodRequest struct {
-	Mood string `json:"mood" binding:"required,oneof=happy neutral angry disappointed sad crying"`
+	Mood          string   `json:"mood" binding:"required,oneof=happy neutral angry disappointed sad crying"`
+	Intensity     *int     `json:"intensity" binding:"omitempty,min=1,max=10"`
+	Note          string   `json:"note" binding:"max=2000"`
+	SocialContext string   `json:"social_context" binding:"omitempty,oneof=alone family friends partner colleagues others"`
+	Activities    []string `json:"activities" binding:"max=10,dive,max=30"`
+	Triggers      []string `json:"triggers" binding:"max=10,dive,max=30"`
 }
 
+// UpdateMoodRequest replaces every field of a check-in
+type UpdateMoodRequest = CreateMoodRequest
+
 type UserMoodDTO struct {
-	ID        uint      `json:"id"`
-	Mood      string    `json:"mood"`
-	Emoji     string    `json:"emoji"`
-	CreatedAt time.Time `json:"created_at"`
+	ID            uint      `json:"id"`
+	Mood          string    `json:"mood"`
+	Emoji         string    `json:"emoji"`
+	Intensity     *int      `json:"intensity"`
+	Note          string    `json:"note"`
+	SocialContext string    `json:"social_context"`
+	Activities    []string  `json:"activities"`
+	Triggers      []string  `json:"triggers"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
 }
 
 type MoodHistoryDTO struct {
@@ -19,10 +36,130 @@ type MoodHistoryDTO struct {
 	TotalCount int64         `json:"total_count"`
 }
 
+// MoodTagDTO is a tag the user has used, for suggestions
+type MoodTagDTO struct {
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+}
+
+type MoodTagsDTO struct {
+	Activities []MoodTagDTO `json:"activities"`
+	Triggers   []MoodTagDTO `json:"triggers"`
+}
+
 // Query params
 type MoodQueryParams struct {
 	StartDate string `form:"start_date"` // YYYY-MM-DD
 	EndDate   string `form:"end_date"`   // YYYY-MM-DD
+	Mood      string `form:"mood" binding:"omitempty,oneof=happy neutral angry disappointed sad crying"`
+	Tag       string `form:"tag"` // activity or trigger name
 	Page      int    `form:"page,default=1"`
 	Limit     int    `form:"limit,default=30"`
 }
+
+// Mood analytics. Valence averages use the -2..2 scale in ValenceScale and
+// are null for periods without check-ins.
+
+type MoodAnalyticsQueryParams struct {
+	Days int `form:"days,default=30"` // calendar length, up to 365
+}
+
+type MoodAnalyticsDTO struct {
+	From           string           `json:"from"` // YYYY-MM-DD, inclusive
+	To             string           `json:"to"`
+	Timezone       string           `json:"timezone"`
+	ValenceScale   map[string]int   `json:"valence_scale"`
+	TotalCheckIns  int              `json:"total_check_ins"`
+	DaysLogged     int              `json:"days_logged"`
+	AverageValence *float64         `json:"average_valence"`
+	Streak         MoodStreakDTO    `json:"streak"`
+	WeekOverWeek   MoodChangeDTO    `json:"week_over_week"`
+	Calendar       []MoodDayDTO     `json:"calendar"`
+	Weekly         []MoodPeriodDTO  `json:"weekly"`
+	Monthly        []MoodPeriodDTO  `json:"monthly"`
+	DayOfWeek      []MoodPatternDTO `json:"day_of_week"`
+	TimeOfDay      []MoodPatternDTO `json:"time_of_day"`
+	MoodCounts     map[string]int   `json:"mood_counts"`
+}
+
+// MoodDayDTO is one day of the calendar heatmap
+type MoodDayDTO struct {
+	Date           string   `json:"date"`
+	CheckIns       int      `json:"check_ins"`
+	AverageValence *float64 `json:"average_valence"`
+	DominantMood   string   `json:"dominant_mood,omitempty"`
+	RollingAverage *float64 `json:"rolling_average"` // over the 7 days ending on this date
+}
+
+// MoodPeriodDTO aggregates a week (starting Monday) or a calendar month
+type MoodPeriodDTO struct {
+	Start          string   `json:"start"`
+	CheckIns       int      `json:"check_ins"`
+	DaysLogged     int      `json:"days_logged"`
+	AverageValence *float64 `json:"average_valence"`
+}
+
+// MoodStreakDTO counts consecutive days with at least one check-in. The
+// current streak stays alive until a full day is missed.
+type MoodStreakDTO struct {
+	Current      int    `json:"current"`
+	Longest      int    `json:"longest"`
+	LastLoggedOn string `json:"last_logged_on,omitempty"`
+}
+
+// MoodChangeDTO compares the last 7 days with the 7 days before
+type MoodChangeDTO struct {
+	CurrentAverage   *float64 `json:"current_average"`
+	PreviousAverage  *float64 `json:"previous_average"`
+	Change           *float64 `json:"change"`
+	CurrentCheckIns  int      `json:"current_check_ins"`
+	PreviousCheckIns int      `json:"previous_check_ins"`
+}
+
+// MoodPatternDTO aggregates check-ins by weekday or part of the day
+type MoodPatternDTO struct {
+	Key            string   `json:"key"`
+	CheckIns       int      `json:"check_ins"`
+	AverageValence *float64 `json:"average_valence"`
+}
+
+// Mood insights relate daily mood valence to what the user did in the app
+// on the same or the previous day. They show correlation, not causation.
+
+type MoodInsightsQueryParams struct {
+	Days   int    `form:"days,default=90"` // up to 365
+	Locale string `form:"locale"`          // statement language, defaults to Accept-Language
+}
+
+type MoodInsightsDTO struct {
+	From         string               `json:"from"`
+	To           string               `json:"to"`
+	DaysWithMood int                  `json:"days_with_mood"`
+	Insights     []MoodInsightDTO     `json:"insights"`
+	Correlations []MoodCorrelationDTO `json:"correlations"`
+}
+
+// MoodInsightDTO is a notable correlation phrased for the user
+type MoodInsightDTO struct {
+	Statement  string  `json:"statement"`
+	Activity   string  `json:"activity"`
+	CategoryID *uint   `json:"category_id,omitempty"`
+	Lag        string  `json:"lag"`       // same_day or next_day
+	Direction  string  `json:"direction"` // better or worse
+	Difference float64 `json:"difference"`
+}
+
+// MoodCorrelationDTO compares the average daily valence of days with and
+// without an activity. Averages are null with fewer than 3 days on a side.
+type MoodCorrelationDTO struct {
+	Activity       string   `json:"activity"` // chat_ai, forum_comment, upload_article, read_article or listen_song
+	CategoryID     *uint    `json:"category_id,omitempty"`
+	Label          string   `json:"label,omitempty"` // song category name
+	Lag            string   `json:"lag"`
+	ActivityDays   int      `json:"activity_days"`
+	DaysWith       int      `json:"days_with"`
+	DaysWithout    int      `json:"days_without"`
+	AverageWith    *float64 `json:"average_with"`
+	AverageWithout *float64 `json:"average_without"`
+	Difference     *float64 `json:"difference"`
+}
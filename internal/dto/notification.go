@@ -0,0 +1,81 @@
+package dto
+
+import "time"
+
+// Mood check-in reminders
+
+// UpdateReminderPreferenceRequest replaces the user's reminder settings.
+// Times and quiet hours are "HH:MM" in the user's timezone, set with
+// PUT /auth/timezone. Quiet hours may span midnight.
+// Enabled reminders need at least one time, day and channel.
+type UpdateReminderPreferenceRequest struct {
+	Enabled    bool     `json:"enabled"`
+	Times      []string `json:"times" binding:"max=3"`
+	Days       []string `json:"days" binding:"dive,oneof=monday tuesday wednesday thursday friday saturday sunday"`
+	QuietStart string   `json:"quiet_start"`
+	QuietEnd   string   `json:"quiet_end"`
+	Channels   []string `json:"channels" binding:"dive,oneof=in_app email web_push"`
+	Locale     string   `json:"locale"` // language of the reminders, defaults to Accept-Language
+}
+
+type ReminderPreferenceDTO struct {
+	Enabled           bool       `json:"enabled"`
+	Times             []string   `json:"times"`
+	Days              []string   `json:"days"`
+	Timezone          string     `json:"timezone"` // the user's timezone
+	QuietStart        string     `json:"quiet_start"`
+	QuietEnd          string     `json:"quiet_end"`
+	Channels          []string   `json:"channels"`
+	AvailableChannels []string   `json:"available_channels"`
+	Locale            string     `json:"locale"`
+	NextReminderAt    *time.Time `json:"next_reminder_at"`
+	LastRemindedAt    *time.Time `json:"last_reminded_at"`
+}
+
+// Notifications inbox
+
+type NotificationQueryParams struct {
+	Unread bool `form:"unread"` // only unread notifications
+	Page   int  `form:"page,default=1"`
+	Limit  int  `form:"limit,default=20"`
+}
+
+type NotificationDTO struct {
+	ID        uint       `json:"id"`
+	Type      string     `json:"type"`
+	Title     string     `json:"title"`
+	Body      string     `json:"body"`
+	Link      string     `json:"link"`
+	ReadAt    *time.Time `json:"read_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type UnreadNotificationsDTO struct {
+	Unread int64 `json:"unread"`
+}
+
+type MarkAllNotificationsReadDTO struct {
+	Marked int64 `json:"marked"`
+}
+
+// Web Push
+
+// PushConfigDTO tells the web app whether push is available and the VAPID
+// key to pass to PushManager.subscribe() as applicationServerKey
+type PushConfigDTO struct {
+	Enabled   bool   `json:"enabled"`
+	PublicKey string `json:"public_key,omitempty"`
+}
+
+// PushSubscriptionRequest is the browser's PushSubscription.toJSON()
+type PushSubscriptionRequest struct {
+	Endpoint string `json:"endpoint" binding:"required,url,startswith=https://"`
+	Keys     struct {
+		P256dh string `json:"p256dh" binding:"required"`
+		Auth   string `json:"auth" binding:"required"`
+	} `json:"keys" binding:"required"`
+}
+
+type PushUnsubscribeRequest struct {
+	Endpoint string `json:"endpoint" binding:"required"`
+}
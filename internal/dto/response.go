@@ -6,6 +6,7 @@ type Response struct {
 	Message string      `json:"message,omitempty"`
 	Data    interface{} `json:"data,omitempty"`
 	Error   string      `json:"error,omitempty"`
+	Code    string      `json:"code,omitempty"` // machine-readable error code
 }
 
 // Pagination response
@@ -34,6 +35,15 @@ func ErrorResponse(err string) Response {
 	}
 }
 
+// ErrorResponseWithCode adds a machine-readable code clients can branch on
+func ErrorResponseWithCode(code, err string) Response {
+	return Response{
+		Success: false,
+		Error:   err,
+		Code:    code,
+	}
+}
+
 func NewPaginatedResponse(data interface{}, page, limit int, total int64) PaginatedResponse {
 	totalPages := int(total) / limit
 	if int(total)%limit > 0 {
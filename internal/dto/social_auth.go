@@ -0,0 +1,34 @@
+package dto
+
+import "time"
+
+// Social login (OpenID Connect) DTOs
+type OIDCAuthorizeResponse struct {
+	Provider         string    `json:"provider"`
+	AuthorizationURL string    `json:"authorization_url"` // send the browser here
+	State            string    `json:"state"`
+	ExpiresAt        time.Time `json:"expires_at"`
+}
+
+// OIDCCallbackRequest carries the code and state the provider redirected back with
+type OIDCCallbackRequest struct {
+	Code       string `json:"code" binding:"required"`
+	State      string `json:"state" binding:"required"`
+	RememberMe bool   `json:"remember_me"`
+	DeviceName string `json:"device_name" binding:"max=100"`
+}
+
+type UserIdentityDTO struct {
+	Provider    string     `json:"provider"`
+	Email       string     `json:"email"`
+	LinkedAt    time.Time  `json:"linked_at"`
+	LastLoginAt *time.Time `json:"last_login_at"`
+}
+
+// SignInMethodsDTO lists how a user can sign in, so clients know whether
+// unlinking a provider is allowed
+type SignInMethodsDTO struct {
+	HasPassword bool              `json:"has_password"`
+	Identities  []UserIdentityDTO `json:"identities"`
+	Available   []string          `json:"available_providers"`
+}
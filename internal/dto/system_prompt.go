@@ -0,0 +1,43 @@
+package dto
+
+import "time"
+
+// System prompt DTOs
+type SystemPromptDTO struct {
+	ID         uint      `json:"id"`
+	Name       string    `json:"name"`
+	Locale     string    `json:"locale"`
+	Version    int       `json:"version"`
+	Content    string    `json:"content"`
+	Notes      string    `json:"notes"`
+	IsActive   bool      `json:"is_active"`
+	Weight     int       `json:"weight"`
+	UsageCount int64     `json:"usage_count"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type CreateSystemPromptRequest struct {
+	Name    string `json:"name" binding:"omitempty,max=50"` // defaults to "chat"
+	Locale  string `json:"locale" binding:"required,min=2,max=10"`
+	Content string `json:"content" binding:"required,min=1"`
+	Notes   string `json:"notes" binding:"max=255"`
+	Weight  int    `json:"weight" binding:"omitempty,gte=1,lte=1000"`
+}
+
+type UpdateSystemPromptRequest struct {
+	Content string `json:"content" binding:"required,min=1"`
+	Notes   string `json:"notes" binding:"max=255"`
+	Weight  int    `json:"weight" binding:"omitempty,gte=1,lte=1000"`
+}
+
+type ActivateSystemPromptRequest struct {
+	// Exclusive deactivates every other version of the same prompt and locale
+	Exclusive bool `json:"exclusive"`
+}
+
+// Query params
+type SystemPromptQueryParams struct {
+	Name   string `form:"name"`
+	Locale string `form:"locale"`
+}
@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/Alfian57/ruang-tenang-api/internal/dto"
+	"github.com/Alfian57/ruang-tenang-api/internal/middleware"
+	"github.com/Alfian57/ruang-tenang-api/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type AccountHandler struct {
+	accountService *services.AccountService
+}
+
+func NewAccountHandler(accountService *services.AccountService) *AccountHandler {
+	return &AccountHandler{accountService: accountService}
+}
+
+// ExportData godoc
+// @Summary Download my data
+// @Description Download everything stored about your account (profile, moods, chat sessions with messages and voice notes, forum topics and replies, articles and EXP history) as a ZIP of JSON files
+// @Tags Account
+// @Produce application/zip
+// @Security BearerAuth
+// @Success 200 {file} file
+// @Failure 429 {object} dto.Response
+// @Router /account/export [get]
+func (h *AccountHandler) ExportData(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+
+	export, err := h.accountService.ExportData(userID)
+	if err != nil {
+		accountError(c, err)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, export.Filename))
+	c.Data(http.StatusOK, "application/zip", export.Data)
+}
+
+// GetDeletionStatus godoc
+// @Summary Get account deletion status
+// @Description Show whether the account is scheduled for deletion and when it will be erased
+// @Tags Account
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.AccountDeletionDTO
+// @Router /account/deletion [get]
+func (h *AccountHandler) GetDeletionStatus(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+
+	status, err := h.accountService.GetDeletionStatus(userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse(status, "Account deletion status retrieved"))
+}
+
+// RequestDeletion godoc
+// @Summary Delete my account
+// @Description Schedule the account for permanent deletion after a grace period, during which it can still be used and the deletion cancelled. Afterwards the account and all its data, including chats and moods, are erased. Requires the password, and an authenticator or recovery code when two-factor authentication is on.
+// @Tags Account
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.DeleteAccountRequest true "Confirmation"
+// @Success 200 {object} dto.AccountDeletionDTO
+// @Failure 400 {object} dto.Response
+// @Failure 409 {object} dto.Response
+// @Router /account/deletion [post]
+func (h *AccountHandler) RequestDeletion(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+
+	var req dto.DeleteAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse(err.Error()))
+		return
+	}
+	if req.Locale == "" {
+		req.Locale = c.GetHeader("Accept-Language")
+	}
+
+	status, err := h.accountService.RequestDeletion(userID, &req)
+	if err != nil {
+		accountError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse(status, "Account scheduled for deletion"))
+}
+
+// CancelDeletion godoc
+// @Summary Cancel account deletion
+// @Description Keep the account when its deletion grace period hasn't ended yet
+// @Tags Account
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.Response
+// @Failure 400 {object} dto.Response
+// @Router /account/deletion [delete]
+func (h *AccountHandler) CancelDeletion(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+
+	if err := h.accountService.CancelDeletion(userID); err != nil {
+		accountError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse(nil, "Account deletion cancelled"))
+}
+
+// accountError maps data export and account deletion errors to responses
+func accountError(c *gin.Context, err error) {
+	var limited *services.RateLimitError
+	switch {
+	case errors.As(err, &limited):
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(limited.RetryAfter.Seconds()))))
+		c.JSON(http.StatusTooManyRequests, dto.ErrorResponseWithCode(limited.Err.Code, err.Error()))
+	case err == services.ErrDeletionScheduled:
+		c.JSON(http.StatusConflict, dto.ErrorResponse(err.Error()))
+	case err == services.ErrIncorrectPassword, err == services.ErrInvalidTwoFactorCode, err == services.ErrDeletionNotScheduled:
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse(err.Error()))
+	default:
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse(err.Error()))
+	}
+}
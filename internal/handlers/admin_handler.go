@@ -2,11 +2,15 @@ package handlers
 
 import (
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/Alfian57/ruang-tenang-api/internal/dto"
+	"github.com/Alfian57/ruang-tenang-api/internal/middleware"
 	"github.com/Alfian57/ruang-tenang-api/internal/models"
 	"github.com/Alfian57/ruang-tenang-api/internal/repositories"
+	"github.com/Alfian57/ruang-tenang-api/internal/services"
+	"github.com/Alfian57/ruang-tenang-api/pkg/clock"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
@@ -15,13 +19,15 @@ type AdminHandler struct {
 	db          *gorm.DB
 	userRepo    *repositories.UserRepository
 	articleRepo *repositories.ArticleRepository
+	authService *services.AuthService
 }
 
-func NewAdminHandler(db *gorm.DB, userRepo *repositories.UserRepository, articleRepo *repositories.ArticleRepository) *AdminHandler {
+func NewAdminHandler(db *gorm.DB, userRepo *repositories.UserRepository, articleRepo *repositories.ArticleRepository, authService *services.AuthService) *AdminHandler {
 	return &AdminHandler{
 		db:          db,
 		userRepo:    userRepo,
 		articleRepo: articleRepo,
+		authService: authService,
 	}
 }
 
@@ -34,8 +40,9 @@ func NewAdminHandler(db *gorm.DB, userRepo *repositories.UserRepository, article
 // @Success 200 {object} dto.Response
 // @Router /admin/stats [get]
 func (h *AdminHandler) GetDashboardStats(c *gin.Context) {
-	now := time.Now()
-	todayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	// Platform-wide stats count days in the app's default timezone
+	now := time.Now().In(clock.Default())
+	todayStart := clock.StartOfDay(now, now.Location())
 	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
 	lastMonthStart := monthStart.AddDate(0, -1, 0)
 	lastMonthEnd := monthStart.Add(-time.Second)
@@ -95,6 +102,10 @@ func (h *AdminHandler) GetDashboardStats(c *gin.Context) {
 	var moodsToday int64
 	h.db.Model(&models.UserMood{}).Where("created_at >= ?", todayStart).Count(&moodsToday)
 
+	// Crisis flags awaiting review
+	var openCrisisFlags int64
+	h.db.Model(&models.CrisisFlag{}).Where("status = ?", models.CrisisFlagOpen).Count(&openCrisisFlags)
+
 	// Weekly chart data for users
 	userChartData := make([]int64, 7)
 	for i := 6; i >= 0; i-- {
@@ -171,6 +182,9 @@ func (h *AdminHandler) GetDashboardStats(c *gin.Context) {
 			"total": totalMoods,
 			"today": moodsToday,
 		},
+		"crisis_flags": gin.H{
+			"open": openCrisisFlags,
+		},
 		"recent_users": recentUsersDTO,
 	}, ""))
 }
@@ -251,7 +265,7 @@ func (h *AdminHandler) DeleteUser(c *gin.Context) {
 
 // BlockUser godoc
 // @Summary Block a user
-// @Description Block a user by ID (admin only)
+// @Description Block a user by ID and sign them out of every device (admin only)
 // @Tags Admin
 // @Produce json
 // @Security BearerAuth
@@ -279,6 +293,12 @@ func (h *AdminHandler) BlockUser(c *gin.Context) {
 		return
 	}
 
+	// Sign the user out everywhere so the block takes effect immediately
+	if err := h.authService.InvalidateUserTokens(user.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse("User blocked but failed to revoke sessions"))
+		return
+	}
+
 	c.JSON(http.StatusOK, dto.SuccessResponse(nil, "User blocked"))
 }
 
@@ -309,6 +329,92 @@ func (h *AdminHandler) UnblockUser(c *gin.Context) {
 	c.JSON(http.StatusOK, dto.SuccessResponse(nil, "User unblocked"))
 }
 
+// UnlockUser godoc
+// @Summary Unlock a user's sign-in
+// @Description End a lockout caused by repeated failed sign-ins (admin only)
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "User ID"
+// @Success 200 {object} dto.Response
+// @Router /admin/users/{id}/unlock [put]
+func (h *AdminHandler) UnlockUser(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid user ID"))
+		return
+	}
+	adminID, _ := middleware.GetUserID(c)
+
+	if err := h.authService.UnlockAccount(uint(id), adminID); err != nil {
+		if err.Error() == "user not found" {
+			c.JSON(http.StatusNotFound, dto.ErrorResponse("User not found"))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse(nil, "User unlocked"))
+}
+
+// GetLoginLockouts godoc
+// @Summary List sign-in lockouts
+// @Description Audit trail of accounts and IPs locked after repeated failed sign-ins (admin only)
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param user_id query int false "Only lockouts of this user"
+// @Param page query int false "Page number"
+// @Param limit query int false "Items per page"
+// @Success 200 {object} dto.PaginatedResponse
+// @Router /admin/login-lockouts [get]
+func (h *AdminHandler) GetLoginLockouts(c *gin.Context) {
+	var params struct {
+		UserID uint `form:"user_id"`
+		Page   int  `form:"page"`
+		Limit  int  `form:"limit"`
+	}
+	c.ShouldBindQuery(&params)
+
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	if params.Limit < 1 || params.Limit > 50 {
+		params.Limit = 10
+	}
+
+	lockouts, total, err := h.authService.GetLockouts(params.UserID, params.Page, params.Limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to get lockouts"))
+		return
+	}
+
+	now := time.Now()
+	result := make([]gin.H, len(lockouts))
+	for i, l := range lockouts {
+		item := gin.H{
+			"id":           l.ID,
+			"scope":        l.Scope,
+			"user_id":      l.UserID,
+			"email":        l.Email,
+			"ip_address":   l.IPAddress,
+			"failures":     l.Failures,
+			"locked_until": l.LockedUntil,
+			"active":       l.UnlockedAt == nil && l.LockedUntil.After(now),
+			"unlocked_at":  l.UnlockedAt,
+			"unlocked_by":  l.UnlockedBy,
+			"created_at":   l.CreatedAt,
+		}
+		if l.User != nil {
+			item["user_name"] = l.User.Name
+		}
+		result[i] = item
+	}
+
+	c.JSON(http.StatusOK, dto.NewPaginatedResponse(result, params.Page, params.Limit, total))
+}
+
 // CreateArticle godoc
 // @Summary Create an article
 // @Description Create a new article (admin only)
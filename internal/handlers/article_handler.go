@@ -5,6 +5,7 @@ import (
 	"strconv"
 
 	"github.com/Alfian57/ruang-tenang-api/internal/dto"
+	"github.com/Alfian57/ruang-tenang-api/internal/middleware"
 	"github.com/Alfian57/ruang-tenang-api/internal/models"
 	"github.com/Alfian57/ruang-tenang-api/internal/services"
 	"github.com/gin-gonic/gin"
@@ -283,3 +284,29 @@ func (h *ArticleHandler) GetArticleByIDForUser(c *gin.Context) {
 
 	c.JSON(http.StatusOK, dto.SuccessResponse(article, ""))
 }
+
+// RecordRead godoc
+// @Summary Record article read
+// @Description Note that the user read a published article, so mood insights can relate moods to reading. Rereads within 30 minutes count once.
+// @Tags Articles
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Article ID"
+// @Success 200 {object} dto.Response
+// @Failure 404 {object} dto.Response
+// @Router /articles/{id}/reads [post]
+func (h *ArticleHandler) RecordRead(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid article ID"))
+		return
+	}
+
+	if err := h.articleService.RecordRead(userID, uint(id)); err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse("Article not found"))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse(nil, "Read recorded"))
+}
@@ -1,7 +1,10 @@
 package handlers
 
 import (
+	"errors"
+	"math"
 	"net/http"
+	"strconv"
 
 	"github.com/Alfian57/ruang-tenang-api/internal/dto"
 	"github.com/Alfian57/ruang-tenang-api/internal/middleware"
@@ -13,12 +16,14 @@ import (
 type AuthHandler struct {
 	authService        *services.AuthService
 	levelConfigService *services.LevelConfigService
+	reminderService    *services.ReminderService
 }
 
-func NewAuthHandler(authService *services.AuthService, levelConfigService *services.LevelConfigService) *AuthHandler {
+func NewAuthHandler(authService *services.AuthService, levelConfigService *services.LevelConfigService, reminderService *services.ReminderService) *AuthHandler {
 	return &AuthHandler{
 		authService:        authService,
 		levelConfigService: levelConfigService,
+		reminderService:    reminderService,
 	}
 }
 
@@ -32,10 +37,25 @@ func (h *AuthHandler) buildUserDTO(user *models.User) dto.UserDTO {
 		Role:      string(user.Role),
 		Exp:       user.Exp,
 		CreatedAt: user.CreatedAt.Format("2006-01-02T15:04:05Z"),
+
+		EmailVerified:    user.IsEmailVerified(),
+		TwoFactorEnabled: user.HasTwoFactor(),
+		Pseudonym:        user.PseudonymOrEmpty(),
+		IsAnonymous:      user.IsAnonymous,
+		Timezone:         user.Timezone,
+
+		DeletionScheduledAt: user.DeletionScheduledAt,
+		PendingEmail:        h.authService.PendingEmail(user),
 	}
 
-	// Get level info
-	currentLevel, _, _ := h.levelConfigService.GetUserLevelInfo(user.Exp)
+	setLevelInfo(h.levelConfigService, &userDTO)
+
+	return userDTO
+}
+
+// setLevelInfo fills in the level and badge for the user's experience points
+func setLevelInfo(levelConfigService *services.LevelConfigService, userDTO *dto.UserDTO) {
+	currentLevel, _, _ := levelConfigService.GetUserLevelInfo(userDTO.Exp)
 	if currentLevel != nil {
 		userDTO.Level = currentLevel.Level
 		userDTO.BadgeName = currentLevel.BadgeName
@@ -46,13 +66,11 @@ func (h *AuthHandler) buildUserDTO(user *models.User) dto.UserDTO {
 		userDTO.BadgeName = "Pemula"
 		userDTO.BadgeIcon = "🌱"
 	}
-
-	return userDTO
 }
 
 // Register godoc
 // @Summary Register new user
-// @Description Register a new user account
+// @Description Register a new user account and email a verification link. Posting in forums and writing articles require a verified email.
 // @Tags Auth
 // @Accept json
 // @Produce json
@@ -67,6 +85,10 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		return
 	}
 
+	if req.Locale == "" {
+		req.Locale = c.GetHeader("Accept-Language")
+	}
+
 	user, err := h.authService.Register(&req)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, dto.ErrorResponse(err.Error()))
@@ -76,15 +98,45 @@ func (h *AuthHandler) Register(c *gin.Context) {
 	c.JSON(http.StatusCreated, dto.SuccessResponse(h.buildUserDTO(user), "Registration successful"))
 }
 
+// RegisterAnonymous godoc
+// @Summary Register anonymous account
+// @Description Create an account with only a pseudonym and password; a pseudonym is generated when omitted. Sign in with the pseudonym. Without an email the password can't be reset, so upgrade the account to add one.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body dto.AnonymousRegisterRequest true "Anonymous register request"
+// @Success 201 {object} dto.Response
+// @Failure 400 {object} dto.Response
+// @Failure 409 {object} dto.Response
+// @Failure 429 {object} dto.Response
+// @Router /auth/register/anonymous [post]
+func (h *AuthHandler) RegisterAnonymous(c *gin.Context) {
+	var req dto.AnonymousRegisterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse(err.Error()))
+		return
+	}
+
+	user, err := h.authService.RegisterAnonymous(&req, c.ClientIP())
+	if err != nil {
+		pseudonymError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.SuccessResponse(h.buildUserDTO(user), "Registration successful"))
+}
+
 // Login godoc
 // @Summary Login user
-// @Description Authenticate user and return JWT token
+// @Description Authenticate with email, or pseudonym for anonymous accounts, and return a short-lived access token plus a refresh token
 // @Tags Auth
 // @Accept json
 // @Produce json
 // @Param request body dto.LoginRequest true "Login request"
-// @Success 200 {object} dto.LoginResponse
+// @Success 200 {object} dto.LoginResponse "Tokens, or dto.TwoFactorChallengeDTO when the account has two-factor authentication"
 // @Failure 401 {object} dto.Response
+// @Failure 403 {object} dto.Response "Account blocked (code account_blocked)"
+// @Failure 429 {object} dto.Response "Too many failed attempts (code account_locked or too_many_requests)"
 // @Router /auth/login [post]
 func (h *AuthHandler) Login(c *gin.Context) {
 	var req dto.LoginRequest
@@ -93,24 +145,35 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	response, err := h.authService.Login(&req)
+	response, err := h.authService.Login(&req, clientInfo(c))
 	if err != nil {
+		var limited *services.RateLimitError
+		if errors.As(err, &limited) {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(limited.RetryAfter.Seconds()))))
+			c.JSON(http.StatusTooManyRequests, dto.ErrorResponseWithCode(limited.Err.Code, err.Error()))
+			return
+		}
+		if err == services.ErrAccountBlocked {
+			c.JSON(http.StatusForbidden, dto.ErrorResponseWithCode(services.ErrAccountBlocked.Code, err.Error()))
+			return
+		}
 		c.JSON(http.StatusUnauthorized, dto.ErrorResponse(err.Error()))
 		return
 	}
 
-	// Add level info to the user in response
-	currentLevel, _, _ := h.levelConfigService.GetUserLevelInfo(response.User.Exp)
-	if currentLevel != nil {
-		response.User.Level = currentLevel.Level
-		response.User.BadgeName = currentLevel.BadgeName
-		response.User.BadgeIcon = currentLevel.BadgeIcon
-	} else {
-		response.User.Level = 1
-		response.User.BadgeName = "Pemula"
-		response.User.BadgeIcon = "🌱"
+	respondLogin(c, h.levelConfigService, response)
+}
+
+// respondLogin sends either the session tokens or the two-factor challenge
+func respondLogin(c *gin.Context, levelConfigService *services.LevelConfigService, response *dto.LoginResponse) {
+	if response.TwoFactor != nil {
+		c.JSON(http.StatusOK, dto.SuccessResponse(response.TwoFactor, "Two-factor authentication required"))
+		return
 	}
 
+	// Add level info to the user in response
+	setLevelInfo(levelConfigService, &response.User)
+
 	c.JSON(http.StatusOK, dto.SuccessResponse(response, "Login successful"))
 }
 
@@ -137,7 +200,7 @@ func (h *AuthHandler) GetProfile(c *gin.Context) {
 
 // UpdateProfile godoc
 // @Summary Update user profile
-// @Description Update authenticated user's profile
+// @Description Update authenticated user's profile. A changed email is only applied after it is verified.
 // @Tags Auth
 // @Accept json
 // @Produce json
@@ -155,18 +218,208 @@ func (h *AuthHandler) UpdateProfile(c *gin.Context) {
 		return
 	}
 
+	if req.Locale == "" {
+		req.Locale = c.GetHeader("Accept-Language")
+	}
+
 	user, err := h.authService.UpdateProfile(userID, &req)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, dto.ErrorResponse(err.Error()))
 		return
 	}
 
-	c.JSON(http.StatusOK, dto.SuccessResponse(h.buildUserDTO(user), "Profile updated successfully"))
+	userDTO := h.buildUserDTO(user)
+	message := "Profile updated successfully"
+	if req.Email != "" && userDTO.PendingEmail == req.Email {
+		message = "Profile updated. Check your new email address to confirm the change."
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse(userDTO, message))
+}
+
+// UpgradeAccount godoc
+// @Summary Upgrade anonymous account
+// @Description Add a name and email to an anonymous account. It becomes a full account, keeping its data and pseudonym, once the email is verified.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.UpgradeAccountRequest true "Upgrade request"
+// @Success 200 {object} dto.UserDTO
+// @Failure 400 {object} dto.Response
+// @Router /auth/upgrade [post]
+func (h *AuthHandler) UpgradeAccount(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+
+	var req dto.UpgradeAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse(err.Error()))
+		return
+	}
+
+	if req.Locale == "" {
+		req.Locale = c.GetHeader("Accept-Language")
+	}
+
+	user, err := h.authService.UpgradeAccount(userID, &req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse(h.buildUserDTO(user), "Check your email to finish upgrading your account"))
+}
+
+// UpdatePseudonym godoc
+// @Summary Set pseudonym
+// @Description Set the name shown on forums, articles and the leaderboard instead of your real name. Send an empty pseudonym to clear it (full accounts only).
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.UpdatePseudonymRequest true "Pseudonym"
+// @Success 200 {object} dto.UserDTO
+// @Failure 400 {object} dto.Response
+// @Failure 409 {object} dto.Response
+// @Router /auth/pseudonym [put]
+func (h *AuthHandler) UpdatePseudonym(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+
+	var req dto.UpdatePseudonymRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse(err.Error()))
+		return
+	}
+
+	user, err := h.authService.UpdatePseudonym(userID, req.Pseudonym)
+	if err != nil {
+		pseudonymError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse(h.buildUserDTO(user), "Pseudonym updated"))
+}
+
+// UpdateTimezone godoc
+// @Summary Set timezone
+// @Description Set the IANA timezone (e.g. "Asia/Makassar") that decides when your day starts for mood check-ins, daily EXP limits and reminders
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.UpdateTimezoneRequest true "Timezone"
+// @Success 200 {object} dto.UserDTO
+// @Failure 400 {object} dto.Response
+// @Router /auth/timezone [put]
+func (h *AuthHandler) UpdateTimezone(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+
+	var req dto.UpdateTimezoneRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse(err.Error()))
+		return
+	}
+
+	user, err := h.authService.UpdateTimezone(userID, req.Timezone)
+	if err != nil {
+		if err == services.ErrInvalidTimezone {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse(err.Error()))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse(err.Error()))
+		return
+	}
+
+	// Reminder times are local, so the next one moves with the timezone
+	if err := h.reminderService.Reschedule(userID); err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to reschedule reminders"))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse(h.buildUserDTO(user), "Timezone updated"))
+}
+
+// pseudonymError maps pseudonym and anonymous sign-up errors to responses
+func pseudonymError(c *gin.Context, err error) {
+	var limited *services.RateLimitError
+	switch {
+	case errors.As(err, &limited):
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(limited.RetryAfter.Seconds()))))
+		c.JSON(http.StatusTooManyRequests, dto.ErrorResponseWithCode(limited.Err.Code, err.Error()))
+	case err == services.ErrPseudonymTaken:
+		c.JSON(http.StatusConflict, dto.ErrorResponse(err.Error()))
+	case err == services.ErrInvalidPseudonym, err == services.ErrPseudonymRequired:
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse(err.Error()))
+	default:
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse(err.Error()))
+	}
+}
+
+// VerifyEmail godoc
+// @Summary Verify email address
+// @Description Confirm an email address with the token from the verification email. For an email change the new address takes effect now.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body dto.VerifyEmailRequest true "Verification token"
+// @Success 200 {object} dto.UserDTO
+// @Failure 400 {object} dto.Response
+// @Router /auth/verify-email [post]
+func (h *AuthHandler) VerifyEmail(c *gin.Context) {
+	var req dto.VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse(err.Error()))
+		return
+	}
+
+	user, err := h.authService.ConfirmEmail(req.Token)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse(h.buildUserDTO(user), "Email verified"))
+}
+
+// ResendVerification godoc
+// @Summary Resend verification email
+// @Description Send a new verification link for the pending email change, or for the current address if it isn't verified
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.ResendVerificationRequest false "Email language"
+// @Success 200 {object} dto.Response
+// @Failure 400 {object} dto.Response
+// @Failure 429 {object} dto.Response
+// @Router /auth/verify-email/resend [post]
+func (h *AuthHandler) ResendVerification(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+
+	var req dto.ResendVerificationRequest
+	_ = c.ShouldBindJSON(&req)
+
+	if req.Locale == "" {
+		req.Locale = c.GetHeader("Accept-Language")
+	}
+
+	if err := h.authService.ResendVerification(userID, req.Locale); err != nil {
+		var limited *services.RateLimitError
+		if errors.As(err, &limited) {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(limited.RetryAfter.Seconds()))))
+			c.JSON(http.StatusTooManyRequests, dto.ErrorResponseWithCode(limited.Err.Code, err.Error()))
+			return
+		}
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse(nil, "Verification email sent"))
 }
 
 // UpdatePassword godoc
 // @Summary Update password
-// @Description Update authenticated user's password
+// @Description Update authenticated user's password and sign out all other devices
 // @Tags Auth
 // @Accept json
 // @Produce json
@@ -184,7 +437,13 @@ func (h *AuthHandler) UpdatePassword(c *gin.Context) {
 		return
 	}
 
-	if err := h.authService.UpdatePassword(userID, &req); err != nil {
+	sessionID, _ := middleware.GetSessionID(c)
+
+	if req.Locale == "" {
+		req.Locale = c.GetHeader("Accept-Language")
+	}
+
+	if err := h.authService.UpdatePassword(userID, sessionID, &req); err != nil {
 		c.JSON(http.StatusBadRequest, dto.ErrorResponse(err.Error()))
 		return
 	}
@@ -194,13 +453,14 @@ func (h *AuthHandler) UpdatePassword(c *gin.Context) {
 
 // ForgotPassword godoc
 // @Summary Request password reset
-// @Description Request a password reset token to be sent to email
+// @Description Email a password reset link. The email language follows locale or Accept-Language (id, en).
 // @Tags Auth
 // @Accept json
 // @Produce json
 // @Param request body dto.ForgotPasswordRequest true "Forgot password request"
 // @Success 200 {object} dto.Response
 // @Failure 400 {object} dto.Response
+// @Failure 429 {object} dto.Response
 // @Router /auth/forgot-password [post]
 func (h *AuthHandler) ForgotPassword(c *gin.Context) {
 	var req dto.ForgotPasswordRequest
@@ -209,17 +469,27 @@ func (h *AuthHandler) ForgotPassword(c *gin.Context) {
 		return
 	}
 
-	if err := h.authService.ForgotPassword(&req); err != nil {
+	if req.Locale == "" {
+		req.Locale = c.GetHeader("Accept-Language")
+	}
+
+	if err := h.authService.ForgotPassword(&req, c.ClientIP()); err != nil {
+		var limited *services.RateLimitError
+		if errors.As(err, &limited) {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(limited.RetryAfter.Seconds()))))
+			c.JSON(http.StatusTooManyRequests, dto.ErrorResponseWithCode(limited.Err.Code, err.Error()))
+			return
+		}
 		c.JSON(http.StatusInternalServerError, dto.ErrorResponse(err.Error()))
 		return
 	}
 
-	c.JSON(http.StatusOK, dto.SuccessResponse(nil, "If the email is registered, a reset token has been sent."))
+	c.JSON(http.StatusOK, dto.SuccessResponse(nil, "If the email is registered, a reset link has been sent."))
 }
 
 // ResetPassword godoc
 // @Summary Reset password
-// @Description Reset password using token
+// @Description Reset password using a single-use token. Signs the user out on every device.
 // @Tags Auth
 // @Accept json
 // @Produce json
@@ -234,6 +504,10 @@ func (h *AuthHandler) ResetPassword(c *gin.Context) {
 		return
 	}
 
+	if req.Locale == "" {
+		req.Locale = c.GetHeader("Accept-Language")
+	}
+
 	if err := h.authService.ResetPassword(&req); err != nil {
 		c.JSON(http.StatusBadRequest, dto.ErrorResponse(err.Error()))
 		return
@@ -241,3 +515,313 @@ func (h *AuthHandler) ResetPassword(c *gin.Context) {
 
 	c.JSON(http.StatusOK, dto.SuccessResponse(nil, "Password has been reset successfully."))
 }
+
+// Refresh godoc
+// @Summary Refresh access token
+// @Description Exchange a refresh token for a new access token. The refresh token is rotated; the old one stops working.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body dto.RefreshTokenRequest true "Refresh token"
+// @Success 200 {object} dto.TokenPair
+// @Failure 401 {object} dto.Response
+// @Failure 403 {object} dto.Response "Account blocked (code account_blocked)"
+// @Router /auth/refresh [post]
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req dto.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse(err.Error()))
+		return
+	}
+
+	tokens, err := h.authService.Refresh(req.RefreshToken, clientInfo(c))
+	if err != nil {
+		if err == services.ErrInvalidRefreshToken {
+			c.JSON(http.StatusUnauthorized, dto.ErrorResponse(err.Error()))
+			return
+		}
+		if err == services.ErrAccountBlocked {
+			c.JSON(http.StatusForbidden, dto.ErrorResponseWithCode(services.ErrAccountBlocked.Code, err.Error()))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to refresh token"))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse(tokens, ""))
+}
+
+// Logout godoc
+// @Summary Logout
+// @Description Sign out the current device; its access and refresh tokens stop working
+// @Tags Auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.Response
+// @Router /auth/logout [post]
+func (h *AuthHandler) Logout(c *gin.Context) {
+	sessionID, _ := middleware.GetSessionID(c)
+
+	if err := h.authService.Logout(sessionID); err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to logout"))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse(nil, "Logged out"))
+}
+
+// GetSessions godoc
+// @Summary List signed-in devices
+// @Description List the active sessions of the authenticated user
+// @Tags Auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.Response
+// @Router /auth/sessions [get]
+func (h *AuthHandler) GetSessions(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+	sessionID, _ := middleware.GetSessionID(c)
+
+	sessions, err := h.authService.GetSessions(userID, sessionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to get sessions"))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse(sessions, ""))
+}
+
+// RevokeSession godoc
+// @Summary Sign out a device
+// @Description Revoke one of the authenticated user's sessions
+// @Tags Auth
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Session ID"
+// @Success 200 {object} dto.Response
+// @Failure 404 {object} dto.Response
+// @Router /auth/sessions/{id} [delete]
+func (h *AuthHandler) RevokeSession(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+	sessionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid session ID"))
+		return
+	}
+
+	if err := h.authService.RevokeSession(userID, uint(sessionID)); err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse(nil, "Session revoked"))
+}
+
+// RevokeOtherSessions godoc
+// @Summary Sign out all other devices
+// @Description Revoke every session of the authenticated user except the current one
+// @Tags Auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.Response
+// @Router /auth/sessions [delete]
+func (h *AuthHandler) RevokeOtherSessions(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+	sessionID, _ := middleware.GetSessionID(c)
+
+	revoked, err := h.authService.RevokeOtherSessions(userID, sessionID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to revoke sessions"))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse(gin.H{
+		"revoked": revoked,
+	}, "Other sessions revoked"))
+}
+
+// VerifyTwoFactorLogin godoc
+// @Summary Complete two-factor sign-in
+// @Description Exchange the login challenge and an authenticator or recovery code for tokens
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body dto.TwoFactorLoginRequest true "Challenge and code"
+// @Success 200 {object} dto.LoginResponse
+// @Failure 401 {object} dto.Response
+// @Failure 429 {object} dto.Response
+// @Router /auth/login/2fa [post]
+func (h *AuthHandler) VerifyTwoFactorLogin(c *gin.Context) {
+	var req dto.TwoFactorLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse(err.Error()))
+		return
+	}
+
+	response, err := h.authService.VerifyTwoFactorLogin(&req, clientInfo(c))
+	if err != nil {
+		twoFactorError(c, err)
+		return
+	}
+
+	respondLogin(c, h.levelConfigService, response)
+}
+
+// GetTwoFactorStatus godoc
+// @Summary Get two-factor status
+// @Description Whether two-factor authentication is enabled and how many recovery codes are left
+// @Tags Auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.TwoFactorStatusDTO
+// @Router /auth/2fa [get]
+func (h *AuthHandler) GetTwoFactorStatus(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+
+	status, err := h.authService.GetTwoFactorStatus(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to get two-factor status"))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse(status, ""))
+}
+
+// SetupTwoFactor godoc
+// @Summary Start two-factor enrollment
+// @Description Generate an authenticator secret and its otpauth:// URI for a QR code. Confirm with /auth/2fa/enable.
+// @Tags Auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.TwoFactorSetupDTO
+// @Failure 409 {object} dto.Response
+// @Router /auth/2fa/setup [post]
+func (h *AuthHandler) SetupTwoFactor(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+
+	setup, err := h.authService.SetupTwoFactor(userID)
+	if err != nil {
+		twoFactorError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse(setup, "Scan the code with your authenticator app"))
+}
+
+// EnableTwoFactor godoc
+// @Summary Enable two-factor authentication
+// @Description Confirm enrollment with an authenticator code. Returns recovery codes once and signs out other devices.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.TwoFactorCodeRequest true "Authenticator code"
+// @Success 200 {object} dto.RecoveryCodesDTO
+// @Failure 400 {object} dto.Response
+// @Router /auth/2fa/enable [post]
+func (h *AuthHandler) EnableTwoFactor(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+	sessionID, _ := middleware.GetSessionID(c)
+
+	var req dto.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse(err.Error()))
+		return
+	}
+
+	codes, err := h.authService.EnableTwoFactor(userID, sessionID, req.Code)
+	if err != nil {
+		twoFactorError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse(dto.RecoveryCodesDTO{RecoveryCodes: codes}, "Two-factor authentication enabled"))
+}
+
+// DisableTwoFactor godoc
+// @Summary Disable two-factor authentication
+// @Description Turn off two-factor authentication with an authenticator or recovery code
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.TwoFactorCodeRequest true "Authenticator or recovery code"
+// @Success 200 {object} dto.Response
+// @Failure 400 {object} dto.Response
+// @Failure 403 {object} dto.Response "Required for the account"
+// @Router /auth/2fa/disable [post]
+func (h *AuthHandler) DisableTwoFactor(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+
+	var req dto.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse(err.Error()))
+		return
+	}
+
+	if err := h.authService.DisableTwoFactor(userID, req.Code); err != nil {
+		twoFactorError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse(nil, "Two-factor authentication disabled"))
+}
+
+// RegenerateRecoveryCodes godoc
+// @Summary Regenerate recovery codes
+// @Description Replace all recovery codes; the old ones stop working
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.TwoFactorCodeRequest true "Authenticator or recovery code"
+// @Success 200 {object} dto.RecoveryCodesDTO
+// @Failure 400 {object} dto.Response
+// @Router /auth/2fa/recovery-codes [post]
+func (h *AuthHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+
+	var req dto.TwoFactorCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse(err.Error()))
+		return
+	}
+
+	codes, err := h.authService.RegenerateRecoveryCodes(userID, req.Code)
+	if err != nil {
+		twoFactorError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse(dto.RecoveryCodesDTO{RecoveryCodes: codes}, "Recovery codes regenerated"))
+}
+
+// twoFactorError maps two-factor errors to responses
+func twoFactorError(c *gin.Context, err error) {
+	var limited *services.RateLimitError
+	switch {
+	case errors.As(err, &limited):
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(limited.RetryAfter.Seconds()))))
+		c.JSON(http.StatusTooManyRequests, dto.ErrorResponseWithCode(limited.Err.Code, err.Error()))
+	case err == services.ErrAccountBlocked:
+		c.JSON(http.StatusForbidden, dto.ErrorResponseWithCode(services.ErrAccountBlocked.Code, err.Error()))
+	case err == services.ErrInvalidTwoFactorChallenge:
+		c.JSON(http.StatusUnauthorized, dto.ErrorResponse(err.Error()))
+	case err == services.ErrTwoFactorMandatory:
+		c.JSON(http.StatusForbidden, dto.ErrorResponse(err.Error()))
+	case err == services.ErrTwoFactorEnabled:
+		c.JSON(http.StatusConflict, dto.ErrorResponse(err.Error()))
+	case err == services.ErrInvalidTwoFactorCode, err == services.ErrTwoFactorNotEnabled, err == services.ErrTwoFactorNotSetUp:
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse(err.Error()))
+	default:
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse(err.Error()))
+	}
+}
+
+// clientInfo describes the device making the request
+func clientInfo(c *gin.Context) services.ClientInfo {
+	return services.ClientInfo{
+		UserAgent: c.Request.UserAgent(),
+		IPAddress: c.ClientIP(),
+	}
+}
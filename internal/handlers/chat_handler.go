@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
 
@@ -137,8 +138,11 @@ func (h *ChatHandler) SendMessage(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, dto.ErrorResponse(err.Error()))
 		return
 	}
+	if req.Locale == "" {
+		req.Locale = c.GetHeader("Accept-Language")
+	}
 
-	userMsg, aiMsg, err := h.chatService.SendMessage(uint(sessionID), userID, &req)
+	userMsg, aiMsg, err := h.chatService.SendMessage(c.Request.Context(), uint(sessionID), userID, &req)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, dto.ErrorResponse(err.Error()))
 		return
@@ -150,6 +154,137 @@ func (h *ChatHandler) SendMessage(c *gin.Context) {
 	}, ""))
 }
 
+// StreamMessage godoc
+// @Summary Send message to chat with streamed reply
+// @Description Send a message and receive the AI response as Server-Sent Events.
+// @Description Events: user_message (saved user message), token (reply chunk), ai_message (saved AI message), error.
+// @Tags Chat
+// @Accept json
+// @Produce text/event-stream
+// @Security BearerAuth
+// @Param id path int true "Session ID"
+// @Param request body dto.SendMessageRequest true "Message content"
+// @Success 200 {string} string "event stream"
+// @Failure 400 {object} dto.Response
+// @Router /chat-sessions/{id}/messages/stream [post]
+func (h *ChatHandler) StreamMessage(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+	sessionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid session ID"))
+		return
+	}
+
+	var req dto.SendMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse(err.Error()))
+		return
+	}
+	if req.Locale == "" {
+		req.Locale = c.GetHeader("Accept-Language")
+	}
+
+	ctx := c.Request.Context()
+	onUserMessage := func(msg *dto.ChatMessageDTO) {
+		c.Header("Cache-Control", "no-cache")
+		c.Header("Connection", "keep-alive")
+		c.Header("X-Accel-Buffering", "no")
+		c.SSEvent("user_message", msg)
+		c.Writer.Flush()
+	}
+	onToken := func(token string) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		c.SSEvent("token", gin.H{"content": token})
+		c.Writer.Flush()
+		return nil
+	}
+
+	aiMsg, err := h.chatService.SendMessageStream(ctx, uint(sessionID), userID, &req, onUserMessage, onToken)
+	if err != nil {
+		if !c.Writer.Written() {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse(err.Error()))
+			return
+		}
+		if ctx.Err() == nil {
+			c.SSEvent("error", gin.H{"error": err.Error()})
+			c.Writer.Flush()
+		}
+		return
+	}
+
+	c.SSEvent("ai_message", aiMsg)
+	c.Writer.Flush()
+}
+
+// RegenerateTitle godoc
+// @Summary Regenerate chat session title
+// @Description Generate a new title from the first exchange of the session
+// @Tags Chat
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Session ID"
+// @Success 200 {object} dto.Response
+// @Failure 400 {object} dto.Response
+// @Router /chat-sessions/{id}/title [post]
+func (h *ChatHandler) RegenerateTitle(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+	sessionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid session ID"))
+		return
+	}
+
+	title, err := h.chatService.RegenerateTitle(c.Request.Context(), uint(sessionID), userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse(gin.H{
+		"id":    sessionID,
+		"title": title,
+	}, "Title regenerated"))
+}
+
+// ExportSession godoc
+// @Summary Export chat session
+// @Description Download a chat session with all messages as PDF, Markdown or JSON, e.g. to share with a therapist
+// @Tags Chat
+// @Produce application/pdf
+// @Produce text/markdown
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Session ID"
+// @Param format query string false "Export format: pdf, md or json" default(pdf)
+// @Success 200 {file} file
+// @Failure 400 {object} dto.Response
+// @Router /chat-sessions/{id}/export [get]
+func (h *ChatHandler) ExportSession(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+	sessionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid session ID"))
+		return
+	}
+
+	var params dto.ChatExportQueryParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse(err.Error()))
+		return
+	}
+
+	export, err := h.chatService.ExportSession(uint(sessionID), userID, params.Format)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse(err.Error()))
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, export.Filename))
+	c.Data(http.StatusOK, export.ContentType, export.Data)
+}
+
 // ToggleTrash godoc
 // @Summary Toggle session trash status
 // @Description Move session to/from trash
@@ -175,6 +310,54 @@ func (h *ChatHandler) ToggleTrash(c *gin.Context) {
 	c.JSON(http.StatusOK, dto.SuccessResponse(nil, "Trash status toggled"))
 }
 
+// RestoreSession godoc
+// @Summary Restore session from trash
+// @Description Move a trashed session back to the session list
+// @Tags Chat
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Session ID"
+// @Success 200 {object} dto.Response
+// @Failure 400 {object} dto.Response
+// @Router /chat-sessions/{id}/restore [put]
+func (h *ChatHandler) RestoreSession(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+	sessionID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid session ID"))
+		return
+	}
+
+	if err := h.chatService.RestoreSession(uint(sessionID), userID); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse(nil, "Session restored"))
+}
+
+// EmptyTrash godoc
+// @Summary Empty trash
+// @Description Permanently delete every chat session in the trash, including all messages
+// @Tags Chat
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.Response
+// @Router /chat-sessions/trash [delete]
+func (h *ChatHandler) EmptyTrash(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+
+	deleted, err := h.chatService.EmptyTrash(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to empty trash"))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse(gin.H{
+		"deleted": deleted,
+	}, "Trash emptied"))
+}
+
 // ToggleFavorite godoc
 // @Summary Toggle session favorite
 // @Description Toggle favorite status for a session
@@ -227,11 +410,13 @@ func (h *ChatHandler) DeleteSession(c *gin.Context) {
 
 // ToggleMessageLike godoc
 // @Summary Toggle message like
-// @Description Toggle like status for a message
+// @Description Toggle like status for an AI message, with an optional reason
 // @Tags Chat
+// @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param id path int true "Message ID"
+// @Param request body dto.MessageFeedbackRequest false "Feedback reason"
 // @Success 200 {object} dto.Response
 // @Router /chat-messages/{id}/like [put]
 func (h *ChatHandler) ToggleMessageLike(c *gin.Context) {
@@ -241,9 +426,18 @@ func (h *ChatHandler) ToggleMessageLike(c *gin.Context) {
 		return
 	}
 
-	userID := c.MustGet("userID").(uint)
+	userID, _ := middleware.GetUserID(c)
 
-	if err := h.chatService.ToggleMessageLike(uint(messageID), userID); err != nil {
+	// The reason is optional, so an empty body is allowed
+	var req dto.MessageFeedbackRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse(err.Error()))
+			return
+		}
+	}
+
+	if err := h.chatService.ToggleMessageLike(uint(messageID), userID, req.Reason); err != nil {
 		c.JSON(http.StatusBadRequest, dto.ErrorResponse(err.Error()))
 		return
 	}
@@ -253,11 +447,13 @@ func (h *ChatHandler) ToggleMessageLike(c *gin.Context) {
 
 // ToggleMessageDislike godoc
 // @Summary Toggle message dislike
-// @Description Toggle dislike status for a message
+// @Description Toggle dislike status for an AI message, with an optional reason
 // @Tags Chat
+// @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param id path int true "Message ID"
+// @Param request body dto.MessageFeedbackRequest false "Feedback reason"
 // @Success 200 {object} dto.Response
 // @Router /chat-messages/{id}/dislike [put]
 func (h *ChatHandler) ToggleMessageDislike(c *gin.Context) {
@@ -267,9 +463,18 @@ func (h *ChatHandler) ToggleMessageDislike(c *gin.Context) {
 		return
 	}
 
-	userID := c.MustGet("userID").(uint)
+	userID, _ := middleware.GetUserID(c)
+
+	// The reason is optional, so an empty body is allowed
+	var req dto.MessageFeedbackRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse(err.Error()))
+			return
+		}
+	}
 
-	if err := h.chatService.ToggleMessageDislike(uint(messageID), userID); err != nil {
+	if err := h.chatService.ToggleMessageDislike(uint(messageID), userID, req.Reason); err != nil {
 		c.JSON(http.StatusBadRequest, dto.ErrorResponse(err.Error()))
 		return
 	}
@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Alfian57/ruang-tenang-api/internal/config"
+	"github.com/Alfian57/ruang-tenang-api/internal/middleware"
+	"github.com/Alfian57/ruang-tenang-api/internal/models"
+	"github.com/Alfian57/ruang-tenang-api/internal/repositories"
+	"github.com/Alfian57/ruang-tenang-api/internal/services"
+	"github.com/Alfian57/ruang-tenang-api/pkg/llm"
+	"github.com/Alfian57/ruang-tenang-api/pkg/logger"
+	"github.com/Alfian57/ruang-tenang-api/pkg/safety"
+	"github.com/Alfian57/ruang-tenang-api/pkg/stt"
+	"github.com/Alfian57/ruang-tenang-api/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	logger.Log = zap.NewNop()
+	logger.Sugar = logger.Log.Sugar()
+	config.AppConfig = &config.Config{JWTSecret: "test-secret"}
+	os.Exit(m.Run())
+}
+
+// testDB opens the Postgres database in TEST_DATABASE_URL and returns a
+// transaction that is rolled back after the test. Tests are skipped when no
+// database is configured.
+func testDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: gormlogger.Default.LogMode(gormlogger.Silent)})
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	err = db.AutoMigrate(
+		&models.User{},
+		&models.ChatSession{},
+		&models.ChatMessage{},
+		&models.SystemPrompt{},
+		&models.ChatFeedback{},
+	)
+	if err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	tx := db.Begin()
+	t.Cleanup(func() { tx.Rollback() })
+	return tx
+}
+
+func TestMessageFeedbackThroughAuth(t *testing.T) {
+	tests := []struct {
+		name       string
+		path       string
+		body       string
+		wantRating models.FeedbackRating
+		wantReason string
+	}{
+		{name: "like with a reason", path: "like", body: `{"reason":" Sangat membantu "}`, wantRating: models.FeedbackLike, wantReason: "Sangat membantu"},
+		{name: "like without a body", path: "like", wantRating: models.FeedbackLike},
+		{name: "dislike with a reason", path: "dislike", body: `{"reason":"Kurang relevan"}`, wantRating: models.FeedbackDislike, wantReason: "Kurang relevan"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := testDB(t)
+			promptRepo := repositories.NewSystemPromptRepository(db)
+			chatService := services.NewChatService(
+				repositories.NewChatSessionRepository(db),
+				repositories.NewChatMessageRepository(db),
+				repositories.NewUserRepository(db),
+				&config.Config{},
+				llm.NewStubProvider(),
+				stt.NewStubTranscriber(),
+				services.NewPromptService(promptRepo),
+				services.NewCrisisService(repositories.NewCrisisFlagRepository(db), safety.NewLexiconClassifier()),
+				services.NewFeedbackService(repositories.NewChatFeedbackRepository(db), promptRepo),
+				services.NewGamificationService(db),
+			)
+			handler := NewChatHandler(chatService)
+
+			user := &models.User{
+				Name:     "Feedback Test",
+				Email:    fmt.Sprintf("feedback-test-%d@example.com", time.Now().UnixNano()),
+				Password: "x",
+				Role:     models.RoleMember,
+			}
+			if err := db.Create(user).Error; err != nil {
+				t.Fatalf("create user: %v", err)
+			}
+			session := &models.ChatSession{UserID: user.ID, Title: "Test"}
+			if err := db.Create(session).Error; err != nil {
+				t.Fatalf("create session: %v", err)
+			}
+			msg := &models.ChatMessage{ChatSessionID: session.ID, Role: models.ChatRoleAI, Content: "Halo"}
+			if err := db.Create(msg).Error; err != nil {
+				t.Fatalf("create message: %v", err)
+			}
+
+			token, err := utils.GenerateToken(user.ID, user.Email, string(user.Role), 0, 0, time.Hour)
+			if err != nil {
+				t.Fatalf("token: %v", err)
+			}
+
+			router := gin.New()
+			messages := router.Group("/chat-messages")
+			messages.Use(middleware.AuthMiddleware())
+			messages.PUT("/:id/like", handler.ToggleMessageLike)
+			messages.PUT("/:id/dislike", handler.ToggleMessageDislike)
+
+			req := httptest.NewRequest(http.MethodPut, fmt.Sprintf("/chat-messages/%d/%s", msg.ID, tt.path), strings.NewReader(tt.body))
+			req.Header.Set("Authorization", "Bearer "+token)
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, body %s", w.Code, w.Body.String())
+			}
+
+			var events []models.ChatFeedback
+			if err := db.Where("chat_message_id = ?", msg.ID).Find(&events).Error; err != nil {
+				t.Fatalf("load feedback: %v", err)
+			}
+			if len(events) != 1 {
+				t.Fatalf("recorded %d feedback events, want 1", len(events))
+			}
+			if events[0].UserID != user.ID || events[0].Rating != tt.wantRating || events[0].Reason != tt.wantReason {
+				t.Errorf("feedback = %+v, want %s by user %d with reason %q", events[0], tt.wantRating, user.ID, tt.wantReason)
+			}
+		})
+	}
+}
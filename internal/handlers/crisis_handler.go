@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Alfian57/ruang-tenang-api/internal/dto"
+	"github.com/Alfian57/ruang-tenang-api/internal/middleware"
+	"github.com/Alfian57/ruang-tenang-api/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type CrisisHandler struct {
+	crisisService *services.CrisisService
+}
+
+func NewCrisisHandler(crisisService *services.CrisisService) *CrisisHandler {
+	return &CrisisHandler{crisisService: crisisService}
+}
+
+// GetFlags godoc
+// @Summary Get crisis flags
+// @Description Get chat messages flagged by crisis screening (admin only)
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param status query string false "Filter: open, reviewed, escalated, dismissed"
+// @Param risk_level query string false "Filter: low, medium, high"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} dto.PaginatedResponse
+// @Router /admin/crisis-flags [get]
+func (h *CrisisHandler) GetFlags(c *gin.Context) {
+	var params dto.CrisisFlagQueryParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse(err.Error()))
+		return
+	}
+
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	if params.Limit < 1 || params.Limit > 50 {
+		params.Limit = 20
+	}
+
+	flags, total, err := h.crisisService.GetFlags(params)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to get crisis flags"))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewPaginatedResponse(flags, params.Page, params.Limit, total))
+}
+
+// GetFlag godoc
+// @Summary Get crisis flag by ID
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Flag ID"
+// @Success 200 {object} dto.CrisisFlagDTO
+// @Failure 404 {object} dto.Response
+// @Router /admin/crisis-flags/{id} [get]
+func (h *CrisisHandler) GetFlag(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid flag ID"))
+		return
+	}
+
+	flag, err := h.crisisService.GetFlag(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse(flag, ""))
+}
+
+// ReviewFlag godoc
+// @Summary Review crisis flag
+// @Description Mark a crisis flag as reviewed, escalated or dismissed (admin only)
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Flag ID"
+// @Param request body dto.ReviewCrisisFlagRequest true "Review data"
+// @Success 200 {object} dto.Response
+// @Failure 404 {object} dto.Response
+// @Router /admin/crisis-flags/{id}/review [put]
+func (h *CrisisHandler) ReviewFlag(c *gin.Context) {
+	reviewerID, _ := middleware.GetUserID(c)
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid flag ID"))
+		return
+	}
+
+	var req dto.ReviewCrisisFlagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse(err.Error()))
+		return
+	}
+
+	flag, err := h.crisisService.ReviewFlag(uint(id), reviewerID, &req)
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse(flag, "Crisis flag reviewed"))
+}
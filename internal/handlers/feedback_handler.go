@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Alfian57/ruang-tenang-api/internal/dto"
+	"github.com/Alfian57/ruang-tenang-api/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type FeedbackHandler struct {
+	feedbackService *services.FeedbackService
+}
+
+func NewFeedbackHandler(feedbackService *services.FeedbackService) *FeedbackHandler {
+	return &FeedbackHandler{feedbackService: feedbackService}
+}
+
+// GetFeedback godoc
+// @Summary Get chat feedback events
+// @Description Get like/dislike events on AI messages with their reasons (admin only)
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param rating query string false "Filter: like, dislike, none"
+// @Param days query int false "Look-back window in days" default(30)
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} dto.PaginatedResponse
+// @Router /admin/chat-feedback [get]
+func (h *FeedbackHandler) GetFeedback(c *gin.Context) {
+	var params dto.FeedbackQueryParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse(err.Error()))
+		return
+	}
+
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	if params.Limit < 1 || params.Limit > 50 {
+		params.Limit = 20
+	}
+
+	feedback, total, err := h.feedbackService.GetFeedback(params)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to get feedback"))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewPaginatedResponse(feedback, params.Page, params.Limit, total))
+}
+
+// GetAnalytics godoc
+// @Summary Get chat feedback analytics
+// @Description Aggregate AI reply satisfaction by day, prompt version and model (admin only)
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param days query int false "Look-back window in days" default(30)
+// @Success 200 {object} dto.FeedbackAnalyticsDTO
+// @Router /admin/chat-feedback/analytics [get]
+func (h *FeedbackHandler) GetAnalytics(c *gin.Context) {
+	var params dto.FeedbackAnalyticsQueryParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse(err.Error()))
+		return
+	}
+
+	analytics, err := h.feedbackService.GetAnalytics(params.Days)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to get feedback analytics"))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse(analytics, ""))
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"net/http"
+	"strconv"
 
 	"github.com/Alfian57/ruang-tenang-api/internal/dto"
 	"github.com/Alfian57/ruang-tenang-api/internal/middleware"
@@ -19,13 +20,14 @@ func NewMoodHandler(moodService *services.MoodService) *MoodHandler {
 
 // RecordMood godoc
 // @Summary Record user mood
-// @Description Record a new mood entry for the user
+// @Description Log a mood check-in with an optional intensity (1-10), journal note, social context and activity/trigger tags. Users can check in several times a day.
 // @Tags Mood
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param request body dto.CreateMoodRequest true "Mood data"
 // @Success 201 {object} dto.UserMoodDTO
+// @Failure 400 {object} dto.Response
 // @Router /user-moods [post]
 func (h *MoodHandler) RecordMood(c *gin.Context) {
 	userID, _ := middleware.GetUserID(c)
@@ -38,6 +40,10 @@ func (h *MoodHandler) RecordMood(c *gin.Context) {
 
 	mood, err := h.moodService.RecordMood(userID, &req)
 	if err != nil {
+		if err == services.ErrInvalidMoodTag {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse(err.Error()))
+			return
+		}
 		c.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to record mood"))
 		return
 	}
@@ -53,6 +59,8 @@ func (h *MoodHandler) RecordMood(c *gin.Context) {
 // @Security BearerAuth
 // @Param start_date query string false "Start date (YYYY-MM-DD)"
 // @Param end_date query string false "End date (YYYY-MM-DD)"
+// @Param mood query string false "Only check-ins with this mood"
+// @Param tag query string false "Only check-ins with this activity or trigger tag"
 // @Param page query int false "Page number" default(1)
 // @Param limit query int false "Items per page" default(30)
 // @Success 200 {object} dto.MoodHistoryDTO
@@ -103,6 +111,173 @@ func (h *MoodHandler) GetLatestMood(c *gin.Context) {
 	c.JSON(http.StatusOK, dto.SuccessResponse(mood, ""))
 }
 
+// GetMood godoc
+// @Summary Get mood entry
+// @Description Get one of the user's mood check-ins
+// @Tags Mood
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Mood ID"
+// @Success 200 {object} dto.UserMoodDTO
+// @Failure 404 {object} dto.Response
+// @Router /user-moods/{id} [get]
+func (h *MoodHandler) GetMood(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+	moodID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid mood ID"))
+		return
+	}
+
+	mood, err := h.moodService.GetMood(userID, uint(moodID))
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse(mood, ""))
+}
+
+// UpdateMood godoc
+// @Summary Update mood entry
+// @Description Replace the mood, intensity, note, social context and tags of a check-in
+// @Tags Mood
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Mood ID"
+// @Param request body dto.UpdateMoodRequest true "Mood data"
+// @Success 200 {object} dto.UserMoodDTO
+// @Failure 400 {object} dto.Response
+// @Failure 404 {object} dto.Response
+// @Router /user-moods/{id} [put]
+func (h *MoodHandler) UpdateMood(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+	moodID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid mood ID"))
+		return
+	}
+
+	var req dto.UpdateMoodRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse(err.Error()))
+		return
+	}
+
+	mood, err := h.moodService.UpdateMood(userID, uint(moodID), &req)
+	if err != nil {
+		moodError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse(mood, "Mood updated"))
+}
+
+// DeleteMood godoc
+// @Summary Delete mood entry
+// @Description Permanently delete a check-in with its note and tags
+// @Tags Mood
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Mood ID"
+// @Success 200 {object} dto.Response
+// @Failure 404 {object} dto.Response
+// @Router /user-moods/{id} [delete]
+func (h *MoodHandler) DeleteMood(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+	moodID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid mood ID"))
+		return
+	}
+
+	if err := h.moodService.DeleteMood(userID, uint(moodID)); err != nil {
+		moodError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse(nil, "Mood deleted"))
+}
+
+// GetMoodTags godoc
+// @Summary Get mood tags
+// @Description List the activity and trigger tags the user has used, most used first, for suggestions
+// @Tags Mood
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.MoodTagsDTO
+// @Router /user-moods/tags [get]
+func (h *MoodHandler) GetMoodTags(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+
+	tags, err := h.moodService.GetMoodTags(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to get mood tags"))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse(tags, ""))
+}
+
+// GetMoodAnalytics godoc
+// @Summary Get mood analytics
+// @Description Mood dashboard data for the last N days: a calendar heatmap with 7-day rolling averages, weekly and monthly aggregates, logging streaks, week-over-week change and day-of-week and time-of-day patterns. Averages use a valence scale from -2 to 2 mapped from each mood.
+// @Tags Mood
+// @Produce json
+// @Security BearerAuth
+// @Param days query int false "Number of days (7-365)" default(30)
+// @Success 200 {object} dto.MoodAnalyticsDTO
+// @Router /user-moods/analytics [get]
+func (h *MoodHandler) GetMoodAnalytics(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+
+	var params dto.MoodAnalyticsQueryParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse(err.Error()))
+		return
+	}
+
+	analytics, err := h.moodService.GetMoodAnalytics(userID, params.Days)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to get mood analytics"))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse(analytics, ""))
+}
+
+// GetMoodInsights godoc
+// @Summary Get mood insights
+// @Description Relate daily mood to app activity (chatting with the AI, forum participation, writing and reading articles, listening to each song category) on the same day and the day after. Returns every comparison and up to 5 notable ones phrased as statements. These are correlations, not causes.
+// @Tags Mood
+// @Produce json
+// @Security BearerAuth
+// @Param days query int false "Number of days (14-365)" default(90)
+// @Param locale query string false "Statement language (id or en), defaults to Accept-Language"
+// @Success 200 {object} dto.MoodInsightsDTO
+// @Router /user-moods/insights [get]
+func (h *MoodHandler) GetMoodInsights(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+
+	var params dto.MoodInsightsQueryParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse(err.Error()))
+		return
+	}
+	if params.Locale == "" {
+		params.Locale = c.GetHeader("Accept-Language")
+	}
+
+	insights, err := h.moodService.GetMoodInsights(userID, params.Days, params.Locale)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to get mood insights"))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse(insights, ""))
+}
+
 // GetMoodStats godoc
 // @Summary Get mood statistics
 // @Description Get mood statistics for the last N days
@@ -130,3 +305,15 @@ func (h *MoodHandler) GetMoodStats(c *gin.Context) {
 
 	c.JSON(http.StatusOK, dto.SuccessResponse(stats, ""))
 }
+
+// moodError maps mood journal errors to responses
+func moodError(c *gin.Context, err error) {
+	switch err {
+	case services.ErrMoodNotFound:
+		c.JSON(http.StatusNotFound, dto.ErrorResponse(err.Error()))
+	case services.ErrInvalidMoodTag:
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse(err.Error()))
+	default:
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse(err.Error()))
+	}
+}
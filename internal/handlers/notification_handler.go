@@ -0,0 +1,259 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Alfian57/ruang-tenang-api/internal/dto"
+	"github.com/Alfian57/ruang-tenang-api/internal/middleware"
+	"github.com/Alfian57/ruang-tenang-api/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type NotificationHandler struct {
+	notificationService *services.NotificationService
+	reminderService     *services.ReminderService
+}
+
+func NewNotificationHandler(notificationService *services.NotificationService, reminderService *services.ReminderService) *NotificationHandler {
+	return &NotificationHandler{
+		notificationService: notificationService,
+		reminderService:     reminderService,
+	}
+}
+
+// GetReminderPreference godoc
+// @Summary Get mood reminder settings
+// @Description Get when and how the user is reminded to log their mood, with the channels this server can deliver on
+// @Tags Notifications
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.ReminderPreferenceDTO
+// @Router /user-moods/reminders [get]
+func (h *NotificationHandler) GetReminderPreference(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+
+	pref, err := h.reminderService.GetPreference(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to get reminder settings"))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse(pref, ""))
+}
+
+// UpdateReminderPreference godoc
+// @Summary Update mood reminder settings
+// @Description Set up to three daily reminder times, the weekdays, quiet hours and channels (in_app, email, web_push), in the user's timezone. Reminders are skipped on days the user already logged a mood.
+// @Tags Notifications
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.UpdateReminderPreferenceRequest true "Reminder settings"
+// @Success 200 {object} dto.ReminderPreferenceDTO
+// @Failure 400 {object} dto.Response
+// @Router /user-moods/reminders [put]
+func (h *NotificationHandler) UpdateReminderPreference(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+
+	var req dto.UpdateReminderPreferenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse(err.Error()))
+		return
+	}
+	if req.Locale == "" {
+		req.Locale = c.GetHeader("Accept-Language")
+	}
+
+	pref, err := h.reminderService.UpdatePreference(userID, &req)
+	if err != nil {
+		notificationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse(pref, "Reminder settings updated"))
+}
+
+// GetNotifications godoc
+// @Summary Get notifications
+// @Description Get the user's in-app notifications, newest first
+// @Tags Notifications
+// @Produce json
+// @Security BearerAuth
+// @Param unread query bool false "Only unread notifications"
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(20)
+// @Success 200 {object} dto.PaginatedResponse
+// @Router /notifications [get]
+func (h *NotificationHandler) GetNotifications(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+
+	var params dto.NotificationQueryParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse(err.Error()))
+		return
+	}
+
+	if params.Page < 1 {
+		params.Page = 1
+	}
+	if params.Limit < 1 || params.Limit > 50 {
+		params.Limit = 20
+	}
+
+	notifications, total, err := h.notificationService.GetNotifications(userID, &params)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to get notifications"))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewPaginatedResponse(notifications, params.Page, params.Limit, total))
+}
+
+// GetUnreadCount godoc
+// @Summary Count unread notifications
+// @Description Get the number of unread in-app notifications, for a badge
+// @Tags Notifications
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.UnreadNotificationsDTO
+// @Router /notifications/unread-count [get]
+func (h *NotificationHandler) GetUnreadCount(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+
+	count, err := h.notificationService.GetUnreadCount(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to count notifications"))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse(count, ""))
+}
+
+// MarkRead godoc
+// @Summary Mark notification read
+// @Tags Notifications
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Notification ID"
+// @Success 200 {object} dto.Response
+// @Failure 404 {object} dto.Response
+// @Router /notifications/{id}/read [put]
+func (h *NotificationHandler) MarkRead(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+	notificationID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid notification ID"))
+		return
+	}
+
+	if err := h.notificationService.MarkRead(userID, uint(notificationID)); err != nil {
+		notificationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse(nil, "Notification marked as read"))
+}
+
+// MarkAllRead godoc
+// @Summary Mark all notifications read
+// @Tags Notifications
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.MarkAllNotificationsReadDTO
+// @Router /notifications/read-all [put]
+func (h *NotificationHandler) MarkAllRead(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+
+	result, err := h.notificationService.MarkAllRead(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to mark notifications as read"))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse(result, "Notifications marked as read"))
+}
+
+// GetPushConfig godoc
+// @Summary Get Web Push settings
+// @Description Whether push notifications are available, and the VAPID public key to subscribe with
+// @Tags Notifications
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.PushConfigDTO
+// @Router /notifications/push [get]
+func (h *NotificationHandler) GetPushConfig(c *gin.Context) {
+	c.JSON(http.StatusOK, dto.SuccessResponse(h.notificationService.GetPushConfig(), ""))
+}
+
+// SubscribePush godoc
+// @Summary Subscribe to push notifications
+// @Description Save this browser's push subscription (PushSubscription.toJSON())
+// @Tags Notifications
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.PushSubscriptionRequest true "Push subscription"
+// @Success 201 {object} dto.Response
+// @Failure 400 {object} dto.Response
+// @Failure 503 {object} dto.Response
+// @Router /notifications/push/subscriptions [post]
+func (h *NotificationHandler) SubscribePush(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+
+	var req dto.PushSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse(err.Error()))
+		return
+	}
+
+	if err := h.notificationService.SubscribePush(userID, &req, c.Request.UserAgent()); err != nil {
+		notificationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.SuccessResponse(nil, "Push notifications enabled"))
+}
+
+// UnsubscribePush godoc
+// @Summary Unsubscribe from push notifications
+// @Description Remove this browser's push subscription
+// @Tags Notifications
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.PushUnsubscribeRequest true "Subscription endpoint"
+// @Success 200 {object} dto.Response
+// @Failure 404 {object} dto.Response
+// @Router /notifications/push/subscriptions [delete]
+func (h *NotificationHandler) UnsubscribePush(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+
+	var req dto.PushUnsubscribeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse(err.Error()))
+		return
+	}
+
+	if err := h.notificationService.UnsubscribePush(userID, req.Endpoint); err != nil {
+		notificationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse(nil, "Push notifications disabled"))
+}
+
+// notificationError maps reminder and notification errors to responses
+func notificationError(c *gin.Context, err error) {
+	switch err {
+	case services.ErrNotificationNotFound, services.ErrPushSubscriptionNotFound:
+		c.JSON(http.StatusNotFound, dto.ErrorResponse(err.Error()))
+	case services.ErrInvalidReminderTime, services.ErrReminderIncomplete,
+		services.ErrChannelUnavailable, services.ErrInvalidPushSubscription:
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse(err.Error()))
+	case services.ErrPushUnavailable:
+		c.JSON(http.StatusServiceUnavailable, dto.ErrorResponse(err.Error()))
+	default:
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse(err.Error()))
+	}
+}
@@ -0,0 +1,228 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/Alfian57/ruang-tenang-api/internal/dto"
+	"github.com/Alfian57/ruang-tenang-api/internal/middleware"
+	"github.com/Alfian57/ruang-tenang-api/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type PromptHandler struct {
+	promptService *services.PromptService
+}
+
+func NewPromptHandler(promptService *services.PromptService) *PromptHandler {
+	return &PromptHandler{promptService: promptService}
+}
+
+// GetPrompts godoc
+// @Summary Get system prompts
+// @Description Get all system prompt versions (admin only)
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param name query string false "Prompt name, e.g. chat"
+// @Param locale query string false "Locale, e.g. id or en"
+// @Success 200 {object} dto.Response
+// @Router /admin/system-prompts [get]
+func (h *PromptHandler) GetPrompts(c *gin.Context) {
+	var params dto.SystemPromptQueryParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse(err.Error()))
+		return
+	}
+
+	prompts, err := h.promptService.GetAll(params)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to get prompts"))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse(prompts, ""))
+}
+
+// GetPrompt godoc
+// @Summary Get system prompt by ID
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Prompt ID"
+// @Success 200 {object} dto.SystemPromptDTO
+// @Failure 404 {object} dto.Response
+// @Router /admin/system-prompts/{id} [get]
+func (h *PromptHandler) GetPrompt(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid prompt ID"))
+		return
+	}
+
+	prompt, err := h.promptService.GetByID(uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse(prompt, ""))
+}
+
+// CreatePrompt godoc
+// @Summary Create system prompt version
+// @Description Create a new, inactive version of a system prompt (admin only)
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.CreateSystemPromptRequest true "Prompt data"
+// @Success 201 {object} dto.Response
+// @Failure 400 {object} dto.Response
+// @Router /admin/system-prompts [post]
+func (h *PromptHandler) CreatePrompt(c *gin.Context) {
+	adminID, _ := middleware.GetUserID(c)
+
+	var req dto.CreateSystemPromptRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse(err.Error()))
+		return
+	}
+
+	prompt, err := h.promptService.Create(adminID, &req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to create prompt"))
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.SuccessResponse(prompt, "Prompt version created"))
+}
+
+// UpdatePrompt godoc
+// @Summary Update system prompt version
+// @Description Update a prompt version that has not been used yet (admin only)
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Prompt ID"
+// @Param request body dto.UpdateSystemPromptRequest true "Prompt data"
+// @Success 200 {object} dto.Response
+// @Failure 409 {object} dto.Response
+// @Router /admin/system-prompts/{id} [put]
+func (h *PromptHandler) UpdatePrompt(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid prompt ID"))
+		return
+	}
+
+	var req dto.UpdateSystemPromptRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse(err.Error()))
+		return
+	}
+
+	prompt, err := h.promptService.Update(uint(id), &req)
+	if err != nil {
+		h.handleError(c, err, "Failed to update prompt")
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse(prompt, "Prompt version updated"))
+}
+
+// ActivatePrompt godoc
+// @Summary Activate system prompt version
+// @Description Activate a prompt version. With exclusive=true all other versions of the same locale are deactivated (rollback).
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Prompt ID"
+// @Param request body dto.ActivateSystemPromptRequest false "Activation options"
+// @Success 200 {object} dto.Response
+// @Router /admin/system-prompts/{id}/activate [put]
+func (h *PromptHandler) ActivatePrompt(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid prompt ID"))
+		return
+	}
+
+	var req dto.ActivateSystemPromptRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, dto.ErrorResponse(err.Error()))
+			return
+		}
+	}
+
+	prompt, err := h.promptService.Activate(uint(id), req.Exclusive)
+	if err != nil {
+		h.handleError(c, err, "Failed to activate prompt")
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse(prompt, "Prompt version activated"))
+}
+
+// DeactivatePrompt godoc
+// @Summary Deactivate system prompt version
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Prompt ID"
+// @Success 200 {object} dto.Response
+// @Router /admin/system-prompts/{id}/deactivate [put]
+func (h *PromptHandler) DeactivatePrompt(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid prompt ID"))
+		return
+	}
+
+	prompt, err := h.promptService.Deactivate(uint(id))
+	if err != nil {
+		h.handleError(c, err, "Failed to deactivate prompt")
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse(prompt, "Prompt version deactivated"))
+}
+
+// DeletePrompt godoc
+// @Summary Delete system prompt version
+// @Description Delete an inactive prompt version that was never used (admin only)
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Prompt ID"
+// @Success 200 {object} dto.Response
+// @Failure 409 {object} dto.Response
+// @Router /admin/system-prompts/{id} [delete]
+func (h *PromptHandler) DeletePrompt(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid prompt ID"))
+		return
+	}
+
+	if err := h.promptService.Delete(uint(id)); err != nil {
+		h.handleError(c, err, "Failed to delete prompt")
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse(nil, "Prompt version deleted"))
+}
+
+func (h *PromptHandler) handleError(c *gin.Context, err error, fallback string) {
+	switch err {
+	case services.ErrPromptNotFound:
+		c.JSON(http.StatusNotFound, dto.ErrorResponse(err.Error()))
+	case services.ErrPromptInUse, services.ErrPromptActive:
+		c.JSON(http.StatusConflict, dto.ErrorResponse(err.Error()))
+	default:
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse(fallback))
+	}
+}
@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/Alfian57/ruang-tenang-api/internal/dto"
+	"github.com/Alfian57/ruang-tenang-api/internal/middleware"
+	"github.com/Alfian57/ruang-tenang-api/internal/services"
+	"github.com/Alfian57/ruang-tenang-api/pkg/oidc"
+	"github.com/gin-gonic/gin"
+)
+
+type SocialAuthHandler struct {
+	socialAuthService  *services.SocialAuthService
+	levelConfigService *services.LevelConfigService
+}
+
+func NewSocialAuthHandler(socialAuthService *services.SocialAuthService, levelConfigService *services.LevelConfigService) *SocialAuthHandler {
+	return &SocialAuthHandler{
+		socialAuthService:  socialAuthService,
+		levelConfigService: levelConfigService,
+	}
+}
+
+// GetProviders godoc
+// @Summary List social login providers
+// @Description List the configured OpenID Connect providers
+// @Tags Auth
+// @Produce json
+// @Success 200 {object} dto.Response
+// @Router /auth/oidc/providers [get]
+func (h *SocialAuthHandler) GetProviders(c *gin.Context) {
+	c.JSON(http.StatusOK, dto.SuccessResponse(h.socialAuthService.Providers(), ""))
+}
+
+// Authorize godoc
+// @Summary Start social login
+// @Description Get the provider sign-in URL. After signing in, the provider redirects to the web app with code and state, which it posts to the callback endpoint.
+// @Tags Auth
+// @Produce json
+// @Param provider path string true "Provider, e.g. google"
+// @Success 200 {object} dto.OIDCAuthorizeResponse
+// @Failure 404 {object} dto.Response
+// @Failure 502 {object} dto.Response
+// @Router /auth/oidc/{provider}/authorize [get]
+func (h *SocialAuthHandler) Authorize(c *gin.Context) {
+	result, err := h.socialAuthService.Authorize(c.Request.Context(), c.Param("provider"), nil)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse(result, ""))
+}
+
+// Callback godoc
+// @Summary Finish social login
+// @Description Exchange the code from the provider for tokens. Signs in the linked user, links a verified provider email to the matching verified account, or creates a new account.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param provider path string true "Provider, e.g. google"
+// @Param request body dto.OIDCCallbackRequest true "Code and state from the provider redirect"
+// @Success 200 {object} dto.LoginResponse
+// @Failure 400 {object} dto.Response
+// @Failure 401 {object} dto.Response
+// @Failure 403 {object} dto.Response "Account blocked (code account_blocked) or provider email not verified (code email_not_verified)"
+// @Failure 409 {object} dto.Response "Unverified account with this email exists (code account_exists)"
+// @Router /auth/oidc/{provider}/callback [post]
+func (h *SocialAuthHandler) Callback(c *gin.Context) {
+	var req dto.OIDCCallbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse(err.Error()))
+		return
+	}
+
+	response, err := h.socialAuthService.Login(c.Request.Context(), c.Param("provider"), &req, clientInfo(c))
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	respondLogin(c, h.levelConfigService, response)
+}
+
+// GetSignInMethods godoc
+// @Summary List sign-in methods
+// @Description List linked providers and whether the account has a password
+// @Tags Auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.SignInMethodsDTO
+// @Router /auth/identities [get]
+func (h *SocialAuthHandler) GetSignInMethods(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+
+	result, err := h.socialAuthService.GetSignInMethods(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse("Failed to get sign-in methods"))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse(result, ""))
+}
+
+// AuthorizeLink godoc
+// @Summary Start linking a provider
+// @Description Get the provider sign-in URL for linking it to the current account. Finish with POST /auth/identities/{provider}.
+// @Tags Auth
+// @Produce json
+// @Security BearerAuth
+// @Param provider path string true "Provider, e.g. google"
+// @Success 200 {object} dto.OIDCAuthorizeResponse
+// @Failure 404 {object} dto.Response
+// @Router /auth/identities/{provider}/authorize [get]
+func (h *SocialAuthHandler) AuthorizeLink(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+
+	result, err := h.socialAuthService.Authorize(c.Request.Context(), c.Param("provider"), &userID)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse(result, ""))
+}
+
+// LinkIdentity godoc
+// @Summary Link a provider
+// @Description Finish linking a provider to the current account with the code and state from the provider redirect
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param provider path string true "Provider, e.g. google"
+// @Param request body dto.OIDCCallbackRequest true "Code and state from the provider redirect"
+// @Success 200 {object} dto.UserIdentityDTO
+// @Failure 400 {object} dto.Response
+// @Failure 409 {object} dto.Response
+// @Router /auth/identities/{provider} [post]
+func (h *SocialAuthHandler) LinkIdentity(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+
+	var req dto.OIDCCallbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse(err.Error()))
+		return
+	}
+
+	identity, err := h.socialAuthService.Link(c.Request.Context(), userID, c.Param("provider"), &req)
+	if err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse(identity, "Provider linked"))
+}
+
+// UnlinkIdentity godoc
+// @Summary Unlink a provider
+// @Description Unlink a provider from the current account. The last sign-in method can't be removed.
+// @Tags Auth
+// @Produce json
+// @Security BearerAuth
+// @Param provider path string true "Provider, e.g. google"
+// @Success 200 {object} dto.Response
+// @Failure 404 {object} dto.Response
+// @Failure 409 {object} dto.Response
+// @Router /auth/identities/{provider} [delete]
+func (h *SocialAuthHandler) UnlinkIdentity(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+
+	if err := h.socialAuthService.Unlink(userID, c.Param("provider")); err != nil {
+		h.handleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse(nil, "Provider unlinked"))
+}
+
+func (h *SocialAuthHandler) handleError(c *gin.Context, err error) {
+	var coded *services.CodedError
+	switch {
+	case errors.Is(err, oidc.ErrUnknownProvider):
+		c.JSON(http.StatusNotFound, dto.ErrorResponse("Unknown sign-in provider"))
+	case err == services.ErrIdentityNotFound:
+		c.JSON(http.StatusNotFound, dto.ErrorResponse(err.Error()))
+	case err == services.ErrInvalidOAuthState:
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse(err.Error()))
+	case err == services.ErrSocialLoginFailed:
+		c.JSON(http.StatusUnauthorized, dto.ErrorResponse(err.Error()))
+	case err == services.ErrProviderUnavailable:
+		c.JSON(http.StatusBadGateway, dto.ErrorResponse(err.Error()))
+	case err == services.ErrSocialAccountExists:
+		c.JSON(http.StatusConflict, dto.ErrorResponseWithCode(services.ErrSocialAccountExists.Code, err.Error()))
+	case err == services.ErrIdentityLinked, err == services.ErrProviderAlreadyLinked, err == services.ErrLastSignInMethod:
+		c.JSON(http.StatusConflict, dto.ErrorResponse(err.Error()))
+	case errors.As(err, &coded):
+		c.JSON(http.StatusForbidden, dto.ErrorResponseWithCode(coded.Code, err.Error()))
+	default:
+		c.JSON(http.StatusInternalServerError, dto.ErrorResponse(err.Error()))
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"strconv"
 
 	"github.com/Alfian57/ruang-tenang-api/internal/dto"
+	"github.com/Alfian57/ruang-tenang-api/internal/middleware"
 	"github.com/Alfian57/ruang-tenang-api/internal/services"
 	"github.com/gin-gonic/gin"
 )
@@ -82,3 +83,29 @@ func (h *SongHandler) GetSong(c *gin.Context) {
 
 	c.JSON(http.StatusOK, dto.SuccessResponse(song, ""))
 }
+
+// RecordPlay godoc
+// @Summary Record song play
+// @Description Note that the user listened to a song, so mood insights can relate moods to the music. Replays within 30 minutes count once.
+// @Tags Songs
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Song ID"
+// @Success 200 {object} dto.Response
+// @Failure 404 {object} dto.Response
+// @Router /songs/{id}/plays [post]
+func (h *SongHandler) RecordPlay(c *gin.Context) {
+	userID, _ := middleware.GetUserID(c)
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid song ID"))
+		return
+	}
+
+	if err := h.songService.RecordPlay(userID, uint(id)); err != nil {
+		c.JSON(http.StatusNotFound, dto.ErrorResponse("Song not found"))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.SuccessResponse(nil, "Play recorded"))
+}
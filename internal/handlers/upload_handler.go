@@ -31,6 +31,7 @@ var AllowedAudioTypes = map[string]bool{
 	"audio/mp3":  true,
 	"audio/wav":  true,
 	"audio/ogg":  true,
+	"audio/webm": true,
 }
 
 type UploadHandler struct{}
@@ -102,7 +103,7 @@ func (h *UploadHandler) UploadImage(c *gin.Context) {
 
 // UploadAudio godoc
 // @Summary Upload an audio file
-// @Description Upload an audio file (mp3, wav, ogg) with max size 10MB
+// @Description Upload an audio file (mp3, wav, ogg, webm) with max size 10MB
 // @Tags Upload
 // @Accept multipart/form-data
 // @Produce json
@@ -128,7 +129,7 @@ func (h *UploadHandler) UploadAudio(c *gin.Context) {
 	// Check file type
 	contentType := header.Header.Get("Content-Type")
 	if !AllowedAudioTypes[contentType] {
-		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid file type. Allowed: mp3, wav, ogg"))
+		c.JSON(http.StatusBadRequest, dto.ErrorResponse("Invalid file type. Allowed: mp3, wav, ogg, webm"))
 		return
 	}
 
@@ -172,6 +173,7 @@ func getExtensionFromMime(mimeType string) string {
 		"audio/mp3":  ".mp3",
 		"audio/wav":  ".wav",
 		"audio/ogg":  ".ogg",
+		"audio/webm": ".webm",
 	}
 	if ext, ok := mimeToExt[strings.ToLower(mimeType)]; ok {
 		return ext
@@ -51,10 +51,10 @@ func (h *UserHandler) GetLeaderboard(c *gin.Context) {
 	// Build response with level info
 	userDTOs := make([]dto.UserDTO, len(users))
 	for i, user := range users {
+		// Public list: shown by display name, never with email
 		userDTO := dto.UserDTO{
 			ID:        user.ID,
-			Name:      user.Name,
-			Email:     user.Email,
+			Name:      user.DisplayName(),
 			Avatar:    user.Avatar,
 			Role:      string(user.Role),
 			Exp:       user.Exp,
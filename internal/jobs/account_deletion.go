@@ -0,0 +1,26 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Alfian57/ruang-tenang-api/internal/services"
+	"github.com/Alfian57/ruang-tenang-api/pkg/logger"
+	"github.com/Alfian57/ruang-tenang-api/pkg/scheduler"
+)
+
+// AccountDeletion erases accounts whose deletion grace period has passed
+func AccountDeletion(accountService *services.AccountService, interval time.Duration) scheduler.Job {
+	return scheduler.Job{
+		Name:     "account-deletion",
+		Interval: interval,
+		Run: func(ctx context.Context) error {
+			erased, err := accountService.PurgeDueDeletions(ctx)
+			if erased > 0 {
+				logger.Info(fmt.Sprintf("Erased %d deleted accounts", erased))
+			}
+			return err
+		},
+	}
+}
@@ -0,0 +1,62 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Alfian57/ruang-tenang-api/internal/services"
+	"github.com/Alfian57/ruang-tenang-api/pkg/logger"
+	"github.com/Alfian57/ruang-tenang-api/pkg/scheduler"
+)
+
+// RevocationSync refreshes the in-memory list of revoked sessions so
+// sign-outs on other instances take effect here too
+func RevocationSync(authService *services.AuthService, interval time.Duration) scheduler.Job {
+	return scheduler.Job{
+		Name:     "auth-revocation-sync",
+		Interval: interval,
+		Run: func(ctx context.Context) error {
+			return authService.SyncRevocations()
+		},
+	}
+}
+
+// AuthSessionCleanup deletes long expired or revoked sessions
+func AuthSessionCleanup(authService *services.AuthService, interval time.Duration) scheduler.Job {
+	return scheduler.Job{
+		Name:     "auth-session-cleanup",
+		Interval: interval,
+		Run: func(ctx context.Context) error {
+			deleted, err := authService.PurgeStaleSessions()
+			if deleted > 0 {
+				logger.Info(fmt.Sprintf("Deleted %d stale auth sessions", deleted))
+			}
+			return err
+		},
+	}
+}
+
+// OAuthStateCleanup deletes social sign-ins that were never completed
+func OAuthStateCleanup(socialAuthService *services.SocialAuthService, interval time.Duration) scheduler.Job {
+	return scheduler.Job{
+		Name:     "oauth-state-cleanup",
+		Interval: interval,
+		Run: func(ctx context.Context) error {
+			_, err := socialAuthService.PurgeExpiredStates()
+			return err
+		},
+	}
+}
+
+// LoginAttemptCleanup forgets failed sign-in counters that have run out
+func LoginAttemptCleanup(authService *services.AuthService, interval time.Duration) scheduler.Job {
+	return scheduler.Job{
+		Name:     "login-attempt-cleanup",
+		Interval: interval,
+		Run: func(ctx context.Context) error {
+			_, err := authService.PruneLoginAttempts()
+			return err
+		},
+	}
+}
@@ -0,0 +1,27 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Alfian57/ruang-tenang-api/internal/services"
+	"github.com/Alfian57/ruang-tenang-api/pkg/logger"
+	"github.com/Alfian57/ruang-tenang-api/pkg/scheduler"
+)
+
+// ChatTrashPurge permanently deletes chat sessions whose trash retention
+// period has passed
+func ChatTrashPurge(chatService *services.ChatService, interval time.Duration) scheduler.Job {
+	return scheduler.Job{
+		Name:     "chat-trash-purge",
+		Interval: interval,
+		Run: func(ctx context.Context) error {
+			purged, err := chatService.PurgeExpiredTrash(ctx)
+			if purged > 0 {
+				logger.Info(fmt.Sprintf("Purged %d expired chat sessions", purged))
+			}
+			return err
+		},
+	}
+}
@@ -0,0 +1,20 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/Alfian57/ruang-tenang-api/internal/services"
+	"github.com/Alfian57/ruang-tenang-api/pkg/scheduler"
+)
+
+// EmailDelivery sends queued emails and retries failed ones
+func EmailDelivery(emailService *services.EmailService, interval time.Duration) scheduler.Job {
+	return scheduler.Job{
+		Name:     "email-delivery",
+		Interval: interval,
+		Run: func(ctx context.Context) error {
+			return emailService.ProcessQueue(ctx)
+		},
+	}
+}
@@ -0,0 +1,32 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/Alfian57/ruang-tenang-api/internal/services"
+	"github.com/Alfian57/ruang-tenang-api/pkg/scheduler"
+)
+
+// NotificationDelivery delivers queued notifications on their channels and
+// retries failed ones
+func NotificationDelivery(notificationService *services.NotificationService, interval time.Duration) scheduler.Job {
+	return scheduler.Job{
+		Name:     "notification-delivery",
+		Interval: interval,
+		Run: func(ctx context.Context) error {
+			return notificationService.ProcessQueue(ctx)
+		},
+	}
+}
+
+// MoodReminders queues the mood check-in reminders that are due
+func MoodReminders(reminderService *services.ReminderService, interval time.Duration) scheduler.Job {
+	return scheduler.Job{
+		Name:     "mood-reminders",
+		Interval: interval,
+		Run: func(ctx context.Context) error {
+			return reminderService.ProcessDue(ctx)
+		},
+	}
+}
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"errors"
 	"net/http"
 	"strings"
 
@@ -9,6 +10,17 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// TokenValidator runs extra checks on a parsed access token, such as whether
+// its session was revoked
+type TokenValidator func(claims *utils.JWTClaims) error
+
+var tokenValidator TokenValidator
+
+// SetTokenValidator installs the check AuthMiddleware runs on every token
+func SetTokenValidator(validator TokenValidator) {
+	tokenValidator = validator
+}
+
 func AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
@@ -32,8 +44,17 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		if tokenValidator != nil {
+			if err := tokenValidator(claims); err != nil {
+				c.JSON(http.StatusUnauthorized, errorResponse(err))
+				c.Abort()
+				return
+			}
+		}
+
 		// Set user info in context
 		c.Set("user_id", claims.UserID)
+		c.Set("session_id", claims.SessionID)
 		c.Set("user_email", claims.Email)
 		c.Set("user_role", claims.Role)
 
@@ -67,6 +88,15 @@ func MemberMiddleware() gin.HandlerFunc {
 	}
 }
 
+// errorResponse includes the error code when the error carries one
+func errorResponse(err error) dto.Response {
+	var coded interface{ ErrorCode() string }
+	if errors.As(err, &coded) {
+		return dto.ErrorResponseWithCode(coded.ErrorCode(), err.Error())
+	}
+	return dto.ErrorResponse(err.Error())
+}
+
 // GetUserID helper to get user ID from context
 func GetUserID(c *gin.Context) (uint, bool) {
 	userID, exists := c.Get("user_id")
@@ -75,3 +105,12 @@ func GetUserID(c *gin.Context) (uint, bool) {
 	}
 	return userID.(uint), true
 }
+
+// GetSessionID helper to get the auth session ID from context
+func GetSessionID(c *gin.Context) (uint, bool) {
+	sessionID, exists := c.Get("session_id")
+	if !exists {
+		return 0, false
+	}
+	return sessionID.(uint), true
+}
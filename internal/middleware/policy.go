@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/Alfian57/ruang-tenang-api/internal/dto"
+	"github.com/gin-gonic/gin"
+)
+
+// Policy decides whether the signed-in user may use a feature. A non-nil
+// error rejects the request with 403, including the error code if it has one.
+type Policy func(userID uint) error
+
+// RequirePolicy guards routes behind AuthMiddleware with a policy
+func RequirePolicy(policy Policy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := GetUserID(c)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, dto.ErrorResponse("Authentication required"))
+			c.Abort()
+			return
+		}
+
+		if err := policy(userID); err != nil {
+			c.JSON(http.StatusForbidden, errorResponse(err))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -45,7 +45,7 @@ type Article struct {
 
 	// Relations
 	Category ArticleCategory `gorm:"foreignKey:ArticleCategoryID" json:"category,omitempty"`
-	Author   *User           `gorm:"foreignKey:UserID" json:"author,omitempty"`
+	Author   *PublicUser     `gorm:"foreignKey:UserID" json:"author,omitempty"`
 }
 
 func (Article) TableName() string {
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+)
+
+// AuthSession is one signed-in device. It holds the hash of the device's
+// current refresh token, which is replaced on every refresh.
+type AuthSession struct {
+	ID                uint       `gorm:"primaryKey" json:"id"`
+	UserID            uint       `gorm:"not null;index" json:"user_id"`
+	RefreshTokenHash  string     `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	PreviousTokenHash string     `gorm:"size:64;index" json:"-"` // last rotated-out token, to detect reuse
+	DeviceName        string     `gorm:"size:100" json:"device_name"`
+	UserAgent         string     `gorm:"size:255" json:"user_agent"`
+	IPAddress         string     `gorm:"size:45" json:"ip_address"`
+	ExpiresAt         time.Time  `gorm:"not null" json:"expires_at"`
+	LastUsedAt        time.Time  `json:"last_used_at"`
+	RevokedAt         *time.Time `gorm:"index" json:"revoked_at"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+
+	// Relations
+	User User `gorm:"foreignKey:UserID" json:"user,omitempty"`
+}
+
+func (AuthSession) TableName() string {
+	return "auth_sessions"
+}
+
+// IsActive reports whether the session can still be refreshed
+func (s *AuthSession) IsActive() bool {
+	return s.RevokedAt == nil && time.Now().Before(s.ExpiresAt)
+}
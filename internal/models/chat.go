@@ -13,15 +13,21 @@ const (
 	ChatRoleAI   ChatRole = "ai"
 )
 
+// DefaultChatSessionTitle is used until a title is generated from the first exchange
+const DefaultChatSessionTitle = "New chat"
+
 type ChatSession struct {
-	ID         uint           `gorm:"primaryKey" json:"id"`
-	UserID     uint           `gorm:"not null" json:"user_id"`
-	Title      string         `gorm:"size:255;not null" json:"title"`
-	IsFavorite bool           `gorm:"default:false" json:"is_favorite"`
-	IsTrash    bool           `gorm:"default:false" json:"is_trash"`
-	CreatedAt  time.Time      `json:"created_at"`
-	UpdatedAt  time.Time      `json:"updated_at"`
-	DeletedAt  gorm.DeletedAt `gorm:"index" json:"-"`
+	ID              uint           `gorm:"primaryKey" json:"id"`
+	UserID          uint           `gorm:"not null" json:"user_id"`
+	Title           string         `gorm:"size:255;not null" json:"title"`
+	IsFavorite      bool           `gorm:"default:false" json:"is_favorite"`
+	IsTrash         bool           `gorm:"default:false" json:"is_trash"`
+	TrashedAt       *time.Time     `gorm:"index" json:"trashed_at"`
+	Summary         string         `gorm:"type:text" json:"-"` // rolling summary of the first SummarizedCount messages
+	SummarizedCount int            `gorm:"default:0" json:"-"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"`
 
 	// Relations
 	User     User          `gorm:"foreignKey:UserID" json:"user,omitempty"`
@@ -33,15 +39,20 @@ func (ChatSession) TableName() string {
 }
 
 type ChatMessage struct {
-	ID            uint      `gorm:"primaryKey" json:"id"`
-	ChatSessionID uint      `gorm:"not null" json:"chat_session_id"`
-	Role          ChatRole  `gorm:"type:varchar(10);not null" json:"role"`
-	Content       string    `gorm:"type:text;not null" json:"content"`
-	Type          string    `gorm:"type:varchar(20);default:'text'" json:"type"` // text, audio
-	IsLiked       bool      `gorm:"default:false" json:"is_liked"`
-	IsDisliked    bool      `gorm:"default:false" json:"is_disliked"`
-	CreatedAt     time.Time `json:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at"`
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	ChatSessionID  uint      `gorm:"not null" json:"chat_session_id"`
+	Role           ChatRole  `gorm:"type:varchar(10);not null" json:"role"`
+	Content        string    `gorm:"type:text;not null" json:"content"`           // transcript for audio messages
+	Type           string    `gorm:"type:varchar(20);default:'text'" json:"type"` // text, audio
+	AudioURL       string    `gorm:"size:500" json:"audio_url,omitempty"`
+	RiskLevel      string    `gorm:"type:varchar(10);default:'none'" json:"risk_level"`
+	SystemPromptID *uint     `gorm:"index" json:"system_prompt_id,omitempty"` // prompt version that produced an AI message
+	Provider       string    `gorm:"size:30" json:"provider,omitempty"`
+	Model          string    `gorm:"size:100" json:"model,omitempty"`
+	IsLiked        bool      `gorm:"default:false" json:"is_liked"`
+	IsDisliked     bool      `gorm:"default:false" json:"is_disliked"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
 
 	// Relations
 	ChatSession ChatSession `gorm:"foreignKey:ChatSessionID" json:"chat_session,omitempty"`
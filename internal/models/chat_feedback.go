@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+)
+
+type FeedbackRating string
+
+const (
+	FeedbackLike    FeedbackRating = "like"
+	FeedbackDislike FeedbackRating = "dislike"
+	// FeedbackCleared records a user taking back an earlier like or dislike
+	FeedbackCleared FeedbackRating = "none"
+)
+
+// ChatFeedback is one like/dislike event on an AI message. The latest event
+// per message is its current rating; the prompt version and model are copied
+// from the message so analytics don't need to join back to it.
+type ChatFeedback struct {
+	ID             uint           `gorm:"primaryKey" json:"id"`
+	ChatMessageID  uint           `gorm:"not null;index" json:"chat_message_id"`
+	UserID         uint           `gorm:"not null" json:"user_id"`
+	Rating         FeedbackRating `gorm:"type:varchar(10);not null" json:"rating"`
+	Reason         string         `gorm:"type:text" json:"reason"`
+	SystemPromptID *uint          `json:"system_prompt_id"`
+	Provider       string         `gorm:"size:30" json:"provider"`
+	Model          string         `gorm:"size:100" json:"model"`
+	CreatedAt      time.Time      `gorm:"index" json:"created_at"`
+
+	// Relations
+	ChatMessage ChatMessage `gorm:"foreignKey:ChatMessageID" json:"chat_message,omitempty"`
+}
+
+func (ChatFeedback) TableName() string {
+	return "chat_message_feedback"
+}
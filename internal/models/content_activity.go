@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+)
+
+type ContentType string
+
+const (
+	ContentSong    ContentType = "song"
+	ContentArticle ContentType = "article"
+)
+
+// ContentActivity records a user playing a song or reading an article. The
+// category is copied so the history still means something after the content
+// is deleted.
+type ContentActivity struct {
+	ID          uint        `gorm:"primaryKey" json:"id"`
+	UserID      uint        `gorm:"not null;index:idx_content_activities_user_created,priority:1" json:"user_id"`
+	ContentType ContentType `gorm:"size:20;not null" json:"content_type"`
+	ContentID   uint        `gorm:"not null" json:"content_id"`
+	CategoryID  *uint       `json:"category_id"`
+	CreatedAt   time.Time   `gorm:"index:idx_content_activities_user_created,priority:2" json:"created_at"`
+}
+
+func (ContentActivity) TableName() string {
+	return "content_activities"
+}
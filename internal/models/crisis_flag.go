@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+)
+
+type CrisisFlagStatus string
+
+const (
+	CrisisFlagOpen      CrisisFlagStatus = "open"
+	CrisisFlagReviewed  CrisisFlagStatus = "reviewed"
+	CrisisFlagEscalated CrisisFlagStatus = "escalated"
+	CrisisFlagDismissed CrisisFlagStatus = "dismissed"
+)
+
+// CrisisFlag records a chat message that was screened as risky, for admin review
+type CrisisFlag struct {
+	ID            uint             `gorm:"primaryKey" json:"id"`
+	UserID        uint             `gorm:"not null;index" json:"user_id"`
+	ChatSessionID uint             `gorm:"not null" json:"chat_session_id"`
+	ChatMessageID uint             `gorm:"not null" json:"chat_message_id"`
+	RiskLevel     string           `gorm:"type:varchar(10);not null" json:"risk_level"`
+	MatchedTerms  string           `gorm:"type:text" json:"matched_terms"` // comma separated
+	Source        string           `gorm:"size:100" json:"source"`
+	Status        CrisisFlagStatus `gorm:"type:varchar(20);not null;default:'open'" json:"status"`
+	ReviewNotes   string           `gorm:"type:text" json:"review_notes"`
+	ReviewedBy    *uint            `json:"reviewed_by"`
+	ReviewedAt    *time.Time       `json:"reviewed_at"`
+	CreatedAt     time.Time        `json:"created_at"`
+	UpdatedAt     time.Time        `json:"updated_at"`
+
+	// Relations
+	User        User        `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	ChatMessage ChatMessage `gorm:"foreignKey:ChatMessageID" json:"chat_message,omitempty"`
+}
+
+func (CrisisFlag) TableName() string {
+	return "crisis_flags"
+}
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+)
+
+type EmailStatus string
+
+const (
+	EmailPending EmailStatus = "pending"
+	EmailSent    EmailStatus = "sent"
+	EmailFailed  EmailStatus = "failed" // gave up after the last retry
+)
+
+// EmailOutbox is a rendered email waiting to be delivered by the background
+// job, so a slow or unavailable mail server never blocks a request
+type EmailOutbox struct {
+	ID            uint        `gorm:"primaryKey" json:"id"`
+	Recipient     string      `gorm:"size:255;not null" json:"recipient"`
+	Template      string      `gorm:"size:50;not null" json:"template"`
+	Locale        string      `gorm:"size:10;not null;default:'id'" json:"locale"`
+	Subject       string      `gorm:"size:255;not null" json:"subject"`
+	TextBody      string      `gorm:"type:text;not null" json:"-"`
+	HTMLBody      string      `gorm:"column:html_body;type:text" json:"-"`
+	Status        EmailStatus `gorm:"type:varchar(20);not null;default:'pending'" json:"status"`
+	Attempts      int         `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt time.Time   `gorm:"not null" json:"next_attempt_at"`
+	LastError     string      `gorm:"type:text" json:"last_error"`
+	SentAt        *time.Time  `json:"sent_at"`
+	CreatedAt     time.Time   `json:"created_at"`
+	UpdatedAt     time.Time   `json:"updated_at"`
+}
+
+func (EmailOutbox) TableName() string {
+	return "email_outbox"
+}
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+)
+
+// EmailVerification is an emailed link that proves the user owns Email. It
+// confirms the address given at registration or, for an email change, the
+// new address, which only replaces the current one once confirmed.
+type EmailVerification struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"not null;index" json:"user_id"`
+	Email     string    `gorm:"size:255;not null" json:"email"`
+	TokenHash string    `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	ExpiresAt time.Time `gorm:"not null" json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+
+	// Relations
+	User User `gorm:"foreignKey:UserID" json:"user,omitempty"`
+}
+
+func (EmailVerification) TableName() string {
+	return "email_verifications"
+}
@@ -17,7 +17,7 @@ type Forum struct {
 	DeletedAt  gorm.DeletedAt `gorm:"index" json:"-"`
 
 	// Relations
-	User         User           `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	User         PublicUser     `gorm:"foreignKey:UserID" json:"user,omitempty"`
 	Category     *ForumCategory `gorm:"foreignKey:CategoryID" json:"category,omitempty"`
 	Posts        []ForumPost    `gorm:"foreignKey:ForumID" json:"posts,omitempty"`
 	Likes        []ForumLike    `gorm:"foreignKey:ForumID" json:"likes,omitempty"`
@@ -40,8 +40,8 @@ type ForumPost struct {
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
 
 	// Relations
-	Forum Forum `gorm:"foreignKey:ForumID" json:"forum,omitempty"`
-	User  User  `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	Forum Forum      `gorm:"foreignKey:ForumID" json:"forum,omitempty"`
+	User  PublicUser `gorm:"foreignKey:UserID" json:"user,omitempty"`
 }
 
 func (ForumPost) TableName() string {
@@ -0,0 +1,36 @@
+package models
+
+import (
+	"time"
+)
+
+type LockoutScope string
+
+const (
+	LockoutScopeAccount LockoutScope = "account"
+	LockoutScopeIP      LockoutScope = "ip"
+)
+
+// LoginAttempt is the shared failure counter of one account or client IP
+type LoginAttempt struct {
+	Key           string     `gorm:"primaryKey;size:320" json:"key"`
+	Failures      int        `gorm:"not null;default:0" json:"failures"`
+	LastFailureAt time.Time  `gorm:"not null" json:"last_failure_at"`
+	LockedUntil   *time.Time `json:"locked_until"`
+}
+
+// LoginLockout records a sign-in lockout for the audit trail
+type LoginLockout struct {
+	ID          uint         `gorm:"primaryKey" json:"id"`
+	Scope       LockoutScope `gorm:"size:20;not null" json:"scope"`
+	UserID      *uint        `gorm:"index" json:"user_id"`
+	Email       string       `gorm:"size:255;not null" json:"email"` // email, or anonymous pseudonym, tried when the lock started
+	IPAddress   string       `gorm:"size:45;not null" json:"ip_address"`
+	Failures    int          `gorm:"not null" json:"failures"`
+	LockedUntil time.Time    `gorm:"not null" json:"locked_until"`
+	UnlockedAt  *time.Time   `json:"unlocked_at"`
+	UnlockedBy  *uint        `json:"unlocked_by"`
+	CreatedAt   time.Time    `json:"created_at"`
+
+	User *User `gorm:"foreignKey:UserID" json:"user,omitempty"`
+}
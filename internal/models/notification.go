@@ -0,0 +1,68 @@
+package models
+
+import (
+	"time"
+)
+
+type NotificationStatus string
+
+const (
+	NotificationPending NotificationStatus = "pending"
+	NotificationSent    NotificationStatus = "sent"
+	NotificationFailed  NotificationStatus = "failed" // gave up, or the channel can't reach the user
+)
+
+// Notification channels
+const (
+	ChannelInApp   = "in_app"
+	ChannelEmail   = "email"
+	ChannelWebPush = "web_push"
+)
+
+// Notification types
+const (
+	NotificationMoodReminder = "mood_reminder"
+)
+
+// Notification is a message waiting to be delivered, or delivered, over one
+// channel. Notifying a user on several channels creates a row per channel.
+// Delivered in-app notifications make up the user's inbox.
+type Notification struct {
+	ID            uint               `gorm:"primaryKey" json:"id"`
+	UserID        uint               `gorm:"not null;index:idx_notifications_user_channel,priority:1" json:"user_id"`
+	Type          string             `gorm:"size:30;not null" json:"type"`
+	Channel       string             `gorm:"size:20;not null;index:idx_notifications_user_channel,priority:2" json:"channel"`
+	Locale        string             `gorm:"size:10;not null;default:'id'" json:"locale"`
+	Title         string             `gorm:"size:255;not null" json:"title"`
+	Body          string             `gorm:"type:text;not null" json:"body"`
+	Link          string             `gorm:"size:500;not null;default:''" json:"link"`
+	Status        NotificationStatus `gorm:"type:varchar(20);not null;default:'pending';index:idx_notifications_pending,priority:1" json:"status"`
+	Attempts      int                `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt time.Time          `gorm:"not null;index:idx_notifications_pending,priority:2" json:"next_attempt_at"`
+	LastError     string             `gorm:"type:text" json:"last_error"`
+	SentAt        *time.Time         `json:"sent_at"`
+	ReadAt        *time.Time         `json:"read_at"`
+	CreatedAt     time.Time          `gorm:"index:idx_notifications_user_channel,priority:3" json:"created_at"`
+	UpdatedAt     time.Time          `json:"updated_at"`
+}
+
+func (Notification) TableName() string {
+	return "notifications"
+}
+
+// PushSubscription is a browser the user allowed to receive Web Push
+// notifications
+type PushSubscription struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	UserID    uint      `gorm:"not null;index" json:"user_id"`
+	Endpoint  string    `gorm:"type:text;not null;uniqueIndex" json:"endpoint"`
+	P256dh    string    `gorm:"column:p256dh;size:255;not null" json:"-"`
+	Auth      string    `gorm:"size:255;not null" json:"-"`
+	UserAgent string    `gorm:"size:255;not null;default:''" json:"user_agent"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (PushSubscription) TableName() string {
+	return "push_subscriptions"
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// AllReminderDays is the Days bitmask with every weekday set
+const AllReminderDays = 1<<7 - 1
+
+// ReminderPreference configures when a user is reminded to log their mood.
+// Times, quiet hours and days are in the user's timezone.
+type ReminderPreference struct {
+	ID             uint       `gorm:"primaryKey" json:"id"`
+	UserID         uint       `gorm:"not null;uniqueIndex" json:"user_id"`
+	Enabled        bool       `gorm:"not null;default:false" json:"enabled"`
+	Times          string     `gorm:"size:50;not null" json:"times"`                 // comma-separated "HH:MM"
+	Days           int        `gorm:"type:smallint;not null" json:"days"`            // bit n is time.Weekday(n)
+	QuietStart     string     `gorm:"size:5;not null;default:''" json:"quiet_start"` // "HH:MM", empty without quiet hours
+	QuietEnd       string     `gorm:"size:5;not null;default:''" json:"quiet_end"`
+	Channels       string     `gorm:"size:100;not null" json:"channels"` // comma-separated
+	Locale         string     `gorm:"size:10;not null;default:'id'" json:"locale"`
+	NextReminderAt *time.Time `gorm:"index:idx_reminder_preferences_due,where:enabled" json:"next_reminder_at"`
+	LastRemindedAt *time.Time `json:"last_reminded_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+func (ReminderPreference) TableName() string {
+	return "reminder_preferences"
+}
+
+// TimeList returns the reminder times
+func (p *ReminderPreference) TimeList() []string {
+	return splitList(p.Times)
+}
+
+// ChannelList returns the channels reminders are sent on
+func (p *ReminderPreference) ChannelList() []string {
+	return splitList(p.Channels)
+}
+
+// OnDay reports whether reminders are sent on the weekday
+func (p *ReminderPreference) OnDay(day time.Weekday) bool {
+	return p.Days&(1<<day) != 0
+}
+
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+)
+
+// SystemPromptChat is the prompt name used for the AI chat companion
+const SystemPromptChat = "chat"
+
+// SystemPrompt is one version of an admin-editable system prompt.
+// Several versions of the same name and locale may be active at once; the
+// chat picks between them by Weight to allow A/B testing.
+type SystemPrompt struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	Name      string    `gorm:"size:50;not null;uniqueIndex:idx_system_prompts_version" json:"name"`
+	Locale    string    `gorm:"size:10;not null;uniqueIndex:idx_system_prompts_version" json:"locale"`
+	Version   int       `gorm:"not null;uniqueIndex:idx_system_prompts_version" json:"version"`
+	Content   string    `gorm:"type:text;not null" json:"content"`
+	Notes     string    `gorm:"size:255" json:"notes"`
+	IsActive  bool      `gorm:"default:false" json:"is_active"`
+	Weight    int       `gorm:"default:100" json:"weight"`
+	CreatedBy *uint     `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (SystemPrompt) TableName() string {
+	return "system_prompts"
+}
@@ -14,19 +14,28 @@ const (
 )
 
 type User struct {
-	ID               uint           `gorm:"primaryKey" json:"id"`
-	Name             string         `gorm:"size:255;not null" json:"name"`
-	Email            string         `gorm:"size:255;uniqueIndex;not null" json:"email"`
-	Password         string         `gorm:"size:255;not null" json:"-"`
-	Role             UserRole       `gorm:"type:varchar(20);default:'member'" json:"role"`
-	Exp              int64          `gorm:"default:0" json:"exp"`
-	Avatar           string         `gorm:"size:255;default:''" json:"avatar"`
-	IsBlocked        bool           `gorm:"default:false" json:"is_blocked"`
-	ResetToken       string         `gorm:"size:255" json:"-"`
-	ResetTokenExpiry time.Time      `json:"-"`
-	CreatedAt        time.Time      `json:"created_at"`
-	UpdatedAt        time.Time      `json:"updated_at"`
-	DeletedAt        gorm.DeletedAt `gorm:"index" json:"-"`
+	ID                  uint           `gorm:"primaryKey" json:"id"`
+	Name                string         `gorm:"size:255;not null" json:"name"`
+	Email               string         `gorm:"size:255;uniqueIndex:idx_users_email,where:email <> '';not null" json:"email"` // empty for anonymous accounts
+	Password            string         `gorm:"size:255;not null" json:"-"`
+	Role                UserRole       `gorm:"type:varchar(20);default:'member'" json:"role"`
+	Exp                 int64          `gorm:"default:0" json:"exp"`
+	Avatar              string         `gorm:"size:255;default:''" json:"avatar"`
+	IsBlocked           bool           `gorm:"default:false" json:"is_blocked"`
+	IsAnonymous         bool           `gorm:"not null;default:false" json:"is_anonymous"`
+	Pseudonym           *string        `gorm:"size:50;uniqueIndex:idx_users_pseudonym,expression:LOWER(pseudonym)" json:"pseudonym"`
+	EmailVerifiedAt     *time.Time     `json:"email_verified_at"`
+	TwoFactorSecret     string         `gorm:"type:text" json:"-"` // encrypted TOTP secret, set during enrollment
+	TwoFactorEnabledAt  *time.Time     `json:"-"`
+	TwoFactorLastStep   int64          `gorm:"not null;default:0" json:"-"` // last accepted TOTP step, against replays
+	TokenVersion        int            `gorm:"not null;default:0" json:"-"` // bumped to invalidate every issued token
+	ResetToken          string         `gorm:"size:255;index" json:"-"`     // SHA-256 of the emailed token
+	ResetTokenExpiry    time.Time      `json:"-"`
+	DeletionScheduledAt *time.Time     `gorm:"index:idx_users_deletion_scheduled_at,where:deletion_scheduled_at IS NOT NULL" json:"deletion_scheduled_at"` // self-service deletion runs after this
+	Timezone            string         `gorm:"size:64;not null;default:'Asia/Jakarta'" json:"timezone"`                                                    // IANA name, decides where the user's days start
+	CreatedAt           time.Time      `json:"created_at"`
+	UpdatedAt           time.Time      `json:"updated_at"`
+	DeletedAt           gorm.DeletedAt `gorm:"index" json:"-"`
 
 	// Relations
 	ChatSessions []ChatSession `gorm:"foreignKey:UserID" json:"chat_sessions,omitempty"`
@@ -44,3 +53,65 @@ func (u *User) IsAdmin() bool {
 func (u *User) IsMember() bool {
 	return u.Role == RoleMember
 }
+
+// IsEmailVerified reports whether the user confirmed their current email address
+func (u *User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
+// HasPassword reports whether the user can sign in with a password. Accounts
+// created through social login start without one.
+func (u *User) HasPassword() bool {
+	return u.Password != ""
+}
+
+// HasTwoFactor reports whether sign-ins need a second factor
+func (u *User) HasTwoFactor() bool {
+	return u.TwoFactorEnabledAt != nil
+}
+
+// IsDeletionScheduled reports whether the user asked to delete their account
+// and can still cancel
+func (u *User) IsDeletionScheduled() bool {
+	return u.DeletionScheduledAt != nil
+}
+
+// DisplayName is the name shown to other users: the pseudonym when the user
+// has one, otherwise their name
+func (u *User) DisplayName() string {
+	if u.Pseudonym != nil && *u.Pseudonym != "" {
+		return *u.Pseudonym
+	}
+	return u.Name
+}
+
+// PseudonymOrEmpty returns the pseudonym, or "" when the user has none
+func (u *User) PseudonymOrEmpty() string {
+	if u.Pseudonym == nil {
+		return ""
+	}
+	return *u.Pseudonym
+}
+
+// PublicUser is a user as they appear on community content. It loads only
+// public columns and reports the display name as the name, so neither the
+// real name of a pseudonymous user nor anyone's email can leak.
+type PublicUser struct {
+	ID        uint           `gorm:"primaryKey" json:"id"`
+	Name      string         `json:"name"`
+	Pseudonym *string        `json:"-"`
+	Avatar    string         `json:"avatar"`
+	Exp       int64          `json:"exp"`
+	DeletedAt gorm.DeletedAt `json:"-"`
+}
+
+func (PublicUser) TableName() string {
+	return "users"
+}
+
+func (u *PublicUser) AfterFind(tx *gorm.DB) error {
+	if u.Pseudonym != nil && *u.Pseudonym != "" {
+		u.Name = *u.Pseudonym
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+)
+
+// UserIdentity links a user to an account at an external identity provider
+// (e.g. Google), identified by the provider's stable subject ID
+type UserIdentity struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	UserID      uint       `gorm:"not null;index;uniqueIndex:uq_user_identities_user_provider" json:"user_id"`
+	Provider    string     `gorm:"size:30;not null;uniqueIndex:uq_user_identities_subject;uniqueIndex:uq_user_identities_user_provider" json:"provider"`
+	Subject     string     `gorm:"size:255;not null;uniqueIndex:uq_user_identities_subject" json:"-"`
+	Email       string     `gorm:"size:255" json:"email"`
+	LastLoginAt *time.Time `json:"last_login_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+
+	// Relations
+	User User `gorm:"foreignKey:UserID" json:"user,omitempty"`
+}
+
+func (UserIdentity) TableName() string {
+	return "user_identities"
+}
+
+// OAuthState is a social sign-in in progress. It is created when the user is
+// sent to the provider and consumed by the callback. UserID is set when a
+// signed-in user is linking a provider rather than signing in.
+type OAuthState struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	StateHash    string    `gorm:"size:64;not null;uniqueIndex" json:"-"`
+	Provider     string    `gorm:"size:30;not null" json:"provider"`
+	Nonce        string    `gorm:"size:64;not null" json:"-"`
+	CodeVerifier string    `gorm:"size:128;not null" json:"-"`
+	UserID       *uint     `json:"user_id"`
+	ExpiresAt    time.Time `gorm:"not null;index" json:"expires_at"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+func (OAuthState) TableName() string {
+	return "oauth_states"
+}
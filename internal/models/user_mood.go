@@ -15,21 +15,62 @@ const (
 	MoodCrying       MoodType = "crying"
 )
 
+// Valence scores a mood from -2 (most negative) to 2 (most positive) so
+// moods can be averaged and compared over time
+func (m MoodType) Valence() int {
+	switch m {
+	case MoodHappy:
+		return 2
+	case MoodAngry, MoodDisappointed:
+		return -1
+	case MoodSad, MoodCrying:
+		return -2
+	default:
+		return 0
+	}
+}
+
+// Social contexts describe who the user was with during a check-in
+const (
+	MoodContextAlone      = "alone"
+	MoodContextFamily     = "family"
+	MoodContextFriends    = "friends"
+	MoodContextPartner    = "partner"
+	MoodContextColleagues = "colleagues"
+	MoodContextOthers     = "others"
+)
+
+// UserMood is one mood check-in. Users can log several a day.
 type UserMood struct {
-	ID        uint      `gorm:"primaryKey" json:"id"`
-	UserID    uint      `gorm:"not null" json:"user_id"`
-	Mood      MoodType  `gorm:"type:varchar(20);not null" json:"mood"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	UserID        uint      `gorm:"not null;index:idx_user_moods_user_created,priority:1" json:"user_id"`
+	Mood          MoodType  `gorm:"type:varchar(20);not null" json:"mood"`
+	Intensity     *int      `gorm:"type:smallint" json:"intensity"` // 1-10, how strongly the mood is felt
+	Note          string    `gorm:"type:text;not null;default:''" json:"note"`
+	SocialContext string    `gorm:"size:20;not null;default:''" json:"social_context"`
+	CreatedAt     time.Time `gorm:"index:idx_user_moods_user_created,priority:2" json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
 
 	// Relations
-	User User `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	User User          `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	Tags []UserMoodTag `gorm:"foreignKey:UserMoodID" json:"tags,omitempty"`
 }
 
 func (UserMood) TableName() string {
 	return "user_moods"
 }
 
+// TagNames returns the names of the entry's tags of one kind
+func (m *UserMood) TagNames(kind MoodTagKind) []string {
+	names := []string{}
+	for _, tag := range m.Tags {
+		if tag.Kind == kind {
+			names = append(names, tag.Name)
+		}
+	}
+	return names
+}
+
 // GetMoodEmoji returns emoji for the mood
 func (m *UserMood) GetMoodEmoji() string {
 	switch m.Mood {
@@ -49,3 +90,22 @@ func (m *UserMood) GetMoodEmoji() string {
 		return "🙂"
 	}
 }
+
+type MoodTagKind string
+
+const (
+	MoodTagActivity MoodTagKind = "activity" // what the user was doing
+	MoodTagTrigger  MoodTagKind = "trigger"  // what influenced the mood
+)
+
+// UserMoodTag labels a mood check-in with an activity or trigger
+type UserMoodTag struct {
+	ID         uint        `gorm:"primaryKey" json:"id"`
+	UserMoodID uint        `gorm:"not null;uniqueIndex:idx_user_mood_tags_entry,priority:1" json:"user_mood_id"`
+	Kind       MoodTagKind `gorm:"size:20;not null;uniqueIndex:idx_user_mood_tags_entry,priority:2;index:idx_user_mood_tags_name,priority:1" json:"kind"`
+	Name       string      `gorm:"size:30;not null;uniqueIndex:idx_user_mood_tags_entry,priority:3;index:idx_user_mood_tags_name,priority:2" json:"name"`
+}
+
+func (UserMoodTag) TableName() string {
+	return "user_mood_tags"
+}
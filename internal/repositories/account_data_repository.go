@@ -0,0 +1,152 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/Alfian57/ruang-tenang-api/internal/models"
+	"gorm.io/gorm"
+)
+
+// AccountDataRepository reads and erases everything stored about one user,
+// for data exports and account deletion
+type AccountDataRepository struct {
+	db *gorm.DB
+}
+
+func NewAccountDataRepository(db *gorm.DB) *AccountDataRepository {
+	return &AccountDataRepository{db: db}
+}
+
+func (r *AccountDataRepository) FindMoods(userID uint) ([]models.UserMood, error) {
+	var moods []models.UserMood
+	err := r.db.Preload("Tags").Where("user_id = ?", userID).Order("created_at ASC").Find(&moods).Error
+	return moods, err
+}
+
+// FindChatSessions includes trashed and deleted sessions that haven't been
+// purged yet, since they are still stored
+func (r *AccountDataRepository) FindChatSessions(userID uint) ([]models.ChatSession, error) {
+	var sessions []models.ChatSession
+	err := r.db.Unscoped().
+		Preload("Messages", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at ASC, id ASC")
+		}).
+		Where("user_id = ?", userID).
+		Order("created_at ASC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+func (r *AccountDataRepository) FindForums(userID uint) ([]models.Forum, error) {
+	var forums []models.Forum
+	err := r.db.Where("user_id = ?", userID).Order("created_at ASC").Find(&forums).Error
+	return forums, err
+}
+
+func (r *AccountDataRepository) FindForumPosts(userID uint) ([]models.ForumPost, error) {
+	var posts []models.ForumPost
+	err := r.db.Where("user_id = ?", userID).Order("created_at ASC").Find(&posts).Error
+	return posts, err
+}
+
+func (r *AccountDataRepository) FindArticles(userID uint) ([]models.Article, error) {
+	var articles []models.Article
+	err := r.db.Where("user_id = ?", userID).Order("created_at ASC").Find(&articles).Error
+	return articles, err
+}
+
+func (r *AccountDataRepository) FindExpHistory(userID uint) ([]models.ExpHistory, error) {
+	var history []models.ExpHistory
+	err := r.db.Where("user_id = ?", userID).Order("created_at ASC").Find(&history).Error
+	return history, err
+}
+
+func (r *AccountDataRepository) FindContentActivities(userID uint) ([]models.ContentActivity, error) {
+	var activities []models.ContentActivity
+	err := r.db.Where("user_id = ?", userID).Order("created_at ASC").Find(&activities).Error
+	return activities, err
+}
+
+// FindDueForDeletion returns users whose deletion grace period has ended,
+// including ones an admin has since soft deleted
+func (r *AccountDataRepository) FindDueForDeletion(now time.Time, limit int) ([]uint, error) {
+	var ids []uint
+	err := r.db.Unscoped().Model(&models.User{}).
+		Where("deletion_scheduled_at IS NOT NULL AND deletion_scheduled_at <= ?", now).
+		Order("deletion_scheduled_at ASC").
+		Limit(limit).
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+// Erase permanently deletes the user and every row that belongs to them.
+// Chat sessions must be purged first so their audio files can be removed.
+// Rows others own but the user touched, like reviewed crisis flags, keep
+// their data without the reference.
+func (r *AccountDataRepository) Erase(userID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.Unscoped().Select("id", "email").First(&user, userID).Error; err != nil {
+			return err
+		}
+
+		// Replies, likes and topics, including what others wrote under the
+		// user's topics
+		topicIDs := tx.Unscoped().Model(&models.Forum{}).Select("id").Where("user_id = ?", userID)
+		moodIDs := tx.Model(&models.UserMood{}).Select("id").Where("user_id = ?", userID)
+		deletes := []struct {
+			model interface{}
+			query string
+			args  []interface{}
+		}{
+			{&models.ForumLike{}, "user_id = ? OR forum_id IN (?)", []interface{}{userID, topicIDs}},
+			{&models.ForumPost{}, "user_id = ? OR forum_id IN (?)", []interface{}{userID, topicIDs}},
+			{&models.Forum{}, "user_id = ?", []interface{}{userID}},
+			{&models.Article{}, "user_id = ?", []interface{}{userID}},
+			{&models.UserMoodTag{}, "user_mood_id IN (?)", []interface{}{moodIDs}},
+			{&models.UserMood{}, "user_id = ?", []interface{}{userID}},
+			{&models.ExpHistory{}, "user_id = ?", []interface{}{userID}},
+			{&models.UserActivity{}, "user_id = ?", []interface{}{userID}},
+			{&models.ContentActivity{}, "user_id = ?", []interface{}{userID}},
+			{&models.Notification{}, "user_id = ?", []interface{}{userID}},
+			{&models.ReminderPreference{}, "user_id = ?", []interface{}{userID}},
+			{&models.PushSubscription{}, "user_id = ?", []interface{}{userID}},
+			{&models.ChatFeedback{}, "user_id = ?", []interface{}{userID}},
+			{&models.CrisisFlag{}, "user_id = ?", []interface{}{userID}},
+			{&models.AuthSession{}, "user_id = ?", []interface{}{userID}},
+			{&models.EmailVerification{}, "user_id = ?", []interface{}{userID}},
+			{&models.UserIdentity{}, "user_id = ?", []interface{}{userID}},
+			{&models.OAuthState{}, "user_id = ?", []interface{}{userID}},
+			{&models.RecoveryCode{}, "user_id = ?", []interface{}{userID}},
+		}
+		for _, d := range deletes {
+			if err := tx.Unscoped().Where(d.query, d.args...).Delete(d.model).Error; err != nil {
+				return err
+			}
+		}
+
+		// Queued and sent emails hold rendered personal details
+		if user.Email != "" {
+			if err := tx.Where("recipient = ?", user.Email).Delete(&models.EmailOutbox{}).Error; err != nil {
+				return err
+			}
+		}
+
+		clears := []struct {
+			model  interface{}
+			column string
+		}{
+			{&models.CrisisFlag{}, "reviewed_by"},
+			{&models.SystemPrompt{}, "created_by"},
+			{&models.LoginLockout{}, "user_id"},
+			{&models.LoginLockout{}, "unlocked_by"},
+		}
+		for _, c := range clears {
+			if err := tx.Model(c.model).Where(c.column+" = ?", userID).Update(c.column, nil).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Unscoped().Delete(&models.User{}, userID).Error
+	})
+}
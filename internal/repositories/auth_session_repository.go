@@ -0,0 +1,113 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/Alfian57/ruang-tenang-api/internal/models"
+	"gorm.io/gorm"
+)
+
+type AuthSessionRepository struct {
+	db *gorm.DB
+}
+
+func NewAuthSessionRepository(db *gorm.DB) *AuthSessionRepository {
+	return &AuthSessionRepository{db: db}
+}
+
+func (r *AuthSessionRepository) Create(session *models.AuthSession) error {
+	return r.db.Create(session).Error
+}
+
+func (r *AuthSessionRepository) FindByID(id uint) (*models.AuthSession, error) {
+	var session models.AuthSession
+	err := r.db.First(&session, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *AuthSessionRepository) FindByTokenHash(hash string) (*models.AuthSession, error) {
+	var session models.AuthSession
+	err := r.db.Preload("User").Where("refresh_token_hash = ?", hash).First(&session).Error
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// FindByPreviousTokenHash finds the session a rotated-out refresh token belonged to
+func (r *AuthSessionRepository) FindByPreviousTokenHash(hash string) (*models.AuthSession, error) {
+	var session models.AuthSession
+	err := r.db.Where("previous_token_hash = ?", hash).First(&session).Error
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// FindActiveByUserID lists the user's signed-in devices, most recently used first
+func (r *AuthSessionRepository) FindActiveByUserID(userID uint) ([]models.AuthSession, error) {
+	var sessions []models.AuthSession
+	err := r.db.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_used_at DESC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+// Rotate swaps the session's refresh token, only if it still holds the
+// presented one, so two concurrent refreshes can't both succeed
+func (r *AuthSessionRepository) Rotate(session *models.AuthSession, newHash, ipAddress string) (bool, error) {
+	result := r.db.Model(&models.AuthSession{}).
+		Where("id = ? AND refresh_token_hash = ? AND revoked_at IS NULL", session.ID, session.RefreshTokenHash).
+		Updates(map[string]interface{}{
+			"previous_token_hash": session.RefreshTokenHash,
+			"refresh_token_hash":  newHash,
+			"ip_address":          ipAddress,
+			"last_used_at":        time.Now(),
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+func (r *AuthSessionRepository) Revoke(id uint) error {
+	return r.db.Model(&models.AuthSession{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now()).Error
+}
+
+// RevokeAllByUserID revokes every active session of a user except the one
+// given (0 revokes all) and returns the revoked IDs
+func (r *AuthSessionRepository) RevokeAllByUserID(userID, exceptID uint) ([]uint, error) {
+	var ids []uint
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		query := tx.Model(&models.AuthSession{}).Where("user_id = ? AND revoked_at IS NULL", userID)
+		if exceptID != 0 {
+			query = query.Where("id <> ?", exceptID)
+		}
+		if err := query.Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		return tx.Model(&models.AuthSession{}).Where("id IN ?", ids).Update("revoked_at", time.Now()).Error
+	})
+	return ids, err
+}
+
+// FindRevokedSince returns the ID and revocation time of sessions revoked
+// after the given time
+func (r *AuthSessionRepository) FindRevokedSince(since time.Time) ([]models.AuthSession, error) {
+	var sessions []models.AuthSession
+	err := r.db.Select("id", "revoked_at").
+		Where("revoked_at > ?", since).
+		Find(&sessions).Error
+	return sessions, err
+}
+
+// DeleteStale removes sessions that expired or were revoked before the cutoff
+func (r *AuthSessionRepository) DeleteStale(cutoff time.Time) (int64, error) {
+	result := r.db.Where("expires_at < ? OR revoked_at < ?", cutoff, cutoff).Delete(&models.AuthSession{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,105 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/Alfian57/ruang-tenang-api/internal/models"
+	"gorm.io/gorm"
+)
+
+// Feedback groupings supported by SatisfactionBy
+const (
+	FeedbackGroupDay    = "day"
+	FeedbackGroupPrompt = "prompt"
+	FeedbackGroupModel  = "model"
+)
+
+// feedbackGroupColumns are the group key expressions; the day key takes the
+// timezone days are counted in
+var feedbackGroupColumns = map[string]string{
+	FeedbackGroupDay:    "TO_CHAR(created_at AT TIME ZONE ?, 'YYYY-MM-DD')",
+	FeedbackGroupPrompt: "COALESCE(CAST(system_prompt_id AS TEXT), '')",
+	FeedbackGroupModel:  "COALESCE(NULLIF(provider, '') || '/' || model, '')",
+}
+
+// FeedbackAggregate is the like/dislike count for one group key
+type FeedbackAggregate struct {
+	Key      string `gorm:"column:group_key"`
+	Likes    int64
+	Dislikes int64
+}
+
+type ChatFeedbackRepository struct {
+	db *gorm.DB
+}
+
+func NewChatFeedbackRepository(db *gorm.DB) *ChatFeedbackRepository {
+	return &ChatFeedbackRepository{db: db}
+}
+
+// Record stores a feedback event and mirrors the resulting rating onto the
+// message's is_liked/is_disliked flags in one transaction
+func (r *ChatFeedbackRepository) Record(feedback *models.ChatFeedback) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(feedback).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.ChatMessage{}).Where("id = ?", feedback.ChatMessageID).
+			Updates(map[string]interface{}{
+				"is_liked":    feedback.Rating == models.FeedbackLike,
+				"is_disliked": feedback.Rating == models.FeedbackDislike,
+			}).Error
+	})
+}
+
+func (r *ChatFeedbackRepository) FindAll(rating string, since time.Time, page, limit int) ([]models.ChatFeedback, int64, error) {
+	var feedback []models.ChatFeedback
+	var total int64
+
+	query := r.db.Model(&models.ChatFeedback{}).Where("created_at >= ?", since)
+	if rating != "" {
+		query = query.Where("rating = ?", rating)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * limit
+	err := query.Preload("ChatMessage").
+		Order("created_at DESC").
+		Offset(offset).Limit(limit).
+		Find(&feedback).Error
+
+	return feedback, total, err
+}
+
+// SatisfactionBy counts likes and dislikes given since the cutoff, grouped by
+// day in loc, prompt version or model. Only the latest event per message
+// counts, so toggling a rating back and forth is not double counted.
+func (r *ChatFeedbackRepository) SatisfactionBy(group string, since time.Time, loc *time.Location) ([]FeedbackAggregate, error) {
+	if _, ok := feedbackGroupColumns[group]; !ok {
+		group = FeedbackGroupDay
+	}
+	var args []interface{}
+	if group == FeedbackGroupDay {
+		args = append(args, loc.String())
+	}
+
+	latest := r.db.Model(&models.ChatFeedback{}).
+		Select("DISTINCT ON (chat_message_id) chat_message_id, rating, system_prompt_id, provider, model, created_at").
+		Where("created_at >= ?", since).
+		Order("chat_message_id, created_at DESC, id DESC")
+
+	var results []FeedbackAggregate
+	err := r.db.Table("(?) AS latest", latest).
+		Select(feedbackGroupColumns[group]+" AS group_key, "+
+			"COUNT(*) FILTER (WHERE rating = 'like') AS likes, "+
+			"COUNT(*) FILTER (WHERE rating = 'dislike') AS dislikes", args...).
+		Where("rating <> ?", models.FeedbackCleared).
+		Group("group_key").
+		Order("group_key").
+		Scan(&results).Error
+
+	return results, err
+}
@@ -1,6 +1,8 @@
 package repositories
 
 import (
+	"time"
+
 	"github.com/Alfian57/ruang-tenang-api/internal/models"
 	"gorm.io/gorm"
 )
@@ -72,13 +74,112 @@ func (r *ChatSessionRepository) Update(session *models.ChatSession) error {
 	return r.db.Save(session).Error
 }
 
+// Touch bumps updated_at so the session sorts as recently active
+func (r *ChatSessionRepository) Touch(id uint) error {
+	return r.db.Model(&models.ChatSession{}).Where("id = ?", id).
+		Update("updated_at", time.Now()).Error
+}
+
+func (r *ChatSessionRepository) UpdateTitle(id uint, title string) error {
+	return r.db.Model(&models.ChatSession{}).Where("id = ?", id).
+		UpdateColumn("title", title).Error
+}
+
+// UpdateSummary replaces a summary of the first from messages with one of the
+// first summarizedCount. It reports false, leaving the session alone, when
+// another refresh has already moved the summary on from from.
+func (r *ChatSessionRepository) UpdateSummary(id uint, summary string, from, summarizedCount int) (bool, error) {
+	result := r.db.Model(&models.ChatSession{}).
+		Where("id = ? AND summarized_count = ?", id, from).
+		UpdateColumns(map[string]interface{}{
+			"summary":          summary,
+			"summarized_count": summarizedCount,
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
 func (r *ChatSessionRepository) Delete(id uint) error {
 	return r.db.Delete(&models.ChatSession{}, id).Error
 }
 
+// ToggleTrash moves a session to or from the trash, stamping when it was
+// trashed so the retention period can be enforced
 func (r *ChatSessionRepository) ToggleTrash(id uint) error {
 	return r.db.Model(&models.ChatSession{}).Where("id = ?", id).
-		Update("is_trash", gorm.Expr("NOT is_trash")).Error
+		Updates(map[string]interface{}{
+			"is_trash":   gorm.Expr("NOT is_trash"),
+			"trashed_at": gorm.Expr("CASE WHEN is_trash THEN NULL ELSE CURRENT_TIMESTAMP END"),
+		}).Error
+}
+
+func (r *ChatSessionRepository) Restore(id uint) error {
+	return r.db.Model(&models.ChatSession{}).Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"is_trash":   false,
+			"trashed_at": nil,
+		}).Error
+}
+
+// FindTrashedIDs returns the IDs of all sessions in a user's trash
+func (r *ChatSessionRepository) FindTrashedIDs(userID uint) ([]uint, error) {
+	var ids []uint
+	err := r.db.Model(&models.ChatSession{}).
+		Where("user_id = ? AND is_trash = ?", userID, true).
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+// FindAllIDsByUserID returns every session of the user, including deleted ones
+func (r *ChatSessionRepository) FindAllIDsByUserID(userID uint) ([]uint, error) {
+	var ids []uint
+	err := r.db.Unscoped().Model(&models.ChatSession{}).
+		Where("user_id = ?", userID).
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+// FindPurgeableIDs returns sessions that were trashed or deleted before the
+// cutoff, oldest first
+func (r *ChatSessionRepository) FindPurgeableIDs(cutoff time.Time, limit int) ([]uint, error) {
+	var ids []uint
+	err := r.db.Unscoped().Model(&models.ChatSession{}).
+		Where("(is_trash = ? AND trashed_at < ?) OR (deleted_at IS NOT NULL AND deleted_at < ?)", true, cutoff, cutoff).
+		Order("id ASC").
+		Limit(limit).
+		Pluck("id", &ids).Error
+	return ids, err
+}
+
+// Purge permanently deletes sessions with their messages and everything
+// attached to those messages. It returns the audio URLs of the deleted
+// messages so the files can be removed too.
+func (r *ChatSessionRepository) Purge(ids []uint) ([]string, error) {
+	var audioURLs []string
+	if len(ids) == 0 {
+		return audioURLs, nil
+	}
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.ChatMessage{}).
+			Where("chat_session_id IN ? AND audio_url <> ''", ids).
+			Pluck("audio_url", &audioURLs).Error; err != nil {
+			return err
+		}
+
+		messageIDs := tx.Model(&models.ChatMessage{}).Select("id").Where("chat_session_id IN ?", ids)
+		if err := tx.Where("chat_message_id IN (?)", messageIDs).Delete(&models.ChatFeedback{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("chat_session_id IN ?", ids).Delete(&models.CrisisFlag{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("chat_session_id IN ?", ids).Delete(&models.ChatMessage{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Where("id IN ?", ids).Delete(&models.ChatSession{}).Error
+	})
+
+	return audioURLs, err
 }
 
 func (r *ChatSessionRepository) ToggleFavorite(id uint) error {
@@ -112,19 +213,3 @@ func (r *ChatMessageRepository) FindByID(id uint) (*models.ChatMessage, error) {
 func (r *ChatMessageRepository) Update(message *models.ChatMessage) error {
 	return r.db.Save(message).Error
 }
-
-func (r *ChatMessageRepository) ToggleLike(id uint) error {
-	return r.db.Model(&models.ChatMessage{}).Where("id = ?", id).
-		Updates(map[string]interface{}{
-			"is_liked":    gorm.Expr("NOT is_liked"),
-			"is_disliked": false,
-		}).Error
-}
-
-func (r *ChatMessageRepository) ToggleDislike(id uint) error {
-	return r.db.Model(&models.ChatMessage{}).Where("id = ?", id).
-		Updates(map[string]interface{}{
-			"is_disliked": gorm.Expr("NOT is_disliked"),
-			"is_liked":    false,
-		}).Error
-}
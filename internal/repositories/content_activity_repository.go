@@ -0,0 +1,50 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/Alfian57/ruang-tenang-api/internal/models"
+	"gorm.io/gorm"
+)
+
+type ContentActivityRepository struct {
+	db *gorm.DB
+}
+
+func NewContentActivityRepository(db *gorm.DB) *ContentActivityRepository {
+	return &ContentActivityRepository{db: db}
+}
+
+func (r *ContentActivityRepository) Create(activity *models.ContentActivity) error {
+	return r.db.Create(activity).Error
+}
+
+// ExistsSince reports whether the user already opened the content after since
+func (r *ContentActivityRepository) ExistsSince(userID uint, contentType models.ContentType, contentID uint, since time.Time) bool {
+	var count int64
+	r.db.Model(&models.ContentActivity{}).
+		Where("user_id = ? AND content_type = ? AND content_id = ? AND created_at >= ?", userID, contentType, contentID, since).
+		Count(&count)
+	return count > 0
+}
+
+// ContentActivityPoint is a played song or read article with its category name
+type ContentActivityPoint struct {
+	ContentType  models.ContentType
+	CategoryID   *uint
+	CategoryName string
+	CreatedAt    time.Time
+}
+
+// FindPointsSince returns the user's activity from since onwards, oldest
+// first. Songs carry their category's current name.
+func (r *ContentActivityRepository) FindPointsSince(userID uint, since time.Time) ([]ContentActivityPoint, error) {
+	var points []ContentActivityPoint
+	err := r.db.Model(&models.ContentActivity{}).
+		Select("content_activities.content_type, content_activities.category_id, COALESCE(song_categories.name, '') AS category_name, content_activities.created_at").
+		Joins("LEFT JOIN song_categories ON content_activities.content_type = ? AND song_categories.id = content_activities.category_id", models.ContentSong).
+		Where("content_activities.user_id = ? AND content_activities.created_at >= ?", userID, since).
+		Order("content_activities.created_at ASC").
+		Scan(&points).Error
+	return points, err
+}
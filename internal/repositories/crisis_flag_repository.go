@@ -0,0 +1,57 @@
+package repositories
+
+import (
+	"github.com/Alfian57/ruang-tenang-api/internal/models"
+	"gorm.io/gorm"
+)
+
+type CrisisFlagRepository struct {
+	db *gorm.DB
+}
+
+func NewCrisisFlagRepository(db *gorm.DB) *CrisisFlagRepository {
+	return &CrisisFlagRepository{db: db}
+}
+
+func (r *CrisisFlagRepository) Create(flag *models.CrisisFlag) error {
+	return r.db.Create(flag).Error
+}
+
+func (r *CrisisFlagRepository) FindByID(id uint) (*models.CrisisFlag, error) {
+	var flag models.CrisisFlag
+	err := r.db.Preload("User").Preload("ChatMessage").First(&flag, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &flag, nil
+}
+
+func (r *CrisisFlagRepository) FindAll(status, riskLevel string, page, limit int) ([]models.CrisisFlag, int64, error) {
+	var flags []models.CrisisFlag
+	var total int64
+
+	query := r.db.Model(&models.CrisisFlag{})
+
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if riskLevel != "" {
+		query = query.Where("risk_level = ?", riskLevel)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * limit
+	err := query.Preload("User").Preload("ChatMessage").
+		Order("created_at DESC").
+		Offset(offset).Limit(limit).
+		Find(&flags).Error
+
+	return flags, total, err
+}
+
+func (r *CrisisFlagRepository) Update(flag *models.CrisisFlag) error {
+	return r.db.Omit("User", "ChatMessage").Save(flag).Error
+}
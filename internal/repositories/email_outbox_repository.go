@@ -0,0 +1,76 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/Alfian57/ruang-tenang-api/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type EmailOutboxRepository struct {
+	db *gorm.DB
+}
+
+func NewEmailOutboxRepository(db *gorm.DB) *EmailOutboxRepository {
+	return &EmailOutboxRepository{db: db}
+}
+
+func (r *EmailOutboxRepository) Create(email *models.EmailOutbox) error {
+	return r.db.Create(email).Error
+}
+
+// ClaimDue picks up to limit pending emails that are due and pushes their
+// next attempt back by lease, so another server instance running the same
+// job skips them while they are being sent
+func (r *EmailOutboxRepository) ClaimDue(limit int, lease time.Duration) ([]models.EmailOutbox, error) {
+	var emails []models.EmailOutbox
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", models.EmailPending, now).
+			Order("next_attempt_at ASC").
+			Limit(limit).
+			Find(&emails).Error
+		if err != nil || len(emails) == 0 {
+			return err
+		}
+
+		ids := make([]uint, len(emails))
+		for i := range emails {
+			ids[i] = emails[i].ID
+		}
+		return tx.Model(&models.EmailOutbox{}).
+			Where("id IN ?", ids).
+			Update("next_attempt_at", now.Add(lease)).Error
+	})
+
+	return emails, err
+}
+
+func (r *EmailOutboxRepository) MarkSent(id uint, attempts int) error {
+	return r.db.Model(&models.EmailOutbox{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":     models.EmailSent,
+			"attempts":   attempts,
+			"sent_at":    time.Now(),
+			"last_error": "",
+		}).Error
+}
+
+// MarkFailed records a failed attempt. A nil retryAt gives up on the email.
+func (r *EmailOutboxRepository) MarkFailed(id uint, attempts int, lastError string, retryAt *time.Time) error {
+	updates := map[string]interface{}{
+		"attempts":   attempts,
+		"last_error": lastError,
+	}
+	if retryAt != nil {
+		updates["next_attempt_at"] = *retryAt
+	} else {
+		updates["status"] = models.EmailFailed
+	}
+
+	return r.db.Model(&models.EmailOutbox{}).Where("id = ?", id).Updates(updates).Error
+}
@@ -0,0 +1,79 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/Alfian57/ruang-tenang-api/internal/models"
+	"gorm.io/gorm"
+)
+
+type EmailVerificationRepository struct {
+	db *gorm.DB
+}
+
+func NewEmailVerificationRepository(db *gorm.DB) *EmailVerificationRepository {
+	return &EmailVerificationRepository{db: db}
+}
+
+// Replace stores a new verification for the user and drops any earlier ones,
+// so only the most recently emailed link works
+func (r *EmailVerificationRepository) Replace(verification *models.EmailVerification) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", verification.UserID).Delete(&models.EmailVerification{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(verification).Error
+	})
+}
+
+// FindByTokenHash finds an unexpired verification
+func (r *EmailVerificationRepository) FindByTokenHash(hash string) (*models.EmailVerification, error) {
+	var verification models.EmailVerification
+	err := r.db.Where("token_hash = ? AND expires_at > ?", hash, time.Now()).First(&verification).Error
+	if err != nil {
+		return nil, err
+	}
+	return &verification, nil
+}
+
+// FindPendingByUserID returns the user's outstanding verification, if any
+func (r *EmailVerificationRepository) FindPendingByUserID(userID uint) (*models.EmailVerification, error) {
+	var verification models.EmailVerification
+	err := r.db.Where("user_id = ? AND expires_at > ?", userID, time.Now()).
+		Order("created_at DESC").
+		First(&verification).Error
+	if err != nil {
+		return nil, err
+	}
+	return &verification, nil
+}
+
+// Confirm marks the verified address as the user's email and uses up the
+// verification. Reset links sent to a previous address stop working. Returns
+// false when the verification was already used.
+func (r *EmailVerificationRepository) Confirm(verification *models.EmailVerification) (bool, error) {
+	confirmed := false
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ?", verification.ID).Delete(&models.EmailVerification{})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+
+		err := tx.Model(&models.User{}).Where("id = ?", verification.UserID).Updates(map[string]interface{}{
+			"email":              verification.Email,
+			"email_verified_at":  time.Now(),
+			"is_anonymous":       false, // an anonymous account becomes a full one
+			"reset_token":        nil,
+			"reset_token_expiry": nil,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		confirmed = true
+		return tx.Where("user_id = ?", verification.UserID).Delete(&models.EmailVerification{}).Error
+	})
+
+	return confirmed, err
+}
@@ -80,3 +80,21 @@ func (r *ExpHistoryRepository) GetActivityTypes() ([]string, error) {
 		Pluck("activity_type", &types).Error
 	return types, err
 }
+
+// ActivityTime is when the user earned EXP for an activity
+type ActivityTime struct {
+	ActivityType string
+	CreatedAt    time.Time
+}
+
+// FindActivityTimesSince returns the user's EXP-earning activities from
+// since onwards, oldest first
+func (r *ExpHistoryRepository) FindActivityTimesSince(userID uint, since time.Time) ([]ActivityTime, error) {
+	var times []ActivityTime
+	err := r.db.Model(&models.ExpHistory{}).
+		Select("activity_type, created_at").
+		Where("user_id = ? AND created_at >= ?", userID, since).
+		Order("created_at ASC").
+		Scan(&times).Error
+	return times, err
+}
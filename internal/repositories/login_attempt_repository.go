@@ -0,0 +1,82 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/Alfian57/ruang-tenang-api/internal/models"
+	"github.com/Alfian57/ruang-tenang-api/pkg/lockout"
+	"gorm.io/gorm"
+)
+
+// LoginAttemptRepository is the shared lockout.Store, so failed sign-ins
+// count across every instance
+type LoginAttemptRepository struct {
+	db *gorm.DB
+}
+
+var _ lockout.Store = (*LoginAttemptRepository)(nil)
+
+func NewLoginAttemptRepository(db *gorm.DB) *LoginAttemptRepository {
+	return &LoginAttemptRepository{db: db}
+}
+
+func (r *LoginAttemptRepository) Get(key string) (lockout.Record, error) {
+	var attempts []models.LoginAttempt
+	if err := r.db.Where("key = ?", key).Limit(1).Find(&attempts).Error; err != nil {
+		return lockout.Record{}, err
+	}
+	if len(attempts) == 0 {
+		return lockout.Record{}, nil
+	}
+	return toLockoutRecord(attempts[0]), nil
+}
+
+// AddFailure increments the counter in a single upsert so concurrent
+// failures are all counted
+func (r *LoginAttemptRepository) AddFailure(key string, now time.Time, window time.Duration) (lockout.Record, error) {
+	var attempt models.LoginAttempt
+	err := r.db.Raw(`
+		INSERT INTO login_attempts (key, failures, last_failure_at)
+		VALUES (?, 1, ?)
+		ON CONFLICT (key) DO UPDATE SET
+			failures = CASE WHEN login_attempts.last_failure_at <= ? THEN 1 ELSE login_attempts.failures + 1 END,
+			last_failure_at = EXCLUDED.last_failure_at
+		RETURNING key, failures, last_failure_at, locked_until`,
+		key, now, now.Add(-window),
+	).Scan(&attempt).Error
+	if err != nil {
+		return lockout.Record{}, err
+	}
+	return toLockoutRecord(attempt), nil
+}
+
+func (r *LoginAttemptRepository) Lock(key string, until time.Time) error {
+	return r.db.Model(&models.LoginAttempt{}).
+		Where("key = ?", key).
+		Updates(map[string]interface{}{
+			"failures":     0,
+			"locked_until": until,
+		}).Error
+}
+
+func (r *LoginAttemptRepository) Reset(key string) error {
+	return r.db.Where("key = ?", key).Delete(&models.LoginAttempt{}).Error
+}
+
+func (r *LoginAttemptRepository) Prune(prefix string, before time.Time) (int64, error) {
+	result := r.db.
+		Where("key LIKE ? AND last_failure_at < ? AND (locked_until IS NULL OR locked_until <= ?)", prefix+"%", before, time.Now()).
+		Delete(&models.LoginAttempt{})
+	return result.RowsAffected, result.Error
+}
+
+func toLockoutRecord(attempt models.LoginAttempt) lockout.Record {
+	record := lockout.Record{
+		Failures:      attempt.Failures,
+		LastFailureAt: attempt.LastFailureAt,
+	}
+	if attempt.LockedUntil != nil {
+		record.LockedUntil = *attempt.LockedUntil
+	}
+	return record
+}
@@ -0,0 +1,53 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/Alfian57/ruang-tenang-api/internal/models"
+	"gorm.io/gorm"
+)
+
+type LoginLockoutRepository struct {
+	db *gorm.DB
+}
+
+func NewLoginLockoutRepository(db *gorm.DB) *LoginLockoutRepository {
+	return &LoginLockoutRepository{db: db}
+}
+
+func (r *LoginLockoutRepository) Create(lockout *models.LoginLockout) error {
+	return r.db.Create(lockout).Error
+}
+
+// FindAll lists lockouts newest first, optionally for one user
+func (r *LoginLockoutRepository) FindAll(userID uint, page, limit int) ([]models.LoginLockout, int64, error) {
+	var lockouts []models.LoginLockout
+	var total int64
+
+	query := r.db.Model(&models.LoginLockout{})
+	if userID != 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * limit
+	err := query.Preload("User").
+		Order("created_at DESC").
+		Offset(offset).Limit(limit).
+		Find(&lockouts).Error
+	return lockouts, total, err
+}
+
+// MarkUnlocked records an admin ending the user's active account lockouts
+func (r *LoginLockoutRepository) MarkUnlocked(userID, adminID uint) error {
+	now := time.Now()
+	return r.db.Model(&models.LoginLockout{}).
+		Where("user_id = ? AND scope = ? AND unlocked_at IS NULL AND locked_until > ?", userID, models.LockoutScopeAccount, now).
+		Updates(map[string]interface{}{
+			"unlocked_at": now,
+			"unlocked_by": adminID,
+		}).Error
+}
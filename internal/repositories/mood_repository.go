@@ -4,9 +4,23 @@ import (
 	"time"
 
 	"github.com/Alfian57/ruang-tenang-api/internal/models"
+	"github.com/Alfian57/ruang-tenang-api/pkg/clock"
 	"gorm.io/gorm"
 )
 
+// TagUsage is how often a user has tagged check-ins with one tag
+type TagUsage struct {
+	Kind  models.MoodTagKind
+	Name  string
+	Count int64
+}
+
+// MoodPoint is the part of a check-in that mood analytics needs
+type MoodPoint struct {
+	Mood      models.MoodType
+	CreatedAt time.Time
+}
+
 type UserMoodRepository struct {
 	db *gorm.DB
 }
@@ -15,11 +29,14 @@ func NewUserMoodRepository(db *gorm.DB) *UserMoodRepository {
 	return &UserMoodRepository{db: db}
 }
 
+// Create saves the check-in together with its tags
 func (r *UserMoodRepository) Create(mood *models.UserMood) error {
 	return r.db.Create(mood).Error
 }
 
-func (r *UserMoodRepository) FindByUserID(userID uint, startDate, endDate *time.Time, page, limit int) ([]models.UserMood, int64, error) {
+// FindByUserID pages through check-ins, newest first. Mood and tag filters
+// are skipped when empty.
+func (r *UserMoodRepository) FindByUserID(userID uint, startDate, endDate *time.Time, mood, tag string, page, limit int) ([]models.UserMood, int64, error) {
 	var moods []models.UserMood
 	var total int64
 
@@ -33,40 +50,54 @@ func (r *UserMoodRepository) FindByUserID(userID uint, startDate, endDate *time.
 		query = query.Where("created_at <= ?", endDate)
 	}
 
+	if mood != "" {
+		query = query.Where("mood = ?", mood)
+	}
+
+	if tag != "" {
+		query = query.Where("id IN (?)", r.db.Model(&models.UserMoodTag{}).Select("user_mood_id").Where("name = ?", tag))
+	}
+
 	query.Count(&total)
 
 	offset := (page - 1) * limit
-	err := query.Order("created_at DESC").Offset(offset).Limit(limit).Find(&moods).Error
+	err := query.Preload("Tags").Order("created_at DESC").Offset(offset).Limit(limit).Find(&moods).Error
 
 	return moods, total, err
 }
 
-func (r *UserMoodRepository) GetLatestByUserID(userID uint) (*models.UserMood, error) {
+// FindByIDAndUserID returns one of the user's check-ins with its tags
+func (r *UserMoodRepository) FindByIDAndUserID(id, userID uint) (*models.UserMood, error) {
 	var mood models.UserMood
-	err := r.db.Where("user_id = ?", userID).Order("created_at DESC").First(&mood).Error
+	err := r.db.Preload("Tags").Where("id = ? AND user_id = ?", id, userID).First(&mood).Error
 	if err != nil {
 		return nil, err
 	}
 	return &mood, nil
 }
 
-func (r *UserMoodRepository) GetMoodStats(userID uint, days int) (map[string]int, error) {
-	stats := make(map[string]int)
-
-	// Use Asia/Jakarta timezone (UTC+7) for consistent date handling
-	loc, err := time.LoadLocation("Asia/Jakarta")
+func (r *UserMoodRepository) GetLatestByUserID(userID uint) (*models.UserMood, error) {
+	var mood models.UserMood
+	err := r.db.Preload("Tags").Where("user_id = ?", userID).Order("created_at DESC").First(&mood).Error
 	if err != nil {
-		loc = time.FixedZone("WIB", 7*60*60)
+		return nil, err
 	}
+	return &mood, nil
+}
+
+// GetMoodStats counts the user's moods over the last days calendar days,
+// today included, in loc
+func (r *UserMoodRepository) GetMoodStats(userID uint, days int, loc *time.Location) (map[string]int, error) {
+	stats := make(map[string]int)
 
-	startDate := time.Now().In(loc).AddDate(0, 0, -days)
+	startDate := clock.Today(loc).AddDate(0, 0, -(days - 1))
 
 	var results []struct {
 		Mood  string
 		Count int
 	}
 
-	err = r.db.Model(&models.UserMood{}).
+	err := r.db.Model(&models.UserMood{}).
 		Select("mood, COUNT(*) as count").
 		Where("user_id = ? AND created_at >= ?", userID, startDate).
 		Group("mood").
@@ -83,22 +114,36 @@ func (r *UserMoodRepository) GetMoodStats(userID uint, days int) (map[string]int
 	return stats, nil
 }
 
-// FindTodayByUserID finds today's mood for a user
-func (r *UserMoodRepository) FindTodayByUserID(userID uint) (*models.UserMood, error) {
-	var mood models.UserMood
+// FindPointsSince returns the user's check-ins from since onwards, oldest first
+func (r *UserMoodRepository) FindPointsSince(userID uint, since time.Time) ([]MoodPoint, error) {
+	var points []MoodPoint
+	err := r.db.Model(&models.UserMood{}).
+		Select("mood, created_at").
+		Where("user_id = ? AND created_at >= ?", userID, since).
+		Order("created_at ASC").
+		Scan(&points).Error
+	return points, err
+}
 
-	// Use Asia/Jakarta timezone (UTC+7) for consistent date handling
-	loc, err := time.LoadLocation("Asia/Jakarta")
-	if err != nil {
-		// Fallback to fixed UTC+7 offset if timezone data not available
-		loc = time.FixedZone("WIB", 7*60*60)
-	}
+// FindCheckInDays returns the distinct days in loc the user checked in on,
+// newest first, for computing logging streaks. Days come back as midnight UTC.
+func (r *UserMoodRepository) FindCheckInDays(userID uint, loc *time.Location) ([]time.Time, error) {
+	var days []time.Time
+	err := r.db.Model(&models.UserMood{}).
+		Select("DISTINCT DATE(created_at AT TIME ZONE ?) AS day", loc.String()).
+		Where("user_id = ?", userID).
+		Order("day DESC").
+		Scan(&days).Error
+	return days, err
+}
+
+// FindTodayByUserID finds a mood the user logged today in loc
+func (r *UserMoodRepository) FindTodayByUserID(userID uint, loc *time.Location) (*models.UserMood, error) {
+	var mood models.UserMood
 
-	now := time.Now().In(loc)
-	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
-	endOfDay := startOfDay.Add(24 * time.Hour)
+	startOfDay, endOfDay := clock.DayBounds(time.Now(), loc)
 
-	err = r.db.Where("user_id = ? AND created_at >= ? AND created_at < ?", userID, startOfDay, endOfDay).
+	err := r.db.Where("user_id = ? AND created_at >= ? AND created_at < ?", userID, startOfDay, endOfDay).
 		First(&mood).Error
 	if err != nil {
 		return nil, err
@@ -106,7 +151,61 @@ func (r *UserMoodRepository) FindTodayByUserID(userID uint) (*models.UserMood, e
 	return &mood, nil
 }
 
-// Update updates an existing mood record
+// Update saves the check-in's fields and replaces its tags
 func (r *UserMoodRepository) Update(mood *models.UserMood) error {
-	return r.db.Save(mood).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.UserMood{}).Where("id = ?", mood.ID).Updates(map[string]interface{}{
+			"mood":           mood.Mood,
+			"intensity":      mood.Intensity,
+			"note":           mood.Note,
+			"social_context": mood.SocialContext,
+			"updated_at":     time.Now(),
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Where("user_mood_id = ?", mood.ID).Delete(&models.UserMoodTag{}).Error; err != nil {
+			return err
+		}
+		for i := range mood.Tags {
+			mood.Tags[i].ID = 0
+			mood.Tags[i].UserMoodID = mood.ID
+		}
+		if len(mood.Tags) > 0 {
+			return tx.Create(&mood.Tags).Error
+		}
+		return nil
+	})
+}
+
+// Delete permanently removes one of the user's check-ins and its tags,
+// reporting whether it existed
+func (r *UserMoodRepository) Delete(id, userID uint) (bool, error) {
+	deleted := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		ids := tx.Model(&models.UserMood{}).Select("id").Where("id = ? AND user_id = ?", id, userID)
+		if err := tx.Where("user_mood_id IN (?)", ids).Delete(&models.UserMoodTag{}).Error; err != nil {
+			return err
+		}
+
+		result := tx.Where("id = ? AND user_id = ?", id, userID).Delete(&models.UserMood{})
+		deleted = result.RowsAffected > 0
+		return result.Error
+	})
+	return deleted, err
+}
+
+// GetTagUsage counts how often the user has used each tag, most used first
+func (r *UserMoodRepository) GetTagUsage(userID uint, limit int) ([]TagUsage, error) {
+	var usage []TagUsage
+	err := r.db.Model(&models.UserMoodTag{}).
+		Select("user_mood_tags.kind, user_mood_tags.name, COUNT(*) AS count").
+		Joins("JOIN user_moods ON user_moods.id = user_mood_tags.user_mood_id").
+		Where("user_moods.user_id = ?", userID).
+		Group("user_mood_tags.kind, user_mood_tags.name").
+		Order("count DESC, user_mood_tags.name ASC").
+		Limit(limit).
+		Scan(&usage).Error
+	return usage, err
 }
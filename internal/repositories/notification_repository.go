@@ -0,0 +1,133 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/Alfian57/ruang-tenang-api/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type NotificationRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationRepository(db *gorm.DB) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+
+// CreateBatch stores the per-channel rows of one notification together
+func (r *NotificationRepository) CreateBatch(notifications []models.Notification) error {
+	return r.db.Create(&notifications).Error
+}
+
+// ClaimDue picks up to limit pending notifications that are due and pushes
+// their next attempt back by lease, like EmailOutboxRepository.ClaimDue
+func (r *NotificationRepository) ClaimDue(limit int, lease time.Duration) ([]models.Notification, error) {
+	var notifications []models.Notification
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", models.NotificationPending, now).
+			Order("next_attempt_at ASC").
+			Limit(limit).
+			Find(&notifications).Error
+		if err != nil || len(notifications) == 0 {
+			return err
+		}
+
+		ids := make([]uint, len(notifications))
+		for i := range notifications {
+			ids[i] = notifications[i].ID
+		}
+		return tx.Model(&models.Notification{}).
+			Where("id IN ?", ids).
+			Update("next_attempt_at", now.Add(lease)).Error
+	})
+
+	return notifications, err
+}
+
+func (r *NotificationRepository) MarkSent(id uint, attempts int) error {
+	return r.db.Model(&models.Notification{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":     models.NotificationSent,
+			"attempts":   attempts,
+			"sent_at":    time.Now(),
+			"last_error": "",
+		}).Error
+}
+
+// MarkFailed records a failed attempt. A nil retryAt gives up on the
+// notification.
+func (r *NotificationRepository) MarkFailed(id uint, attempts int, lastError string, retryAt *time.Time) error {
+	updates := map[string]interface{}{
+		"attempts":   attempts,
+		"last_error": lastError,
+	}
+	if retryAt != nil {
+		updates["next_attempt_at"] = *retryAt
+	} else {
+		updates["status"] = models.NotificationFailed
+	}
+
+	return r.db.Model(&models.Notification{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// inbox scopes to the user's delivered in-app notifications
+func (r *NotificationRepository) inbox(userID uint) *gorm.DB {
+	return r.db.Model(&models.Notification{}).
+		Where("user_id = ? AND channel = ? AND status = ?", userID, models.ChannelInApp, models.NotificationSent)
+}
+
+// FindInbox lists the user's in-app notifications, newest first
+func (r *NotificationRepository) FindInbox(userID uint, unreadOnly bool, page, limit int) ([]models.Notification, int64, error) {
+	var notifications []models.Notification
+	var total int64
+
+	query := r.inbox(userID)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Order("created_at DESC").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&notifications).Error
+
+	return notifications, total, err
+}
+
+func (r *NotificationRepository) CountUnread(userID uint) (int64, error) {
+	var count int64
+	err := r.inbox(userID).Where("read_at IS NULL").Count(&count).Error
+	return count, err
+}
+
+// MarkRead marks one of the user's in-app notifications read. It reports
+// false when the user has no such notification.
+func (r *NotificationRepository) MarkRead(id, userID uint) (bool, error) {
+	var count int64
+	if err := r.inbox(userID).Where("id = ?", id).Count(&count).Error; err != nil || count == 0 {
+		return false, err
+	}
+
+	err := r.inbox(userID).
+		Where("id = ? AND read_at IS NULL", id).
+		Update("read_at", time.Now()).Error
+	return true, err
+}
+
+// MarkAllRead marks every unread in-app notification of the user read
+func (r *NotificationRepository) MarkAllRead(userID uint) (int64, error) {
+	result := r.inbox(userID).
+		Where("read_at IS NULL").
+		Update("read_at", time.Now())
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,43 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/Alfian57/ruang-tenang-api/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type OAuthStateRepository struct {
+	db *gorm.DB
+}
+
+func NewOAuthStateRepository(db *gorm.DB) *OAuthStateRepository {
+	return &OAuthStateRepository{db: db}
+}
+
+func (r *OAuthStateRepository) Create(state *models.OAuthState) error {
+	return r.db.Create(state).Error
+}
+
+// Consume deletes and returns an unexpired state, so each sign-in attempt
+// can be completed only once
+func (r *OAuthStateRepository) Consume(stateHash string) (*models.OAuthState, error) {
+	var state models.OAuthState
+	err := r.db.Clauses(clause.Returning{}).
+		Where("state_hash = ? AND expires_at > ?", stateHash, time.Now()).
+		Delete(&state).Error
+	if err != nil {
+		return nil, err
+	}
+	if state.ID == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &state, nil
+}
+
+// DeleteExpired removes sign-ins that were never completed
+func (r *OAuthStateRepository) DeleteExpired() (int64, error) {
+	result := r.db.Where("expires_at <= ?", time.Now()).Delete(&models.OAuthState{})
+	return result.RowsAffected, result.Error
+}
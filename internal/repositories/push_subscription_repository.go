@@ -0,0 +1,43 @@
+package repositories
+
+import (
+	"github.com/Alfian57/ruang-tenang-api/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PushSubscriptionRepository struct {
+	db *gorm.DB
+}
+
+func NewPushSubscriptionRepository(db *gorm.DB) *PushSubscriptionRepository {
+	return &PushSubscriptionRepository{db: db}
+}
+
+// Upsert saves a subscription. Browsers keep the endpoint when they refresh
+// the keys, and a shared browser may move to another user, so an existing
+// endpoint is taken over.
+func (r *PushSubscriptionRepository) Upsert(sub *models.PushSubscription) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "endpoint"}},
+		DoUpdates: clause.AssignmentColumns([]string{"user_id", "p256dh", "auth", "user_agent", "updated_at"}),
+	}).Create(sub).Error
+}
+
+func (r *PushSubscriptionRepository) FindByUserID(userID uint) ([]models.PushSubscription, error) {
+	var subs []models.PushSubscription
+	err := r.db.Where("user_id = ?", userID).Order("created_at ASC").Find(&subs).Error
+	return subs, err
+}
+
+// DeleteByEndpoint removes one of the user's subscriptions. It reports false
+// when the user has no subscription with the endpoint.
+func (r *PushSubscriptionRepository) DeleteByEndpoint(userID uint, endpoint string) (bool, error) {
+	result := r.db.Where("user_id = ? AND endpoint = ?", userID, endpoint).Delete(&models.PushSubscription{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// Delete removes a subscription the push service no longer accepts
+func (r *PushSubscriptionRepository) Delete(id uint) error {
+	return r.db.Delete(&models.PushSubscription{}, id).Error
+}
@@ -0,0 +1,74 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/Alfian57/ruang-tenang-api/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ReminderPreferenceRepository struct {
+	db *gorm.DB
+}
+
+func NewReminderPreferenceRepository(db *gorm.DB) *ReminderPreferenceRepository {
+	return &ReminderPreferenceRepository{db: db}
+}
+
+func (r *ReminderPreferenceRepository) FindByUserID(userID uint) (*models.ReminderPreference, error) {
+	var pref models.ReminderPreference
+	err := r.db.Where("user_id = ?", userID).First(&pref).Error
+	if err != nil {
+		return nil, err
+	}
+	return &pref, nil
+}
+
+// Save creates or updates the user's preference
+func (r *ReminderPreferenceRepository) Save(pref *models.ReminderPreference) error {
+	return r.db.Save(pref).Error
+}
+
+// ClaimDue picks up to limit enabled preferences whose reminder is due and
+// pushes it back by lease, so another server instance running the same job
+// skips them. The returned rows keep the reminder time that was due.
+func (r *ReminderPreferenceRepository) ClaimDue(limit int, lease time.Duration) ([]models.ReminderPreference, error) {
+	var prefs []models.ReminderPreference
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("enabled AND next_reminder_at <= ?", now).
+			Order("next_reminder_at ASC").
+			Limit(limit).
+			Find(&prefs).Error
+		if err != nil || len(prefs) == 0 {
+			return err
+		}
+
+		ids := make([]uint, len(prefs))
+		for i := range prefs {
+			ids[i] = prefs[i].ID
+		}
+		return tx.Model(&models.ReminderPreference{}).
+			Where("id IN ?", ids).
+			Update("next_reminder_at", now.Add(lease)).Error
+	})
+
+	return prefs, err
+}
+
+// SetNextReminder schedules the next reminder. A nil next stops reminders
+// until the preference is saved again.
+func (r *ReminderPreferenceRepository) SetNextReminder(id uint, next *time.Time) error {
+	return r.db.Model(&models.ReminderPreference{}).
+		Where("id = ?", id).
+		Update("next_reminder_at", next).Error
+}
+
+func (r *ReminderPreferenceRepository) MarkReminded(id uint, at time.Time) error {
+	return r.db.Model(&models.ReminderPreference{}).
+		Where("id = ?", id).
+		Update("last_reminded_at", at).Error
+}
@@ -0,0 +1,95 @@
+package repositories
+
+import (
+	"github.com/Alfian57/ruang-tenang-api/internal/models"
+	"gorm.io/gorm"
+)
+
+type SystemPromptRepository struct {
+	db *gorm.DB
+}
+
+func NewSystemPromptRepository(db *gorm.DB) *SystemPromptRepository {
+	return &SystemPromptRepository{db: db}
+}
+
+func (r *SystemPromptRepository) FindAll(name, locale string) ([]models.SystemPrompt, error) {
+	var prompts []models.SystemPrompt
+
+	query := r.db.Model(&models.SystemPrompt{})
+	if name != "" {
+		query = query.Where("name = ?", name)
+	}
+	if locale != "" {
+		query = query.Where("locale = ?", locale)
+	}
+
+	err := query.Order("name ASC, locale ASC, version DESC").Find(&prompts).Error
+	return prompts, err
+}
+
+func (r *SystemPromptRepository) FindByID(id uint) (*models.SystemPrompt, error) {
+	var prompt models.SystemPrompt
+	err := r.db.First(&prompt, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &prompt, nil
+}
+
+// FindActive returns all active versions for a prompt name and locale
+func (r *SystemPromptRepository) FindActive(name, locale string) ([]models.SystemPrompt, error) {
+	var prompts []models.SystemPrompt
+	err := r.db.Where("name = ? AND locale = ? AND is_active = ?", name, locale, true).
+		Order("version ASC").
+		Find(&prompts).Error
+	return prompts, err
+}
+
+// Create stores the prompt as the next version for its name and locale
+func (r *SystemPromptRepository) Create(prompt *models.SystemPrompt) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var maxVersion int
+		if err := tx.Model(&models.SystemPrompt{}).
+			Where("name = ? AND locale = ?", prompt.Name, prompt.Locale).
+			Select("COALESCE(MAX(version), 0)").
+			Scan(&maxVersion).Error; err != nil {
+			return err
+		}
+
+		prompt.Version = maxVersion + 1
+		return tx.Create(prompt).Error
+	})
+}
+
+func (r *SystemPromptRepository) Update(prompt *models.SystemPrompt) error {
+	return r.db.Save(prompt).Error
+}
+
+// Activate marks a version active. When exclusive, every other version of the
+// same name and locale is deactivated, which is how a rollback is done.
+func (r *SystemPromptRepository) Activate(prompt *models.SystemPrompt, exclusive bool) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if exclusive {
+			if err := tx.Model(&models.SystemPrompt{}).
+				Where("name = ? AND locale = ? AND id != ?", prompt.Name, prompt.Locale, prompt.ID).
+				Update("is_active", false).Error; err != nil {
+				return err
+			}
+		}
+
+		prompt.IsActive = true
+		return tx.Save(prompt).Error
+	})
+}
+
+func (r *SystemPromptRepository) Delete(id uint) error {
+	return r.db.Delete(&models.SystemPrompt{}, id).Error
+}
+
+// CountUsage returns how many chat messages were produced with a prompt version
+func (r *SystemPromptRepository) CountUsage(id uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.ChatMessage{}).Where("system_prompt_id = ?", id).Count(&count).Error
+	return count, err
+}
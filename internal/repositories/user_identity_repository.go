@@ -0,0 +1,67 @@
+package repositories
+
+import (
+	"time"
+
+	"github.com/Alfian57/ruang-tenang-api/internal/models"
+	"gorm.io/gorm"
+)
+
+type UserIdentityRepository struct {
+	db *gorm.DB
+}
+
+func NewUserIdentityRepository(db *gorm.DB) *UserIdentityRepository {
+	return &UserIdentityRepository{db: db}
+}
+
+func (r *UserIdentityRepository) Create(identity *models.UserIdentity) error {
+	return r.db.Omit("User").Create(identity).Error
+}
+
+// CreateWithUser creates a new account together with its first identity
+func (r *UserIdentityRepository) CreateWithUser(user *models.User, identity *models.UserIdentity) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		identity.UserID = user.ID
+		return tx.Omit("User").Create(identity).Error
+	})
+}
+
+// FindByProviderSubject finds the identity, with its user, that a provider
+// account is linked to
+func (r *UserIdentityRepository) FindByProviderSubject(provider, subject string) (*models.UserIdentity, error) {
+	var identity models.UserIdentity
+	err := r.db.Preload("User").Where("provider = ? AND subject = ?", provider, subject).First(&identity).Error
+	if err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+func (r *UserIdentityRepository) FindByUserID(userID uint) ([]models.UserIdentity, error) {
+	var identities []models.UserIdentity
+	err := r.db.Where("user_id = ?", userID).Order("created_at ASC").Find(&identities).Error
+	return identities, err
+}
+
+func (r *UserIdentityRepository) CountByUserID(userID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.UserIdentity{}).Where("user_id = ?", userID).Count(&count).Error
+	return count, err
+}
+
+func (r *UserIdentityRepository) TouchLogin(id uint, email string) error {
+	return r.db.Model(&models.UserIdentity{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"email":         email,
+		"last_login_at": time.Now(),
+	}).Error
+}
+
+// Delete unlinks a provider from the user and reports whether it was linked
+func (r *UserIdentityRepository) Delete(userID uint, provider string) (bool, error) {
+	result := r.db.Where("user_id = ? AND provider = ?", userID, provider).Delete(&models.UserIdentity{})
+	return result.RowsAffected > 0, result.Error
+}
@@ -37,6 +37,66 @@ func (r *UserRepository) FindByEmail(email string) (*models.User, error) {
 	return &user, nil
 }
 
+// FindAnonymousByPseudonym finds the anonymous account that signs in with
+// the pseudonym, ignoring case
+func (r *UserRepository) FindAnonymousByPseudonym(pseudonym string) (*models.User, error) {
+	var user models.User
+	err := r.db.Where("is_anonymous = ? AND LOWER(pseudonym) = LOWER(?)", true, pseudonym).First(&user).Error
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// FindAuthState loads just the fields AuthMiddleware and access policies need
+func (r *UserRepository) FindAuthState(id uint) (*models.User, error) {
+	var user models.User
+	err := r.db.Select("id", "role", "is_blocked", "is_anonymous", "token_version", "email_verified_at", "two_factor_enabled_at").First(&user, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// SetTwoFactorSecret stores a new, not yet enabled, TOTP secret
+func (r *UserRepository) SetTwoFactorSecret(id uint, encryptedSecret string) error {
+	return r.db.Model(&models.User{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"two_factor_secret":     encryptedSecret,
+		"two_factor_enabled_at": nil,
+		"two_factor_last_step":  0,
+	}).Error
+}
+
+func (r *UserRepository) EnableTwoFactor(id uint, step int64) error {
+	return r.db.Model(&models.User{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"two_factor_enabled_at": time.Now(),
+		"two_factor_last_step":  step,
+	}).Error
+}
+
+func (r *UserRepository) DisableTwoFactor(id uint) error {
+	return r.db.Model(&models.User{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"two_factor_secret":     nil,
+		"two_factor_enabled_at": nil,
+		"two_factor_last_step":  0,
+	}).Error
+}
+
+// UseTwoFactorStep records an accepted TOTP step, failing if it (or a later
+// one) was already used so each code works only once
+func (r *UserRepository) UseTwoFactorStep(id uint, step int64) (bool, error) {
+	result := r.db.Model(&models.User{}).
+		Where("id = ? AND two_factor_last_step < ?", id, step).
+		UpdateColumn("two_factor_last_step", step)
+	return result.RowsAffected == 1, result.Error
+}
+
+// IncrementTokenVersion invalidates every token issued to the user so far
+func (r *UserRepository) IncrementTokenVersion(id uint) error {
+	return r.db.Model(&models.User{}).Where("id = ?", id).
+		UpdateColumn("token_version", gorm.Expr("token_version + 1")).Error
+}
+
 func (r *UserRepository) Update(user *models.User) error {
 	return r.db.Save(user).Error
 }
@@ -57,6 +117,35 @@ func (r *UserRepository) ExistsByEmailExcept(email string, exceptID uint) bool {
 	return count > 0
 }
 
+// ExistsByPseudonym reports whether another user already has the
+// pseudonym, ignoring case. Soft-deleted users keep theirs.
+func (r *UserRepository) ExistsByPseudonym(pseudonym string, exceptID uint) bool {
+	var count int64
+	r.db.Unscoped().Model(&models.User{}).Where("LOWER(pseudonym) = LOWER(?) AND id != ?", pseudonym, exceptID).Count(&count)
+	return count > 0
+}
+
+// UpdatePseudonym sets the pseudonym, or clears it when nil
+func (r *UserRepository) UpdatePseudonym(id uint, pseudonym *string) error {
+	return r.db.Model(&models.User{}).Where("id = ?", id).Update("pseudonym", pseudonym).Error
+}
+
+// UpdateDeletionSchedule schedules the account for erasure, or cancels it when nil
+func (r *UserRepository) UpdateDeletionSchedule(id uint, at *time.Time) error {
+	return r.db.Model(&models.User{}).Where("id = ?", id).Update("deletion_scheduled_at", at).Error
+}
+
+func (r *UserRepository) UpdateTimezone(id uint, timezone string) error {
+	return r.db.Model(&models.User{}).Where("id = ?", id).Update("timezone", timezone).Error
+}
+
+// FindTimezone returns the user's timezone name
+func (r *UserRepository) FindTimezone(id uint) (string, error) {
+	var user models.User
+	err := r.db.Select("id", "timezone").First(&user, id).Error
+	return user.Timezone, err
+}
+
 func (r *UserRepository) GetTopUsers(limit int) ([]models.User, error) {
 	var users []models.User
 	err := r.db.Order("exp desc").Limit(limit).Find(&users).Error
@@ -65,24 +154,40 @@ func (r *UserRepository) GetTopUsers(limit int) ([]models.User, error) {
 	}
 	return users, nil
 }
-func (r *UserRepository) FindByResetToken(token string) (*models.User, error) {
+
+// FindByResetToken finds the user holding an unexpired reset token hash
+func (r *UserRepository) FindByResetToken(tokenHash string) (*models.User, error) {
 	var user models.User
-	err := r.db.Where("reset_token = ? AND reset_token_expiry > ?", token, time.Now()).First(&user).Error
+	err := r.db.Where("reset_token = ? AND reset_token_expiry > ?", tokenHash, time.Now()).First(&user).Error
 	if err != nil {
 		return nil, err
 	}
 	return &user, nil
 }
 
-func (r *UserRepository) UpdateResetToken(email string, token string, expiry time.Time) error {
+func (r *UserRepository) UpdateResetToken(email string, tokenHash string, expiry time.Time) error {
 	return r.db.Model(&models.User{}).Where("email = ?", email).Updates(map[string]interface{}{
-		"reset_token":        token,
+		"reset_token":        tokenHash,
 		"reset_token_expiry": expiry,
 	}).Error
 }
 
-func (r *UserRepository) ClearResetToken(userID uint) error {
+// ConsumeResetToken clears the reset token only if the user still holds the
+// given one, so a token can be redeemed once even by concurrent requests
+func (r *UserRepository) ConsumeResetToken(userID uint, tokenHash string) (bool, error) {
+	result := r.db.Model(&models.User{}).
+		Where("id = ? AND reset_token = ?", userID, tokenHash).
+		Updates(map[string]interface{}{
+			"reset_token":        nil,
+			"reset_token_expiry": nil,
+		})
+	return result.RowsAffected == 1, result.Error
+}
+
+// UpdatePassword sets a new password hash and drops any outstanding reset token
+func (r *UserRepository) UpdatePassword(userID uint, passwordHash string) error {
 	return r.db.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+		"password":           passwordHash,
 		"reset_token":        nil,
 		"reset_token_expiry": nil,
 	}).Error
@@ -1,15 +1,30 @@
 package router
 
 import (
+	"context"
+	"fmt"
+	"time"
+
 	"github.com/Alfian57/ruang-tenang-api/internal/config"
 	"github.com/Alfian57/ruang-tenang-api/internal/database"
 	"github.com/Alfian57/ruang-tenang-api/internal/handlers"
+	"github.com/Alfian57/ruang-tenang-api/internal/jobs"
 	"github.com/Alfian57/ruang-tenang-api/internal/middleware"
 	"github.com/Alfian57/ruang-tenang-api/internal/repositories"
 	"github.com/Alfian57/ruang-tenang-api/internal/services"
+	"github.com/Alfian57/ruang-tenang-api/pkg/llm"
+	"github.com/Alfian57/ruang-tenang-api/pkg/lockout"
+	"github.com/Alfian57/ruang-tenang-api/pkg/logger"
+	"github.com/Alfian57/ruang-tenang-api/pkg/mailer"
+	"github.com/Alfian57/ruang-tenang-api/pkg/oidc"
+	"github.com/Alfian57/ruang-tenang-api/pkg/safety"
+	"github.com/Alfian57/ruang-tenang-api/pkg/scheduler"
+	"github.com/Alfian57/ruang-tenang-api/pkg/stt"
+	"github.com/Alfian57/ruang-tenang-api/pkg/webpush"
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
+	"go.uber.org/zap"
 )
 
 func SetupRouter(cfg *config.Config) *gin.Engine {
@@ -39,34 +54,153 @@ func SetupRouter(cfg *config.Config) *gin.Engine {
 	forumCategoryRepo := repositories.NewForumCategoryRepository(db)
 	levelConfigRepo := repositories.NewLevelConfigRepository(db)
 	expHistoryRepo := repositories.NewExpHistoryRepository(db)
+	crisisFlagRepo := repositories.NewCrisisFlagRepository(db)
+	systemPromptRepo := repositories.NewSystemPromptRepository(db)
+	chatFeedbackRepo := repositories.NewChatFeedbackRepository(db)
+	authSessionRepo := repositories.NewAuthSessionRepository(db)
+	emailOutboxRepo := repositories.NewEmailOutboxRepository(db)
+	emailVerificationRepo := repositories.NewEmailVerificationRepository(db)
+	userIdentityRepo := repositories.NewUserIdentityRepository(db)
+	oauthStateRepo := repositories.NewOAuthStateRepository(db)
+	recoveryCodeRepo := repositories.NewRecoveryCodeRepository(db)
+	loginLockoutRepo := repositories.NewLoginLockoutRepository(db)
+	accountDataRepo := repositories.NewAccountDataRepository(db)
+	contentActivityRepo := repositories.NewContentActivityRepository(db)
+	notificationRepo := repositories.NewNotificationRepository(db)
+	pushSubscriptionRepo := repositories.NewPushSubscriptionRepository(db)
+	reminderPreferenceRepo := repositories.NewReminderPreferenceRepository(db)
+
+	// AI provider
+	llmProvider, err := llm.NewProvider(llm.Options{
+		Provider: cfg.LLMProvider,
+		BaseURL:  cfg.OpenAIBaseURL,
+		APIKey:   aiAPIKey(cfg, cfg.LLMProvider),
+		Model:    cfg.LLMModel,
+		Timeout:  cfg.LLMTimeout,
+	})
+	if err != nil {
+		logger.Fatal(fmt.Sprintf("Failed to initialize LLM provider: %v", err))
+	}
+	logger.Info(fmt.Sprintf("Using LLM provider %s (%s)", llmProvider.Name(), llmProvider.Model()))
+
+	transcriber, err := stt.NewTranscriber(stt.Options{
+		Provider: cfg.STTProvider,
+		BaseURL:  cfg.OpenAIBaseURL,
+		APIKey:   aiAPIKey(cfg, cfg.STTProvider),
+		Model:    cfg.STTModel,
+		Timeout:  cfg.STTTimeout,
+	})
+	if err != nil {
+		logger.Fatal(fmt.Sprintf("Failed to initialize speech-to-text provider: %v", err))
+	}
+	logger.Info(fmt.Sprintf("Using speech-to-text provider %s", transcriber.Name()))
+
+	mail, err := mailer.NewMailer(cfg)
+	if err != nil {
+		logger.Fatal(fmt.Sprintf("Failed to initialize mailer: %v", err))
+	}
+	logger.Info(fmt.Sprintf("Using mail driver %s", mail.Name()))
+
+	// Web Push is enabled with a VAPID key pair
+	var pushClient *webpush.Client
+	if cfg.VAPIDPublicKey != "" || cfg.VAPIDPrivateKey != "" {
+		pushClient, err = webpush.New(cfg.VAPIDPublicKey, cfg.VAPIDPrivateKey, cfg.VAPIDSubject)
+		if err != nil {
+			logger.Fatal(fmt.Sprintf("Failed to initialize web push: %v", err))
+		}
+	}
+
+	// Social login providers
+	oidcProviders := oidc.NewRegistry(cfg)
+
+	// Failed sign-in counters, per instance or shared through the database
+	var loginAttempts lockout.Store
+	switch cfg.LoginThrottleStore {
+	case "memory", "":
+		loginAttempts = lockout.NewMemoryStore()
+	case "database":
+		loginAttempts = repositories.NewLoginAttemptRepository(db)
+	default:
+		logger.Fatal(fmt.Sprintf("Unknown login throttle store %q", cfg.LoginThrottleStore))
+	}
+
+	// Crisis screening: lexicon always, model classifier when enabled
+	classifiers := []safety.Classifier{safety.NewLexiconClassifier()}
+	if cfg.CrisisModelClassifier {
+		classifiers = append(classifiers, safety.NewModelClassifier(llmProvider))
+	}
+	crisisClassifier := safety.NewMultiClassifier(func(err error) {
+		logger.Warn("Crisis classifier failed", zap.Error(err))
+	}, classifiers...)
 
 	// Services
 	gamificationService := services.NewGamificationService(db)
-	authService := services.NewAuthService(userRepo)
+	emailService := services.NewEmailService(emailOutboxRepo, mail)
+	authService := services.NewAuthService(userRepo, authSessionRepo, emailVerificationRepo, recoveryCodeRepo, loginLockoutRepo, loginAttempts, emailService, cfg)
+	socialAuthService := services.NewSocialAuthService(authService, userRepo, userIdentityRepo, oauthStateRepo, oidcProviders)
 	userService := services.NewUserService(userRepo)
-	articleService := services.NewArticleService(articleRepo, articleCategoryRepo, gamificationService)
-	chatService := services.NewChatService(chatSessionRepo, chatMessageRepo, cfg, gamificationService)
-	songService := services.NewSongService(songRepo, songCategoryRepo)
-	moodService := services.NewMoodService(moodRepo)
+	articleService := services.NewArticleService(articleRepo, articleCategoryRepo, gamificationService, contentActivityRepo)
+	crisisService := services.NewCrisisService(crisisFlagRepo, crisisClassifier)
+	promptService := services.NewPromptService(systemPromptRepo)
+	feedbackService := services.NewFeedbackService(chatFeedbackRepo, systemPromptRepo)
+	chatService := services.NewChatService(chatSessionRepo, chatMessageRepo, userRepo, cfg, llmProvider, transcriber, promptService, crisisService, feedbackService, gamificationService)
+	songService := services.NewSongService(songRepo, songCategoryRepo, contentActivityRepo)
+	moodService := services.NewMoodService(moodRepo, expHistoryRepo, contentActivityRepo, userRepo)
 	forumService := services.NewForumService(forumRepo, gamificationService)
 	forumCategoryService := services.NewForumCategoryService(forumCategoryRepo)
 	levelConfigService := services.NewLevelConfigService(levelConfigRepo)
 	expHistoryService := services.NewExpHistoryService(expHistoryRepo)
+	accountService := services.NewAccountService(userRepo, accountDataRepo, userIdentityRepo, chatService, authService, emailService, cfg)
+
+	notificationChannels := []services.NotificationChannel{
+		services.NewInAppChannel(),
+		services.NewEmailChannel(emailService, userRepo),
+	}
+	if pushClient != nil {
+		notificationChannels = append(notificationChannels, services.NewWebPushChannel(pushClient, pushSubscriptionRepo))
+	}
+	notificationService := services.NewNotificationService(notificationRepo, pushSubscriptionRepo, pushClient, notificationChannels...)
+	reminderService := services.NewReminderService(reminderPreferenceRepo, moodRepo, userRepo, notificationService, cfg)
+
+	// Background jobs
+	jobScheduler := scheduler.New()
+	jobScheduler.Register(jobs.ChatTrashPurge(chatService, cfg.ChatTrashPurgeInterval))
+	jobScheduler.Register(jobs.RevocationSync(authService, 30*time.Second))
+	jobScheduler.Register(jobs.AuthSessionCleanup(authService, 24*time.Hour))
+	jobScheduler.Register(jobs.LoginAttemptCleanup(authService, time.Hour))
+	jobScheduler.Register(jobs.OAuthStateCleanup(socialAuthService, time.Hour))
+	jobScheduler.Register(jobs.EmailDelivery(emailService, cfg.EmailQueueInterval))
+	jobScheduler.Register(jobs.AccountDeletion(accountService, time.Hour))
+	jobScheduler.Register(jobs.NotificationDelivery(notificationService, cfg.NotificationQueueInterval))
+	jobScheduler.Register(jobs.MoodReminders(reminderService, cfg.ReminderInterval))
+	jobScheduler.Start(context.Background())
+
+	// Reject access tokens of signed-out sessions
+	middleware.SetTokenValidator(authService.ValidateAccessToken)
+
+	// Community posting is limited to verified email addresses
+	requireVerifiedEmail := middleware.RequirePolicy(authService.RequireVerifiedEmail)
 
 	// Handlers
-	authHandler := handlers.NewAuthHandler(authService, levelConfigService)
+	authHandler := handlers.NewAuthHandler(authService, levelConfigService, reminderService)
+	socialAuthHandler := handlers.NewSocialAuthHandler(socialAuthService, levelConfigService)
+	accountHandler := handlers.NewAccountHandler(accountService)
 	userHandler := handlers.NewUserHandler(userService, levelConfigService)
 	articleHandler := handlers.NewArticleHandler(articleService)
 	chatHandler := handlers.NewChatHandler(chatService)
 	uploadHandler := handlers.NewUploadHandler()
 	songHandler := handlers.NewSongHandler(songService)
 	moodHandler := handlers.NewMoodHandler(moodService)
-	adminHandler := handlers.NewAdminHandler(db, userRepo, articleRepo)
+	adminHandler := handlers.NewAdminHandler(db, userRepo, articleRepo, authService)
 	searchHandler := handlers.NewSearchHandler(articleRepo, songRepo)
 	forumHandler := handlers.NewForumHandler(forumService)
 	forumCategoryHandler := handlers.NewForumCategoryHandler(forumCategoryService)
 	levelConfigHandler := handlers.NewLevelConfigHandler(levelConfigService)
 	expHistoryHandler := handlers.NewExpHistoryHandler(expHistoryService, levelConfigService)
+	crisisHandler := handlers.NewCrisisHandler(crisisService)
+	promptHandler := handlers.NewPromptHandler(promptService)
+	feedbackHandler := handlers.NewFeedbackHandler(feedbackService)
+	notificationHandler := handlers.NewNotificationHandler(notificationService, reminderService)
 
 	// Swagger
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
@@ -86,9 +220,18 @@ func SetupRouter(cfg *config.Config) *gin.Engine {
 		auth := v1.Group("/auth")
 		{
 			auth.POST("/register", authHandler.Register)
+			auth.POST("/register/anonymous", authHandler.RegisterAnonymous)
 			auth.POST("/login", authHandler.Login)
+			auth.POST("/login/2fa", authHandler.VerifyTwoFactorLogin)
 			auth.POST("/forgot-password", authHandler.ForgotPassword)
 			auth.POST("/reset-password", authHandler.ResetPassword)
+			auth.POST("/refresh", authHandler.Refresh)
+			auth.POST("/verify-email", authHandler.VerifyEmail)
+
+			// Social login (OpenID Connect)
+			auth.GET("/oidc/providers", socialAuthHandler.GetProviders)
+			auth.GET("/oidc/:provider/authorize", socialAuthHandler.Authorize)
+			auth.POST("/oidc/:provider/callback", socialAuthHandler.Callback)
 		}
 
 		// Protected auth routes
@@ -98,6 +241,33 @@ func SetupRouter(cfg *config.Config) *gin.Engine {
 			authProtected.GET("/me", authHandler.GetProfile)
 			authProtected.PUT("/profile", authHandler.UpdateProfile)
 			authProtected.PUT("/password", authHandler.UpdatePassword)
+			authProtected.PUT("/pseudonym", authHandler.UpdatePseudonym)
+			authProtected.PUT("/timezone", authHandler.UpdateTimezone)
+			authProtected.POST("/upgrade", authHandler.UpgradeAccount)
+			authProtected.POST("/verify-email/resend", authHandler.ResendVerification)
+			authProtected.POST("/logout", authHandler.Logout)
+			authProtected.GET("/sessions", authHandler.GetSessions)
+			authProtected.DELETE("/sessions", authHandler.RevokeOtherSessions)
+			authProtected.DELETE("/sessions/:id", authHandler.RevokeSession)
+			authProtected.GET("/2fa", authHandler.GetTwoFactorStatus)
+			authProtected.POST("/2fa/setup", authHandler.SetupTwoFactor)
+			authProtected.POST("/2fa/enable", authHandler.EnableTwoFactor)
+			authProtected.POST("/2fa/disable", authHandler.DisableTwoFactor)
+			authProtected.POST("/2fa/recovery-codes", authHandler.RegenerateRecoveryCodes)
+			authProtected.GET("/identities", socialAuthHandler.GetSignInMethods)
+			authProtected.GET("/identities/:provider/authorize", socialAuthHandler.AuthorizeLink)
+			authProtected.POST("/identities/:provider", socialAuthHandler.LinkIdentity)
+			authProtected.DELETE("/identities/:provider", socialAuthHandler.UnlinkIdentity)
+		}
+
+		// Personal data export and account deletion (protected)
+		account := v1.Group("/account")
+		account.Use(middleware.AuthMiddleware())
+		{
+			account.GET("/export", accountHandler.ExportData)
+			account.GET("/deletion", accountHandler.GetDeletionStatus)
+			account.POST("/deletion", accountHandler.RequestDeletion)
+			account.DELETE("/deletion", accountHandler.CancelDeletion)
 		}
 
 		// Upload routes (protected)
@@ -113,6 +283,7 @@ func SetupRouter(cfg *config.Config) *gin.Engine {
 		{
 			articles.GET("", articleHandler.GetArticles)
 			articles.GET("/:id", articleHandler.GetArticle)
+			articles.POST("/:id/reads", middleware.AuthMiddleware(), articleHandler.RecordRead)
 		}
 		v1.GET("/article-categories", articleHandler.GetCategories)
 
@@ -121,9 +292,9 @@ func SetupRouter(cfg *config.Config) *gin.Engine {
 		myArticles.Use(middleware.AuthMiddleware())
 		{
 			myArticles.GET("", articleHandler.GetMyArticles)
-			myArticles.POST("", articleHandler.CreateMyArticle)
+			myArticles.POST("", requireVerifiedEmail, articleHandler.CreateMyArticle)
 			myArticles.GET("/:id", articleHandler.GetArticleByIDForUser)
-			myArticles.PUT("/:id", articleHandler.UpdateMyArticle)
+			myArticles.PUT("/:id", requireVerifiedEmail, articleHandler.UpdateMyArticle)
 			myArticles.DELETE("/:id", articleHandler.DeleteMyArticle)
 		}
 
@@ -131,6 +302,7 @@ func SetupRouter(cfg *config.Config) *gin.Engine {
 		v1.GET("/song-categories", songHandler.GetCategories)
 		v1.GET("/song-categories/:id/songs", songHandler.GetSongsByCategory)
 		v1.GET("/songs/:id", songHandler.GetSong)
+		v1.POST("/songs/:id/plays", middleware.AuthMiddleware(), songHandler.RecordPlay)
 
 		// Chat (protected)
 		chat := v1.Group("/chat-sessions")
@@ -140,7 +312,12 @@ func SetupRouter(cfg *config.Config) *gin.Engine {
 			chat.POST("", chatHandler.CreateSession)
 			chat.GET("/:id", chatHandler.GetSession) // Changed from GetSession to GetSessionByID
 			chat.POST("/:id/messages", chatHandler.SendMessage)
+			chat.POST("/:id/messages/stream", chatHandler.StreamMessage)
+			chat.POST("/:id/title", chatHandler.RegenerateTitle)
+			chat.GET("/:id/export", chatHandler.ExportSession)
 			chat.PUT("/:id/trash", chatHandler.ToggleTrash)
+			chat.PUT("/:id/restore", chatHandler.RestoreSession)
+			chat.DELETE("/trash", chatHandler.EmptyTrash)
 			chat.PUT("/:id/favorite", chatHandler.ToggleFavorite)
 			chat.DELETE("/:id", chatHandler.DeleteSession)
 		}
@@ -161,6 +338,27 @@ func SetupRouter(cfg *config.Config) *gin.Engine {
 			mood.POST("", moodHandler.RecordMood)
 			mood.GET("/latest", moodHandler.GetLatestMood)
 			mood.GET("/stats", moodHandler.GetMoodStats)
+			mood.GET("/analytics", moodHandler.GetMoodAnalytics)
+			mood.GET("/insights", moodHandler.GetMoodInsights)
+			mood.GET("/tags", moodHandler.GetMoodTags)
+			mood.GET("/reminders", notificationHandler.GetReminderPreference)
+			mood.PUT("/reminders", notificationHandler.UpdateReminderPreference)
+			mood.GET("/:id", moodHandler.GetMood)
+			mood.PUT("/:id", moodHandler.UpdateMood)
+			mood.DELETE("/:id", moodHandler.DeleteMood)
+		}
+
+		// Notifications (protected)
+		notifications := v1.Group("/notifications")
+		notifications.Use(middleware.AuthMiddleware())
+		{
+			notifications.GET("", notificationHandler.GetNotifications)
+			notifications.GET("/unread-count", notificationHandler.GetUnreadCount)
+			notifications.PUT("/read-all", notificationHandler.MarkAllRead)
+			notifications.PUT("/:id/read", notificationHandler.MarkRead)
+			notifications.GET("/push", notificationHandler.GetPushConfig)
+			notifications.POST("/push/subscriptions", notificationHandler.SubscribePush)
+			notifications.DELETE("/push/subscriptions", notificationHandler.UnsubscribePush)
 		}
 
 		// Level configs (public)
@@ -178,6 +376,7 @@ func SetupRouter(cfg *config.Config) *gin.Engine {
 		admin := v1.Group("/admin")
 		admin.Use(middleware.AuthMiddleware())
 		admin.Use(middleware.AdminMiddleware())
+		admin.Use(middleware.RequirePolicy(authService.RequireAdminTwoFactor))
 		{
 			admin.GET("/stats", adminHandler.GetDashboardStats)
 
@@ -186,6 +385,8 @@ func SetupRouter(cfg *config.Config) *gin.Engine {
 			admin.DELETE("/users/:id", adminHandler.DeleteUser)
 			admin.PUT("/users/:id/block", adminHandler.BlockUser)
 			admin.PUT("/users/:id/unblock", adminHandler.UnblockUser)
+			admin.PUT("/users/:id/unlock", adminHandler.UnlockUser)
+			admin.GET("/login-lockouts", adminHandler.GetLoginLockouts)
 
 			// Article management
 			admin.GET("/articles", adminHandler.GetAllArticles)
@@ -223,6 +424,24 @@ func SetupRouter(cfg *config.Config) *gin.Engine {
 			admin.POST("/level-configs", levelConfigHandler.CreateConfig)
 			admin.PUT("/level-configs/:id", levelConfigHandler.UpdateConfig)
 			admin.DELETE("/level-configs/:id", levelConfigHandler.DeleteConfig)
+
+			// Crisis flag review
+			admin.GET("/crisis-flags", crisisHandler.GetFlags)
+			admin.GET("/crisis-flags/:id", crisisHandler.GetFlag)
+			admin.PUT("/crisis-flags/:id/review", crisisHandler.ReviewFlag)
+
+			// System prompts management
+			admin.GET("/system-prompts", promptHandler.GetPrompts)
+			admin.POST("/system-prompts", promptHandler.CreatePrompt)
+			admin.GET("/system-prompts/:id", promptHandler.GetPrompt)
+			admin.PUT("/system-prompts/:id", promptHandler.UpdatePrompt)
+			admin.DELETE("/system-prompts/:id", promptHandler.DeletePrompt)
+			admin.PUT("/system-prompts/:id/activate", promptHandler.ActivatePrompt)
+			admin.PUT("/system-prompts/:id/deactivate", promptHandler.DeactivatePrompt)
+
+			// Chat feedback analytics
+			admin.GET("/chat-feedback", feedbackHandler.GetFeedback)
+			admin.GET("/chat-feedback/analytics", feedbackHandler.GetAnalytics)
 		}
 
 		// Public Forum Categories
@@ -232,11 +451,11 @@ func SetupRouter(cfg *config.Config) *gin.Engine {
 		forum := v1.Group("/forums")
 		forum.Use(middleware.AuthMiddleware())
 		{
-			forum.POST("", forumHandler.CreateForum)
+			forum.POST("", requireVerifiedEmail, forumHandler.CreateForum)
 			forum.GET("", forumHandler.GetForums)
 			forum.GET("/:id", forumHandler.GetForumByID)
 			forum.DELETE("/:id", forumHandler.DeleteForum)
-			forum.POST("/:id/posts", forumHandler.CreateForumPost)
+			forum.POST("/:id/posts", requireVerifiedEmail, forumHandler.CreateForumPost)
 			forum.GET("/:id/posts", forumHandler.GetForumPosts)
 			forum.PUT("/:id/like", forumHandler.ToggleLike)
 		}
@@ -254,3 +473,12 @@ func SetupRouter(cfg *config.Config) *gin.Engine {
 
 	return r
 }
+
+// aiAPIKey picks the API key for an AI provider; Gemini is also the default
+// when no provider is set
+func aiAPIKey(cfg *config.Config, provider string) string {
+	if provider == llm.ProviderOpenAI {
+		return cfg.OpenAIAPIKey
+	}
+	return cfg.GeminiAPIKey
+}
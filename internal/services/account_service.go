@@ -0,0 +1,420 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Alfian57/ruang-tenang-api/internal/config"
+	"github.com/Alfian57/ruang-tenang-api/internal/dto"
+	"github.com/Alfian57/ruang-tenang-api/internal/models"
+	"github.com/Alfian57/ruang-tenang-api/internal/repositories"
+	"github.com/Alfian57/ruang-tenang-api/pkg/clock"
+	"github.com/Alfian57/ruang-tenang-api/pkg/logger"
+	"github.com/Alfian57/ruang-tenang-api/pkg/ratelimit"
+	"github.com/Alfian57/ruang-tenang-api/pkg/utils"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultDeletionGracePeriod = 14 * 24 * time.Hour
+	deletionBatchSize          = 20
+
+	exportLimit = 3 // data exports per user per hour
+)
+
+const dataExportReadme = `Ruang Tenang personal data export
+
+profile.json           your account
+moods.json             mood check-ins with notes and tags
+chat_sessions.json     AI chat sessions with all messages, including trashed ones
+audio/                 voice notes sent in chats
+forum_topics.json      forum topics you started
+forum_posts.json       forum replies you wrote
+articles.json          articles you wrote
+exp_history.json       EXP you earned
+content_activity.json  songs you played and articles you read
+
+Timestamps are in UTC.
+`
+
+// AccountExport is a ZIP archive of everything stored about a user
+type AccountExport struct {
+	Filename string
+	Data     []byte
+}
+
+// AccountService lets users download their personal data and delete their
+// account. Deletion is scheduled after a grace period, then a background job
+// erases the account with all its data.
+type AccountService struct {
+	userRepo     *repositories.UserRepository
+	dataRepo     *repositories.AccountDataRepository
+	identityRepo *repositories.UserIdentityRepository
+	chatService  *ChatService
+	authService  *AuthService
+	emailService *EmailService
+
+	gracePeriod time.Duration
+	exports     *ratelimit.Limiter
+}
+
+func NewAccountService(userRepo *repositories.UserRepository, dataRepo *repositories.AccountDataRepository, identityRepo *repositories.UserIdentityRepository, chatService *ChatService, authService *AuthService, emailService *EmailService, cfg *config.Config) *AccountService {
+	gracePeriod := cfg.AccountDeletionGracePeriod
+	if gracePeriod <= 0 {
+		gracePeriod = defaultDeletionGracePeriod
+	}
+
+	return &AccountService{
+		userRepo:     userRepo,
+		dataRepo:     dataRepo,
+		identityRepo: identityRepo,
+		chatService:  chatService,
+		authService:  authService,
+		emailService: emailService,
+		gracePeriod:  gracePeriod,
+		exports:      ratelimit.New(exportLimit, time.Hour),
+	}
+}
+
+// ExportData packages the user's profile, moods, chats, forum activity,
+// articles and EXP history as JSON files in a ZIP archive
+func (s *AccountService) ExportData(userID uint) (*AccountExport, error) {
+	if ok, retryAfter := s.exports.Allow(strconv.FormatUint(uint64(userID), 10)); !ok {
+		return nil, &RateLimitError{Err: ErrTooManyExportRequests, RetryAfter: retryAfter}
+	}
+
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	files, audioURLs, err := s.collectExportFiles(user)
+	if err != nil {
+		logger.Error("Failed to collect data export", zap.Uint("user_id", userID), zap.Error(err))
+		return nil, errors.New("failed to export data")
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	if err := writeZipFile(zw, "README.txt", []byte(dataExportReadme)); err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		data, err := json.MarshalIndent(f.content, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("AccountService.ExportData: failed to encode %s: %w", f.name, err)
+		}
+		if err := writeZipFile(zw, f.name, data); err != nil {
+			return nil, err
+		}
+	}
+	for _, url := range audioURLs {
+		if err := writeZipAudio(zw, url); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("AccountService.ExportData: %w", err)
+	}
+
+	exportedAt := time.Now().In(clock.Location(user.Timezone))
+	return &AccountExport{
+		Filename: fmt.Sprintf("ruang-tenang-data-%d-%s.zip", user.ID, exportedAt.Format("20060102")),
+		Data:     buf.Bytes(),
+	}, nil
+}
+
+type exportFile struct {
+	name    string
+	content interface{}
+}
+
+// collectExportFiles loads the user's data, returning the JSON files and
+// the voice notes referenced by their chat messages
+func (s *AccountService) collectExportFiles(user *models.User) ([]exportFile, []string, error) {
+	identities, err := s.identityRepo.FindByUserID(user.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	providers := make([]string, 0, len(identities))
+	for _, identity := range identities {
+		providers = append(providers, identity.Provider)
+	}
+
+	profile := dto.DataExportProfileDTO{
+		ID:                  user.ID,
+		Name:                user.Name,
+		Email:               user.Email,
+		Pseudonym:           user.PseudonymOrEmpty(),
+		IsAnonymous:         user.IsAnonymous,
+		Role:                string(user.Role),
+		Avatar:              user.Avatar,
+		Exp:                 user.Exp,
+		EmailVerifiedAt:     user.EmailVerifiedAt,
+		TwoFactorEnabled:    user.HasTwoFactor(),
+		LinkedProviders:     providers,
+		Timezone:            user.Timezone,
+		DeletionScheduledAt: user.DeletionScheduledAt,
+		CreatedAt:           user.CreatedAt,
+		ExportedAt:          time.Now().UTC(),
+	}
+
+	moods, err := s.dataRepo.FindMoods(user.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	moodDTOs := make([]dto.DataExportMoodDTO, len(moods))
+	for i, m := range moods {
+		moodDTOs[i] = dto.DataExportMoodDTO{
+			ID:            m.ID,
+			Mood:          string(m.Mood),
+			Intensity:     m.Intensity,
+			Note:          m.Note,
+			SocialContext: m.SocialContext,
+			Activities:    m.TagNames(models.MoodTagActivity),
+			Triggers:      m.TagNames(models.MoodTagTrigger),
+			CreatedAt:     m.CreatedAt,
+			UpdatedAt:     m.UpdatedAt,
+		}
+	}
+
+	sessions, err := s.dataRepo.FindChatSessions(user.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	var audioURLs []string
+	sessionDTOs := make([]dto.DataExportChatSessionDTO, len(sessions))
+	for i, session := range sessions {
+		messages := make([]dto.ChatMessageDTO, len(session.Messages))
+		for j := range session.Messages {
+			messages[j] = *toChatMessageDTO(&session.Messages[j])
+			if url := session.Messages[j].AudioURL; url != "" {
+				audioURLs = append(audioURLs, url)
+			}
+		}
+		sessionDTOs[i] = dto.DataExportChatSessionDTO{
+			ID:         session.ID,
+			Title:      session.Title,
+			IsFavorite: session.IsFavorite,
+			IsTrash:    session.IsTrash,
+			CreatedAt:  session.CreatedAt,
+			Messages:   messages,
+		}
+	}
+
+	forums, err := s.dataRepo.FindForums(user.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	forumDTOs := make([]dto.DataExportForumDTO, len(forums))
+	for i, f := range forums {
+		forumDTOs[i] = dto.DataExportForumDTO{ID: f.ID, CategoryID: f.CategoryID, Title: f.Title, Content: f.Content, CreatedAt: f.CreatedAt}
+	}
+
+	posts, err := s.dataRepo.FindForumPosts(user.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	postDTOs := make([]dto.DataExportForumPostDTO, len(posts))
+	for i, p := range posts {
+		postDTOs[i] = dto.DataExportForumPostDTO{ID: p.ID, ForumID: p.ForumID, Content: p.Content, CreatedAt: p.CreatedAt}
+	}
+
+	articles, err := s.dataRepo.FindArticles(user.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	articleDTOs := make([]dto.DataExportArticleDTO, len(articles))
+	for i, a := range articles {
+		articleDTOs[i] = dto.DataExportArticleDTO{
+			ID:         a.ID,
+			CategoryID: a.ArticleCategoryID,
+			Title:      a.Title,
+			Thumbnail:  a.Thumbnail,
+			Content:    a.Content,
+			Status:     string(a.Status),
+			CreatedAt:  a.CreatedAt,
+			UpdatedAt:  a.UpdatedAt,
+		}
+	}
+
+	history, err := s.dataRepo.FindExpHistory(user.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	historyDTOs := make([]dto.DataExportExpHistoryDTO, len(history))
+	for i, h := range history {
+		historyDTOs[i] = dto.DataExportExpHistoryDTO{ActivityType: h.ActivityType, Points: h.Points, Description: h.Description, CreatedAt: h.CreatedAt}
+	}
+
+	activities, err := s.dataRepo.FindContentActivities(user.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	activityDTOs := make([]dto.DataExportContentActivityDTO, len(activities))
+	for i, a := range activities {
+		activityDTOs[i] = dto.DataExportContentActivityDTO{ContentType: string(a.ContentType), ContentID: a.ContentID, CategoryID: a.CategoryID, CreatedAt: a.CreatedAt}
+	}
+
+	return []exportFile{
+		{"profile.json", profile},
+		{"moods.json", moodDTOs},
+		{"chat_sessions.json", sessionDTOs},
+		{"forum_topics.json", forumDTOs},
+		{"forum_posts.json", postDTOs},
+		{"articles.json", articleDTOs},
+		{"exp_history.json", historyDTOs},
+		{"content_activity.json", activityDTOs},
+	}, audioURLs, nil
+}
+
+func writeZipFile(zw *zip.Writer, name string, data []byte) error {
+	w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: time.Now()})
+	if err != nil {
+		return fmt.Errorf("failed to add %s to export: %w", name, err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("failed to add %s to export: %w", name, err)
+	}
+	return nil
+}
+
+// writeZipAudio adds an uploaded voice note under audio/. Files that were
+// already removed from disk are skipped.
+func writeZipAudio(zw *zip.Writer, audioURL string) error {
+	if !strings.HasPrefix(audioURL, audioURLPrefix) {
+		return nil
+	}
+	name := filepath.Base(strings.TrimPrefix(audioURL, audioURLPrefix))
+	data, err := os.ReadFile(filepath.Join(audioUploadDir, name))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logger.Warn("Failed to read audio file for export", zap.String("name", name), zap.Error(err))
+		}
+		return nil
+	}
+	return writeZipFile(zw, "audio/"+name, data)
+}
+
+// GetDeletionStatus reports whether and when the account will be erased
+func (s *AccountService) GetDeletionStatus(userID uint) (*dto.AccountDeletionDTO, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	return &dto.AccountDeletionDTO{
+		Scheduled:   user.IsDeletionScheduled(),
+		ScheduledAt: user.DeletionScheduledAt,
+	}, nil
+}
+
+// RequestDeletion schedules the account to be erased after the grace period.
+// The password, and a second factor when enabled, confirm the request since
+// it eventually destroys data that can't be recovered.
+func (s *AccountService) RequestDeletion(userID uint, req *dto.DeleteAccountRequest) (*dto.AccountDeletionDTO, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	if user.IsDeletionScheduled() {
+		return nil, ErrDeletionScheduled
+	}
+
+	if user.HasPassword() && !utils.CheckPassword(req.Password, user.Password) {
+		return nil, ErrIncorrectPassword
+	}
+	if user.HasTwoFactor() {
+		if err := s.authService.verifySecondFactor(user, req.Code); err != nil {
+			return nil, err
+		}
+	}
+
+	deleteAt := time.Now().Add(s.gracePeriod)
+	if err := s.userRepo.UpdateDeletionSchedule(userID, &deleteAt); err != nil {
+		return nil, errors.New("failed to schedule account deletion")
+	}
+	logger.Info("Account deletion scheduled", zap.Uint("user_id", userID), zap.Time("delete_at", deleteAt))
+
+	if user.Email != "" {
+		err := s.emailService.Enqueue(user.Email, EmailAccountDeletion, req.Locale, map[string]interface{}{
+			"Name":     user.Name,
+			"DeleteAt": emailTime(deleteAt),
+		})
+		if err != nil {
+			logger.Error("Failed to queue account deletion email", zap.Uint("user_id", userID), zap.Error(err))
+		}
+	}
+
+	return &dto.AccountDeletionDTO{Scheduled: true, ScheduledAt: &deleteAt}, nil
+}
+
+// CancelDeletion keeps the account if its grace period hasn't ended yet
+func (s *AccountService) CancelDeletion(userID uint) error {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+	if !user.IsDeletionScheduled() {
+		return ErrDeletionNotScheduled
+	}
+
+	if err := s.userRepo.UpdateDeletionSchedule(userID, nil); err != nil {
+		return errors.New("failed to cancel account deletion")
+	}
+	logger.Info("Account deletion cancelled", zap.Uint("user_id", userID))
+	return nil
+}
+
+// PurgeDueDeletions erases accounts whose grace period has ended, returning
+// how many were erased. The user is signed out everywhere first, then their
+// chats and voice notes are purged and the remaining rows hard deleted.
+func (s *AccountService) PurgeDueDeletions(ctx context.Context) (int, error) {
+	s.exports.Prune()
+
+	erased := 0
+	for ctx.Err() == nil {
+		ids, err := s.dataRepo.FindDueForDeletion(time.Now(), deletionBatchSize)
+		if err != nil {
+			return erased, err
+		}
+		if len(ids) == 0 {
+			break
+		}
+
+		for _, id := range ids {
+			if err := s.erase(id); err != nil {
+				return erased, fmt.Errorf("failed to erase user %d: %w", id, err)
+			}
+			erased++
+		}
+
+		if len(ids) < deletionBatchSize {
+			break
+		}
+	}
+
+	return erased, ctx.Err()
+}
+
+func (s *AccountService) erase(userID uint) error {
+	if err := s.authService.InvalidateUserTokens(userID); err != nil {
+		return err
+	}
+	if err := s.chatService.PurgeUserSessions(userID); err != nil {
+		return err
+	}
+	if err := s.dataRepo.Erase(userID); err != nil {
+		return err
+	}
+	logger.Info("Account erased", zap.Uint("user_id", userID))
+	return nil
+}
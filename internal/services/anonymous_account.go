@@ -0,0 +1,153 @@
+package services
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+
+	"github.com/Alfian57/ruang-tenang-api/internal/dto"
+	"github.com/Alfian57/ruang-tenang-api/internal/models"
+	"github.com/Alfian57/ruang-tenang-api/pkg/logger"
+	"github.com/Alfian57/ruang-tenang-api/pkg/utils"
+	"go.uber.org/zap"
+)
+
+const (
+	// anonymousRegistrationLimit caps anonymous sign-ups per client IP per
+	// hour. They skip email verification, so this is what keeps spam
+	// accounts out of the community.
+	anonymousRegistrationLimit = 5
+
+	generatedPseudonymPrefix = "Teman"
+	generatedPseudonymTries  = 5
+)
+
+var pseudonymPattern = regexp.MustCompile(`^[A-Za-z0-9_.-]{3,30}$`)
+
+// RegisterAnonymous creates an account with only a pseudonym and password.
+// The pseudonym is generated when none is given.
+func (s *AuthService) RegisterAnonymous(req *dto.AnonymousRegisterRequest, clientIP string) (*models.User, error) {
+	pseudonym := strings.TrimSpace(req.Pseudonym)
+	if pseudonym != "" {
+		if err := s.checkPseudonym(pseudonym, 0); err != nil {
+			return nil, err
+		}
+	}
+
+	if ok, retryAfter := s.anonymousRegistrations.Allow(clientIP); !ok {
+		return nil, &RateLimitError{Err: ErrTooManyRegistrations, RetryAfter: retryAfter}
+	}
+
+	if pseudonym == "" {
+		generated, err := s.generatePseudonym()
+		if err != nil {
+			return nil, err
+		}
+		pseudonym = generated
+	}
+
+	hashedPassword, err := utils.HashPassword(req.Password)
+	if err != nil {
+		return nil, errors.New("failed to hash password")
+	}
+
+	user := &models.User{
+		Name:        pseudonym,
+		Password:    hashedPassword,
+		Role:        models.RoleMember,
+		IsAnonymous: true,
+		Pseudonym:   &pseudonym,
+		Timezone:    signUpTimezone(req.Timezone),
+	}
+	if err := s.userRepo.Create(user); err != nil {
+		return nil, errors.New("failed to create user")
+	}
+
+	return user, nil
+}
+
+// UpgradeAccount starts turning an anonymous account into a full one. The
+// account keeps its data and pseudonym and becomes a full account when the
+// email is verified.
+func (s *AuthService) UpgradeAccount(userID uint, req *dto.UpgradeAccountRequest) (*models.User, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	if !user.IsAnonymous {
+		return nil, ErrNotAnonymous
+	}
+	if s.userRepo.ExistsByEmail(req.Email) {
+		return nil, errors.New("email already registered")
+	}
+
+	user.Name = req.Name
+	if err := s.userRepo.Update(user); err != nil {
+		return nil, errors.New("failed to update profile")
+	}
+
+	if err := s.sendVerification(user, req.Email, req.Locale); err != nil {
+		logger.Error("Failed to queue verification email", zap.Uint("user_id", user.ID), zap.Error(err))
+		return nil, errors.New("failed to send verification email")
+	}
+
+	return user, nil
+}
+
+// UpdatePseudonym sets the name shown on forums, articles and the
+// leaderboard. Full accounts may clear it to appear by name again.
+func (s *AuthService) UpdatePseudonym(userID uint, pseudonym string) (*models.User, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	pseudonym = strings.TrimSpace(pseudonym)
+	if pseudonym == "" {
+		if user.IsAnonymous {
+			return nil, ErrPseudonymRequired
+		}
+		user.Pseudonym = nil
+	} else {
+		if err := s.checkPseudonym(pseudonym, userID); err != nil {
+			return nil, err
+		}
+		user.Pseudonym = &pseudonym
+	}
+
+	if err := s.userRepo.UpdatePseudonym(userID, user.Pseudonym); err != nil {
+		return nil, errors.New("failed to update pseudonym")
+	}
+
+	return user, nil
+}
+
+func (s *AuthService) checkPseudonym(pseudonym string, exceptID uint) error {
+	if !pseudonymPattern.MatchString(pseudonym) {
+		return ErrInvalidPseudonym
+	}
+	if s.userRepo.ExistsByPseudonym(pseudonym, exceptID) {
+		return ErrPseudonymTaken
+	}
+	return nil
+}
+
+// generatePseudonym picks an unused name like "Teman482913"
+func (s *AuthService) generatePseudonym() (string, error) {
+	max := big.NewInt(1_000_000)
+	for i := 0; i < generatedPseudonymTries; i++ {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", errors.New("failed to generate pseudonym")
+		}
+
+		pseudonym := fmt.Sprintf("%s%06d", generatedPseudonymPrefix, n.Int64())
+		if !s.userRepo.ExistsByPseudonym(pseudonym, 0) {
+			return pseudonym, nil
+		}
+	}
+	return "", errors.New("failed to generate pseudonym")
+}
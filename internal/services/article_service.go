@@ -13,14 +13,16 @@ type ArticleService struct {
 	articleRepo         *repositories.ArticleRepository
 	categoryRepo        *repositories.ArticleCategoryRepository
 	gamificationService *GamificationService
+	activityRepo        *repositories.ContentActivityRepository
 }
 
-func NewArticleService(articleRepo *repositories.ArticleRepository, categoryRepo *repositories.ArticleCategoryRepository, gamificationService *GamificationService) *ArticleService {
+func NewArticleService(articleRepo *repositories.ArticleRepository, categoryRepo *repositories.ArticleCategoryRepository, gamificationService *GamificationService, activityRepo *repositories.ContentActivityRepository) *ArticleService {
 	return &ArticleService{
 		articleRepo: articleRepo,
 
 		categoryRepo:        categoryRepo,
 		gamificationService: gamificationService,
+		activityRepo:        activityRepo,
 	}
 }
 
@@ -141,6 +143,17 @@ func (s *ArticleService) GetPublishedArticleByID(id uint) (*dto.ArticleDTO, erro
 	return article, nil
 }
 
+// RecordRead notes that the user read a published article, for mood insights
+func (s *ArticleService) RecordRead(userID, articleID uint) error {
+	article, err := s.articleRepo.FindByID(articleID)
+	if err != nil || article.Status != models.ArticleStatusPublished {
+		return errors.New("article not found")
+	}
+
+	categoryID := article.ArticleCategoryID
+	return recordContentActivity(s.activityRepo, userID, models.ContentArticle, article.ID, &categoryID)
+}
+
 func (s *ArticleService) GetCategories() ([]dto.ArticleCategoryDTO, error) {
 	categories, err := s.categoryRepo.FindAll()
 	if err != nil {
@@ -2,21 +2,107 @@ package services
 
 import (
 	"errors"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/Alfian57/ruang-tenang-api/internal/config"
 	"github.com/Alfian57/ruang-tenang-api/internal/dto"
 	"github.com/Alfian57/ruang-tenang-api/internal/models"
 	"github.com/Alfian57/ruang-tenang-api/internal/repositories"
+	"github.com/Alfian57/ruang-tenang-api/pkg/clock"
+	"github.com/Alfian57/ruang-tenang-api/pkg/lockout"
+	"github.com/Alfian57/ruang-tenang-api/pkg/logger"
+	"github.com/Alfian57/ruang-tenang-api/pkg/ratelimit"
 	"github.com/Alfian57/ruang-tenang-api/pkg/utils"
+	"go.uber.org/zap"
 )
 
+// passwordResetTTL is how long a password reset link stays valid
+const passwordResetTTL = time.Hour
+
 type AuthService struct {
-	userRepo *repositories.UserRepository
+	userRepo            *repositories.UserRepository
+	sessionRepo         *repositories.AuthSessionRepository
+	verificationRepo    *repositories.EmailVerificationRepository
+	emailService        *EmailService
+	revocations         *revocationList
+	userStates          *userStateCache
+	resetURL            string
+	verifyURL           string
+	resetByIP           *ratelimit.Limiter
+	resetByEmail        *ratelimit.Limiter
+	verificationResends *ratelimit.Limiter
+
+	recoveryCodeRepo   *repositories.RecoveryCodeRepository
+	twoFactorKey       string
+	twoFactorForAdmins bool
+	twoFactorAttempts  *ratelimit.Limiter
+
+	lockoutRepo    *repositories.LoginLockoutRepository
+	loginByAccount *lockout.Guard
+	loginByIP      *lockout.Guard
+
+	anonymousRegistrations *ratelimit.Limiter
+
+	accessTTL  time.Duration
+	sessionTTL time.Duration // refresh token lifetime without "remember me"
+	refreshTTL time.Duration // refresh token lifetime with "remember me"
 }
 
-func NewAuthService(userRepo *repositories.UserRepository) *AuthService {
-	return &AuthService{userRepo: userRepo}
+func NewAuthService(userRepo *repositories.UserRepository, sessionRepo *repositories.AuthSessionRepository, verificationRepo *repositories.EmailVerificationRepository, recoveryCodeRepo *repositories.RecoveryCodeRepository, lockoutRepo *repositories.LoginLockoutRepository, loginAttempts lockout.Store, emailService *EmailService, cfg *config.Config) *AuthService {
+	accessTTL := cfg.JWTAccessTTL
+	if accessTTL <= 0 {
+		accessTTL = defaultAccessTTL
+	}
+	refreshTTL := cfg.JWTRefreshTTL
+	if refreshTTL <= 0 {
+		refreshTTL = defaultRefreshTTL
+	}
+	sessionTTL := time.Duration(cfg.JWTExpiryHours) * time.Hour
+	if sessionTTL <= 0 {
+		sessionTTL = 24 * time.Hour
+	}
+	resetURL := cfg.PasswordResetURL
+	if resetURL == "" {
+		resetURL = strings.TrimRight(cfg.ClientOrigin, "/") + "/reset-password"
+	}
+	verifyURL := cfg.EmailVerificationURL
+	if verifyURL == "" {
+		verifyURL = strings.TrimRight(cfg.ClientOrigin, "/") + "/verify-email"
+	}
+	twoFactorKey := cfg.TwoFactorEncryptionKey
+	if twoFactorKey == "" {
+		twoFactorKey = cfg.JWTSecret
+	}
+
+	loginByAccount, loginByIP := newLoginGuards(loginAttempts, cfg)
+
+	return &AuthService{
+		userRepo:            userRepo,
+		sessionRepo:         sessionRepo,
+		verificationRepo:    verificationRepo,
+		emailService:        emailService,
+		revocations:         newRevocationList(),
+		userStates:          newUserStateCache(),
+		resetURL:            resetURL,
+		verifyURL:           verifyURL,
+		resetByIP:           ratelimit.New(cfg.PasswordResetIPLimit, cfg.PasswordResetLimitWindow),
+		resetByEmail:        ratelimit.New(cfg.PasswordResetEmailLimit, cfg.PasswordResetLimitWindow),
+		verificationResends: ratelimit.New(verificationResendLimit, time.Hour),
+		recoveryCodeRepo:    recoveryCodeRepo,
+		twoFactorKey:        twoFactorKey,
+		twoFactorForAdmins:  cfg.TwoFactorRequiredForAdmins,
+		twoFactorAttempts:   ratelimit.New(twoFactorAttemptLimit, twoFactorChallengeTTL),
+		lockoutRepo:         lockoutRepo,
+		loginByAccount:      loginByAccount,
+		loginByIP:           loginByIP,
+
+		anonymousRegistrations: ratelimit.New(anonymousRegistrationLimit, time.Hour),
+		accessTTL:              accessTTL,
+		sessionTTL:             sessionTTL,
+		refreshTTL:             refreshTTL,
+	}
 }
 
 func (s *AuthService) Register(req *dto.RegisterRequest) (*models.User, error) {
@@ -36,37 +122,60 @@ func (s *AuthService) Register(req *dto.RegisterRequest) (*models.User, error) {
 		Email:    req.Email,
 		Password: hashedPassword,
 		Role:     models.RoleMember,
+		Timezone: signUpTimezone(req.Timezone),
 	}
 
 	if err := s.userRepo.Create(user); err != nil {
 		return nil, errors.New("failed to create user")
 	}
 
+	// The account works right away; community features wait for verification
+	if err := s.sendVerification(user, user.Email, req.Locale); err != nil {
+		logger.Error("Failed to queue verification email", zap.Uint("user_id", user.ID), zap.Error(err))
+	}
+
 	return user, nil
 }
 
-func (s *AuthService) Login(req *dto.LoginRequest) (*dto.LoginResponse, error) {
-	user, err := s.userRepo.FindByEmail(req.Email)
+func (s *AuthService) Login(req *dto.LoginRequest, client ClientInfo) (*dto.LoginResponse, error) {
+	// Anonymous accounts sign in with their pseudonym instead of an email
+	identifier := req.Email
+	if identifier == "" {
+		identifier = req.Pseudonym
+	}
+
+	if err := s.checkLoginThrottle(identifier, client.IPAddress); err != nil {
+		return nil, err
+	}
+
+	var user *models.User
+	var err error
+	if req.Email != "" {
+		user, err = s.userRepo.FindByEmail(req.Email)
+	} else {
+		user, err = s.userRepo.FindAnonymousByPseudonym(req.Pseudonym)
+	}
 	if err != nil {
-		return nil, errors.New("invalid email or password")
+		return nil, s.loginFailed(identifier, nil, client.IPAddress)
 	}
 
 	if !utils.CheckPassword(req.Password, user.Password) {
-		return nil, errors.New("invalid email or password")
+		return nil, s.loginFailed(identifier, user, client.IPAddress)
 	}
+	s.loginSucceeded(identifier)
 
-	tokenExpiry := time.Duration(config.AppConfig.JWTExpiryHours) * time.Hour
-	if req.RememberMe {
-		tokenExpiry = 30 * 24 * time.Hour // 30 days
+	// Checked after the password so blocked status isn't revealed to guessers
+	if user.IsBlocked {
+		return nil, ErrAccountBlocked
 	}
 
-	token, err := utils.GenerateToken(user.ID, user.Email, string(user.Role), tokenExpiry)
-	if err != nil {
-		return nil, errors.New("failed to generate token")
-	}
+	client.DeviceName = req.DeviceName
+	return s.completeLogin(user, req.RememberMe, client)
+}
 
+func loginResponse(user *models.User, tokens *dto.TokenPair) *dto.LoginResponse {
 	return &dto.LoginResponse{
-		Token: token,
+		TokenPair: *tokens,
 		User: dto.UserDTO{
 			ID:        user.ID,
 			Name:      user.Name,
@@ -75,8 +184,16 @@ func (s *AuthService) Login(req *dto.LoginRequest) (*dto.LoginResponse, error) {
 			Role:      string(user.Role),
 			Exp:       user.Exp,
 			CreatedAt: user.CreatedAt.Format("2006-01-02T15:04:05Z"),
+
+			EmailVerified:    user.IsEmailVerified(),
+			TwoFactorEnabled: user.HasTwoFactor(),
+			Pseudonym:        user.PseudonymOrEmpty(),
+			IsAnonymous:      user.IsAnonymous,
+			Timezone:         user.Timezone,
+
+			DeletionScheduledAt: user.DeletionScheduledAt,
 		},
-	}, nil
+	}
 }
 
 func (s *AuthService) GetProfile(userID uint) (*models.User, error) {
@@ -89,23 +206,68 @@ func (s *AuthService) UpdateProfile(userID uint, req *dto.UpdateProfileRequest)
 		return nil, errors.New("user not found")
 	}
 
+	// Anonymous accounts add an email through UpgradeAccount
+	if user.IsAnonymous && req.Email != "" {
+		return nil, ErrNoEmail
+	}
+	if !user.IsAnonymous && req.Email == "" {
+		return nil, errors.New("email is required")
+	}
+
 	// Check if new email is taken by another user
 	if req.Email != user.Email && s.userRepo.ExistsByEmailExcept(req.Email, userID) {
 		return nil, errors.New("email already taken")
 	}
 
 	user.Name = req.Name
-	user.Email = req.Email
 	user.Avatar = req.Avatar
 
 	if err := s.userRepo.Update(user); err != nil {
 		return nil, errors.New("failed to update profile")
 	}
 
+	// A new email only replaces the current one once it is confirmed
+	if req.Email != user.Email {
+		if err := s.sendVerification(user, req.Email, req.Locale); err != nil {
+			logger.Error("Failed to queue verification email", zap.Uint("user_id", user.ID), zap.Error(err))
+			return nil, errors.New("failed to send verification email")
+		}
+	}
+
+	return user, nil
+}
+
+// UpdateTimezone sets the timezone the user's days are counted in
+func (s *AuthService) UpdateTimezone(userID uint, timezone string) (*models.User, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	timezone = strings.TrimSpace(timezone)
+	if !clock.Valid(timezone) {
+		return nil, ErrInvalidTimezone
+	}
+
+	if err := s.userRepo.UpdateTimezone(userID, timezone); err != nil {
+		return nil, errors.New("failed to update timezone")
+	}
+	user.Timezone = timezone
+
 	return user, nil
 }
 
-func (s *AuthService) UpdatePassword(userID uint, req *dto.UpdatePasswordRequest) error {
+// signUpTimezone keeps a timezone sent at sign-up when it is known
+func signUpTimezone(timezone string) string {
+	timezone = strings.TrimSpace(timezone)
+	if clock.Valid(timezone) {
+		return timezone
+	}
+	return clock.DefaultTimezone
+}
+
+// UpdatePassword changes the password and signs out every other device
+func (s *AuthService) UpdatePassword(userID, currentSessionID uint, req *dto.UpdatePasswordRequest) error {
 	user, err := s.userRepo.FindByID(userID)
 	if err != nil {
 		return errors.New("user not found")
@@ -120,43 +282,68 @@ func (s *AuthService) UpdatePassword(userID uint, req *dto.UpdatePasswordRequest
 		return errors.New("failed to hash password")
 	}
 
-	user.Password = hashedPassword
-
-	if err := s.userRepo.Update(user); err != nil {
+	// Also invalidates any reset link the user may have requested
+	if err := s.userRepo.UpdatePassword(user.ID, hashedPassword); err != nil {
 		return errors.New("failed to update password")
 	}
 
+	if _, err := s.RevokeOtherSessions(userID, currentSessionID); err != nil {
+		return errors.New("failed to sign out other devices")
+	}
+
+	s.notifyPasswordChanged(user, req.Locale)
+
 	return nil
 }
 
-func (s *AuthService) ForgotPassword(req *dto.ForgotPasswordRequest) error {
+// ForgotPassword emails a single-use reset link. Requests are throttled per
+// client IP (rejected with ErrTooManyResetRequests) and per email address
+// (silently dropped, so the response never reveals whether an account exists).
+func (s *AuthService) ForgotPassword(req *dto.ForgotPasswordRequest, clientIP string) error {
+	if ok, retryAfter := s.resetByIP.Allow(clientIP); !ok {
+		return &RateLimitError{Err: ErrTooManyResetRequests, RetryAfter: retryAfter}
+	}
+	if ok, _ := s.resetByEmail.Allow(strings.ToLower(req.Email)); !ok {
+		logger.Warn("Password reset email throttled", zap.String("ip", clientIP))
+		return nil
+	}
+
 	user, err := s.userRepo.FindByEmail(req.Email)
 	if err != nil {
 		// Return nil to avoid email enumeration
 		return nil
 	}
 
-	// Generate reset token (simple random string)
 	token, err := utils.GenerateRandomString(32)
 	if err != nil {
 		return errors.New("failed to generate token")
 	}
 
-	expiry := time.Now().Add(1 * time.Hour)
-
-	if err := s.userRepo.UpdateResetToken(user.Email, token, expiry); err != nil {
+	// Only the hash is stored; a new request replaces the previous link
+	expiry := time.Now().Add(passwordResetTTL)
+	if err := s.userRepo.UpdateResetToken(user.Email, utils.HashToken(token), expiry); err != nil {
 		return errors.New("failed to save reset token")
 	}
 
-	// Mock email sending - Log the token
-	// In production, send email here
-	println("RESET TOKEN for " + req.Email + ": " + token)
+	err = s.emailService.Enqueue(user.Email, EmailPasswordReset, req.Locale, map[string]interface{}{
+		"Name":             user.Name,
+		"ResetURL":         s.resetURL + "?token=" + url.QueryEscape(token),
+		"ExpiresInMinutes": int(passwordResetTTL.Minutes()),
+	})
+	if err != nil {
+		logger.Error("Failed to queue password reset email", zap.Uint("user_id", user.ID), zap.Error(err))
+		return errors.New("failed to send reset email")
+	}
 
 	return nil
 }
 
+// ResetPassword redeems a reset token once, sets the new password and signs
+// the user out everywhere, including access tokens already issued
 func (s *AuthService) ResetPassword(req *dto.ResetPasswordRequest) error {
-	user, err := s.userRepo.FindByResetToken(req.Token)
+	tokenHash := utils.HashToken(req.Token)
+
+	user, err := s.userRepo.FindByResetToken(tokenHash)
 	if err != nil {
 		return errors.New("invalid or expired token")
 	}
@@ -166,16 +353,43 @@ func (s *AuthService) ResetPassword(req *dto.ResetPasswordRequest) error {
 		return errors.New("failed to hash password")
 	}
 
-	user.Password = hashedPassword
+	consumed, err := s.userRepo.ConsumeResetToken(user.ID, tokenHash)
+	if err != nil {
+		return errors.New("failed to reset password")
+	}
+	if !consumed {
+		return errors.New("invalid or expired token")
+	}
 
-	if err := s.userRepo.Update(user); err != nil {
+	if err := s.userRepo.UpdatePassword(user.ID, hashedPassword); err != nil {
 		return errors.New("failed to update password")
 	}
 
-	// Clear token
-	if err := s.userRepo.ClearResetToken(user.ID); err != nil {
-		// Log error but don't fail properly finished process
+	// Whoever had access before the reset must sign in again
+	if err := s.InvalidateUserTokens(user.ID); err != nil {
+		return errors.New("failed to sign out existing sessions")
 	}
 
+	s.resetByEmail.Reset(strings.ToLower(user.Email))
+	// Proving access to the inbox ends a lockout
+	s.loginSucceeded(user.Email)
+	s.notifyPasswordChanged(user, req.Locale)
+
 	return nil
 }
+
+// notifyPasswordChanged tells the account owner about a password change. The
+// change itself already succeeded, so a queueing failure is only logged.
+func (s *AuthService) notifyPasswordChanged(user *models.User, locale string) {
+	if user.Email == "" {
+		return
+	}
+
+	err := s.emailService.Enqueue(user.Email, EmailPasswordChanged, locale, map[string]interface{}{
+		"Name":      user.Name,
+		"ChangedAt": emailTime(time.Now()),
+	})
+	if err != nil {
+		logger.Error("Failed to queue password changed email", zap.Uint("user_id", user.ID), zap.Error(err))
+	}
+}
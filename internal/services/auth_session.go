@@ -0,0 +1,359 @@
+package services
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Alfian57/ruang-tenang-api/internal/dto"
+	"github.com/Alfian57/ruang-tenang-api/internal/models"
+	"github.com/Alfian57/ruang-tenang-api/pkg/logger"
+	"github.com/Alfian57/ruang-tenang-api/pkg/utils"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultAccessTTL  = 15 * time.Minute
+	defaultRefreshTTL = 30 * 24 * time.Hour
+	// userStateTTL is how long a user's blocked flag, token version and
+	// verification status are cached before they are reloaded
+	userStateTTL = 30 * time.Second
+	// staleSessionRetention keeps expired and revoked sessions around for a
+	// while so refresh token reuse can still be detected
+	staleSessionRetention = 7 * 24 * time.Hour
+)
+
+// ClientInfo describes the device a sign-in comes from
+type ClientInfo struct {
+	DeviceName string
+	UserAgent  string
+	IPAddress  string
+}
+
+// revocationList caches recently revoked session IDs so AuthMiddleware can
+// reject their access tokens without a database lookup. Entries only need to
+// outlive the access token TTL.
+type revocationList struct {
+	mu      sync.RWMutex
+	revoked map[uint]time.Time
+}
+
+func newRevocationList() *revocationList {
+	return &revocationList{revoked: make(map[uint]time.Time)}
+}
+
+func (l *revocationList) add(id uint, at time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.revoked[id] = at
+}
+
+func (l *revocationList) contains(id uint) bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	_, ok := l.revoked[id]
+	return ok
+}
+
+func (l *revocationList) prune(before time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for id, at := range l.revoked {
+		if at.Before(before) {
+			delete(l.revoked, id)
+		}
+	}
+}
+
+// userState is the cached part of a user that decides whether their tokens
+// are still accepted
+type userState struct {
+	blocked      bool
+	tokenVersion int
+	verified     bool // email verified, for RequireVerifiedEmail
+	admin        bool
+	twoFactor    bool // for RequireAdminTwoFactor
+	loadedAt     time.Time
+}
+
+type userStateCache struct {
+	mu     sync.RWMutex
+	states map[uint]userState
+}
+
+func newUserStateCache() *userStateCache {
+	return &userStateCache{states: make(map[uint]userState)}
+}
+
+func (c *userStateCache) get(userID uint) (userState, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	state, ok := c.states[userID]
+	if !ok || time.Since(state.loadedAt) > userStateTTL {
+		return userState{}, false
+	}
+	return state, true
+}
+
+func (c *userStateCache) set(userID uint, state userState) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.states[userID] = state
+}
+
+func (c *userStateCache) forget(userID uint) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.states, userID)
+}
+
+func (c *userStateCache) prune() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for id, state := range c.states {
+		if time.Since(state.loadedAt) > userStateTTL {
+			delete(c.states, id)
+		}
+	}
+}
+
+// ValidateAccessToken rejects access tokens whose session was revoked, whose
+// user is blocked or gone, or that predate the user's current token version.
+// Tokens issued before sessions existed carry no session ID and are refused
+// so every client ends up on a revocable session.
+func (s *AuthService) ValidateAccessToken(claims *utils.JWTClaims) error {
+	if claims.SessionID == 0 || s.revocations.contains(claims.SessionID) {
+		return ErrSessionRevoked
+	}
+
+	state, err := s.loadUserState(claims.UserID)
+	if err != nil {
+		return ErrSessionRevoked
+	}
+
+	if state.blocked {
+		return ErrAccountBlocked
+	}
+	if claims.TokenVersion != state.tokenVersion {
+		return ErrTokenRevoked
+	}
+	return nil
+}
+
+// loadUserState returns the user's cached auth state, reloading it once it
+// is older than userStateTTL
+func (s *AuthService) loadUserState(userID uint) (userState, error) {
+	if state, ok := s.userStates.get(userID); ok {
+		return state, nil
+	}
+
+	user, err := s.userRepo.FindAuthState(userID)
+	if err != nil {
+		return userState{}, err
+	}
+
+	state := userState{
+		blocked:      user.IsBlocked,
+		tokenVersion: user.TokenVersion,
+		// Anonymous accounts have no email to verify; their sign-ups are
+		// rate limited instead
+		verified:  user.IsEmailVerified() || user.IsAnonymous,
+		admin:     user.IsAdmin(),
+		twoFactor: user.HasTwoFactor(),
+		loadedAt:  time.Now(),
+	}
+	s.userStates.set(userID, state)
+	return state, nil
+}
+
+// InvalidateUserTokens makes every access and refresh token of the user
+// unusable, e.g. when an admin blocks the account
+func (s *AuthService) InvalidateUserTokens(userID uint) error {
+	if err := s.userRepo.IncrementTokenVersion(userID); err != nil {
+		return err
+	}
+	s.userStates.forget(userID)
+
+	_, err := s.RevokeOtherSessions(userID, 0)
+	return err
+}
+
+// SyncRevocations loads sessions revoked on any instance within the access
+// token lifetime into the local revocation list
+func (s *AuthService) SyncRevocations() error {
+	since := time.Now().Add(-s.accessTTL - time.Minute)
+	sessions, err := s.sessionRepo.FindRevokedSince(since)
+	if err != nil {
+		return err
+	}
+
+	for _, session := range sessions {
+		s.revocations.add(session.ID, *session.RevokedAt)
+	}
+	s.revocations.prune(since)
+	s.userStates.prune()
+	s.resetByIP.Prune()
+	s.resetByEmail.Prune()
+	s.verificationResends.Prune()
+	s.twoFactorAttempts.Prune()
+	s.anonymousRegistrations.Prune()
+	return nil
+}
+
+// PurgeStaleSessions deletes sessions that expired or were revoked long ago
+func (s *AuthService) PurgeStaleSessions() (int64, error) {
+	return s.sessionRepo.DeleteStale(time.Now().Add(-staleSessionRetention))
+}
+
+// startSession creates a session for the device and issues its first token pair
+func (s *AuthService) startSession(user *models.User, rememberMe bool, client ClientInfo) (*dto.TokenPair, error) {
+	refreshTTL := s.sessionTTL
+	if rememberMe {
+		refreshTTL = s.refreshTTL
+	}
+
+	refreshToken, err := utils.GenerateRandomString(32)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	session := &models.AuthSession{
+		UserID:           user.ID,
+		RefreshTokenHash: utils.HashToken(refreshToken),
+		DeviceName:       client.DeviceName,
+		UserAgent:        truncate(client.UserAgent, 255),
+		IPAddress:        client.IPAddress,
+		ExpiresAt:        now.Add(refreshTTL),
+		LastUsedAt:       now,
+	}
+	if err := s.sessionRepo.Create(session); err != nil {
+		return nil, err
+	}
+
+	return s.issueTokens(user, session, refreshToken)
+}
+
+// Refresh exchanges a refresh token for a new token pair. The refresh token
+// rotates on every use; presenting an already rotated token means it leaked,
+// so the whole session is revoked.
+func (s *AuthService) Refresh(refreshToken string, client ClientInfo) (*dto.TokenPair, error) {
+	hash := utils.HashToken(refreshToken)
+
+	session, err := s.sessionRepo.FindByTokenHash(hash)
+	if err != nil {
+		if reused, err := s.sessionRepo.FindByPreviousTokenHash(hash); err == nil {
+			logger.Warn("Refresh token reuse detected, revoking session",
+				zap.Uint("session_id", reused.ID),
+				zap.Uint("user_id", reused.UserID),
+			)
+			s.revokeSession(reused.ID)
+		}
+		return nil, ErrInvalidRefreshToken
+	}
+
+	if !session.IsActive() || session.User.ID == 0 {
+		return nil, ErrInvalidRefreshToken
+	}
+	if session.User.IsBlocked {
+		return nil, ErrAccountBlocked
+	}
+
+	newToken, err := utils.GenerateRandomString(32)
+	if err != nil {
+		return nil, fmt.Errorf("AuthService.Refresh: %w", err)
+	}
+
+	rotated, err := s.sessionRepo.Rotate(session, utils.HashToken(newToken), client.IPAddress)
+	if err != nil {
+		return nil, fmt.Errorf("AuthService.Refresh: %w", err)
+	}
+	if !rotated {
+		// Another request rotated the token first
+		return nil, ErrInvalidRefreshToken
+	}
+
+	return s.issueTokens(&session.User, session, newToken)
+}
+
+// Logout revokes the session the current access token belongs to
+func (s *AuthService) Logout(sessionID uint) error {
+	return s.revokeSession(sessionID)
+}
+
+// GetSessions lists the user's signed-in devices
+func (s *AuthService) GetSessions(userID, currentSessionID uint) ([]dto.AuthSessionDTO, error) {
+	sessions, err := s.sessionRepo.FindActiveByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]dto.AuthSessionDTO, len(sessions))
+	for i, session := range sessions {
+		result[i] = dto.AuthSessionDTO{
+			ID:         session.ID,
+			DeviceName: session.DeviceName,
+			UserAgent:  session.UserAgent,
+			IPAddress:  session.IPAddress,
+			Current:    session.ID == currentSessionID,
+			LastUsedAt: session.LastUsedAt,
+			ExpiresAt:  session.ExpiresAt,
+			CreatedAt:  session.CreatedAt,
+		}
+	}
+	return result, nil
+}
+
+// RevokeSession signs out one of the user's devices
+func (s *AuthService) RevokeSession(userID, sessionID uint) error {
+	session, err := s.sessionRepo.FindByID(sessionID)
+	if err != nil || session.UserID != userID {
+		return ErrSessionNotFound
+	}
+	return s.revokeSession(sessionID)
+}
+
+// RevokeOtherSessions signs out every device of the user except the current
+// one. Pass 0 to sign out everywhere.
+func (s *AuthService) RevokeOtherSessions(userID, currentSessionID uint) (int, error) {
+	ids, err := s.sessionRepo.RevokeAllByUserID(userID, currentSessionID)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	for _, id := range ids {
+		s.revocations.add(id, now)
+	}
+	return len(ids), nil
+}
+
+func (s *AuthService) revokeSession(sessionID uint) error {
+	if err := s.sessionRepo.Revoke(sessionID); err != nil {
+		return err
+	}
+	s.revocations.add(sessionID, time.Now())
+	return nil
+}
+
+func (s *AuthService) issueTokens(user *models.User, session *models.AuthSession, refreshToken string) (*dto.TokenPair, error) {
+	accessToken, err := utils.GenerateToken(user.ID, user.Email, string(user.Role), session.ID, user.TokenVersion, s.accessTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.TokenPair{
+		Token:            accessToken,
+		ExpiresIn:        int(s.accessTTL.Seconds()),
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: session.ExpiresAt,
+		SessionID:        session.ID,
+	}, nil
+}
+
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return s[:max]
+}
@@ -0,0 +1,319 @@
+package services
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Alfian57/ruang-tenang-api/internal/dto"
+	"github.com/Alfian57/ruang-tenang-api/internal/models"
+	"github.com/Alfian57/ruang-tenang-api/pkg/logger"
+	"github.com/Alfian57/ruang-tenang-api/pkg/totp"
+	"github.com/Alfian57/ruang-tenang-api/pkg/utils"
+	"go.uber.org/zap"
+)
+
+const (
+	twoFactorIssuer = "Ruang Tenang"
+	// twoFactorChallengeTTL is how long the user has to enter their code
+	// after the password step
+	twoFactorChallengeTTL = 5 * time.Minute
+	// twoFactorAttemptLimit caps wrong codes per user within the challenge
+	// lifetime, so 6 digit codes can't be brute forced
+	twoFactorAttemptLimit = 5
+
+	recoveryCodeCount    = 10
+	recoveryCodeAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+)
+
+// completeLogin finishes a sign-in whose first factor was verified: accounts
+// with two-factor authentication get a challenge, others a new session
+func (s *AuthService) completeLogin(user *models.User, rememberMe bool, client ClientInfo) (*dto.LoginResponse, error) {
+	if user.HasTwoFactor() {
+		token, err := utils.GenerateTwoFactorToken(user.ID, user.TokenVersion, rememberMe, client.DeviceName, twoFactorChallengeTTL)
+		if err != nil {
+			return nil, errors.New("failed to generate token")
+		}
+		return &dto.LoginResponse{
+			TwoFactor: &dto.TwoFactorChallengeDTO{
+				TwoFactorRequired: true,
+				ChallengeToken:    token,
+				ExpiresAt:         time.Now().Add(twoFactorChallengeTTL),
+			},
+		}, nil
+	}
+
+	tokens, err := s.startSession(user, rememberMe, client)
+	if err != nil {
+		return nil, errors.New("failed to generate token")
+	}
+	return loginResponse(user, tokens), nil
+}
+
+// VerifyTwoFactorLogin completes a challenged sign-in with an authenticator
+// or recovery code
+func (s *AuthService) VerifyTwoFactorLogin(req *dto.TwoFactorLoginRequest, client ClientInfo) (*dto.LoginResponse, error) {
+	claims, err := utils.ValidateTwoFactorToken(req.ChallengeToken)
+	if err != nil {
+		return nil, ErrInvalidTwoFactorChallenge
+	}
+
+	user, err := s.userRepo.FindByID(claims.UserID)
+	if err != nil || claims.TokenVersion != user.TokenVersion || !user.HasTwoFactor() {
+		return nil, ErrInvalidTwoFactorChallenge
+	}
+	if user.IsBlocked {
+		return nil, ErrAccountBlocked
+	}
+
+	if err := s.verifySecondFactor(user, req.Code); err != nil {
+		return nil, err
+	}
+
+	client.DeviceName = claims.DeviceName
+	tokens, err := s.startSession(user, claims.RememberMe, client)
+	if err != nil {
+		return nil, errors.New("failed to generate token")
+	}
+	return loginResponse(user, tokens), nil
+}
+
+func (s *AuthService) GetTwoFactorStatus(userID uint) (*dto.TwoFactorStatusDTO, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	status := &dto.TwoFactorStatusDTO{
+		Enabled:   user.HasTwoFactor(),
+		EnabledAt: user.TwoFactorEnabledAt,
+		Required:  s.twoFactorRequired(user),
+	}
+	if status.Enabled {
+		remaining, err := s.recoveryCodeRepo.CountUnused(userID)
+		if err != nil {
+			return nil, err
+		}
+		status.RecoveryCodesRemaining = int(remaining)
+	}
+	return status, nil
+}
+
+// SetupTwoFactor starts enrollment with a fresh secret. It takes effect once
+// confirmed with a code through EnableTwoFactor.
+func (s *AuthService) SetupTwoFactor(userID uint) (*dto.TwoFactorSetupDTO, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	if user.HasTwoFactor() {
+		return nil, ErrTwoFactorEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, errors.New("failed to generate secret")
+	}
+	encrypted, err := utils.EncryptString(s.twoFactorKey, secret)
+	if err != nil {
+		return nil, errors.New("failed to generate secret")
+	}
+	if err := s.userRepo.SetTwoFactorSecret(userID, encrypted); err != nil {
+		return nil, errors.New("failed to save secret")
+	}
+
+	return &dto.TwoFactorSetupDTO{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(twoFactorIssuer, accountLoginName(user), secret),
+	}, nil
+}
+
+// EnableTwoFactor confirms enrollment with a code from the authenticator app,
+// returns the recovery codes and signs out every other device
+func (s *AuthService) EnableTwoFactor(userID, currentSessionID uint, code string) ([]string, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	if user.HasTwoFactor() {
+		return nil, ErrTwoFactorEnabled
+	}
+	if user.TwoFactorSecret == "" {
+		return nil, ErrTwoFactorNotSetUp
+	}
+	if err := s.allowTwoFactorAttempt(userID); err != nil {
+		return nil, err
+	}
+
+	secret, err := utils.DecryptString(s.twoFactorKey, user.TwoFactorSecret)
+	if err != nil {
+		return nil, ErrTwoFactorNotSetUp
+	}
+	step, ok := totp.Validate(secret, code, time.Now())
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	if err := s.userRepo.EnableTwoFactor(userID, step); err != nil {
+		return nil, errors.New("failed to enable two-factor authentication")
+	}
+	s.userStates.forget(userID)
+
+	codes, err := s.replaceRecoveryCodes(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Existing sessions never passed the second factor
+	if _, err := s.RevokeOtherSessions(userID, currentSessionID); err != nil {
+		logger.Error("Failed to sign out other devices", zap.Uint("user_id", userID), zap.Error(err))
+	}
+
+	return codes, nil
+}
+
+// DisableTwoFactor turns two-factor authentication off after checking a code
+func (s *AuthService) DisableTwoFactor(userID uint, code string) error {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+	if !user.HasTwoFactor() {
+		return ErrTwoFactorNotEnabled
+	}
+	if s.twoFactorRequired(user) {
+		return ErrTwoFactorMandatory
+	}
+
+	if err := s.verifySecondFactor(user, code); err != nil {
+		return err
+	}
+
+	if err := s.userRepo.DisableTwoFactor(userID); err != nil {
+		return errors.New("failed to disable two-factor authentication")
+	}
+	if err := s.recoveryCodeRepo.DeleteByUserID(userID); err != nil {
+		logger.Error("Failed to delete recovery codes", zap.Uint("user_id", userID), zap.Error(err))
+	}
+	s.userStates.forget(userID)
+	return nil
+}
+
+// RegenerateRecoveryCodes replaces all recovery codes after checking a code
+func (s *AuthService) RegenerateRecoveryCodes(userID uint, code string) ([]string, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+	if !user.HasTwoFactor() {
+		return nil, ErrTwoFactorNotEnabled
+	}
+
+	if err := s.verifySecondFactor(user, code); err != nil {
+		return nil, err
+	}
+	return s.replaceRecoveryCodes(userID)
+}
+
+// RequireAdminTwoFactor is an access policy for the admin API when
+// TWO_FACTOR_REQUIRED_FOR_ADMINS is on
+func (s *AuthService) RequireAdminTwoFactor(userID uint) error {
+	if !s.twoFactorForAdmins {
+		return nil
+	}
+
+	state, err := s.loadUserState(userID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+	if state.admin && !state.twoFactor {
+		return ErrTwoFactorSetupRequired
+	}
+	return nil
+}
+
+func (s *AuthService) twoFactorRequired(user *models.User) bool {
+	return s.twoFactorForAdmins && user.IsAdmin()
+}
+
+// verifySecondFactor accepts a current authenticator code, once, or an unused
+// recovery code
+func (s *AuthService) verifySecondFactor(user *models.User, code string) error {
+	if err := s.allowTwoFactorAttempt(user.ID); err != nil {
+		return err
+	}
+
+	code = strings.TrimSpace(code)
+	if _, err := strconv.Atoi(code); err == nil && len(code) == totp.Digits {
+		secret, err := utils.DecryptString(s.twoFactorKey, user.TwoFactorSecret)
+		if err != nil {
+			logger.Error("Failed to decrypt two-factor secret", zap.Uint("user_id", user.ID), zap.Error(err))
+			return ErrInvalidTwoFactorCode
+		}
+		step, ok := totp.Validate(secret, code, time.Now())
+		if !ok {
+			return ErrInvalidTwoFactorCode
+		}
+		if used, err := s.userRepo.UseTwoFactorStep(user.ID, step); err != nil || !used {
+			return ErrInvalidTwoFactorCode
+		}
+		return nil
+	}
+
+	used, err := s.recoveryCodeRepo.Use(user.ID, utils.HashToken(normalizeRecoveryCode(code)))
+	if err != nil || !used {
+		return ErrInvalidTwoFactorCode
+	}
+	logger.Info("Recovery code used", zap.Uint("user_id", user.ID))
+	return nil
+}
+
+func (s *AuthService) allowTwoFactorAttempt(userID uint) error {
+	if ok, retryAfter := s.twoFactorAttempts.Allow(strconv.FormatUint(uint64(userID), 10)); !ok {
+		return &RateLimitError{Err: ErrTooManyTwoFactorAttempts, RetryAfter: retryAfter}
+	}
+	return nil
+}
+
+func (s *AuthService) replaceRecoveryCodes(userID uint) ([]string, error) {
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, errors.New("failed to generate recovery codes")
+		}
+		codes[i] = code
+		hashes[i] = utils.HashToken(normalizeRecoveryCode(code))
+	}
+
+	if err := s.recoveryCodeRepo.Replace(userID, hashes); err != nil {
+		return nil, errors.New("failed to save recovery codes")
+	}
+	return codes, nil
+}
+
+// generateRecoveryCode returns a code like "k7m2p-x9qrt"
+func generateRecoveryCode() (string, error) {
+	var b strings.Builder
+	max := big.NewInt(int64(len(recoveryCodeAlphabet)))
+	for i := 0; i < 10; i++ {
+		if i == 5 {
+			b.WriteByte('-')
+		}
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		b.WriteByte(recoveryCodeAlphabet[n.Int64()])
+	}
+	return b.String(), nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
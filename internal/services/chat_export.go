@@ -0,0 +1,161 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Alfian57/ruang-tenang-api/internal/dto"
+	"github.com/Alfian57/ruang-tenang-api/internal/models"
+	"github.com/Alfian57/ruang-tenang-api/pkg/clock"
+	"github.com/Alfian57/ruang-tenang-api/pkg/pdf"
+)
+
+const (
+	ExportFormatPDF      = "pdf"
+	ExportFormatMarkdown = "md"
+	ExportFormatJSON     = "json"
+
+	exportDisclaimer = "Transkrip ini diekspor dari aplikasi Ruang Tenang atas permintaan pengguna dan bersifat rahasia. " +
+		"Balasan Ruang Tenang AI dihasilkan secara otomatis, bukan diagnosis atau nasihat medis, dan tidak menggantikan " +
+		"penilaian tenaga kesehatan jiwa profesional. Dalam keadaan darurat hubungi 112 atau SEJIWA 119 ext. 8."
+	exportTimeLayout = "02 Jan 2006 15:04 MST"
+)
+
+// ChatExport is a rendered chat session ready to be downloaded
+type ChatExport struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// ExportSession renders one of the user's sessions with all its messages as
+// PDF, Markdown or JSON
+func (s *ChatService) ExportSession(sessionID, userID uint, format string) (*ChatExport, error) {
+	session, err := s.sessionRepo.FindByIDWithMessages(sessionID)
+	if err != nil {
+		return nil, errors.New("session not found")
+	}
+
+	if session.UserID != userID {
+		return nil, errors.New("unauthorized")
+	}
+
+	loc, err := s.userLocation(session.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("ChatService.ExportSession: failed to load timezone: %w", err)
+	}
+
+	exportedAt := time.Now().In(loc)
+	filename := fmt.Sprintf("ruang-tenang-chat-%d-%s", session.ID, exportedAt.Format("20060102"))
+
+	switch format {
+	case ExportFormatPDF, "":
+		data, err := renderChatPDF(session, exportedAt)
+		if err != nil {
+			return nil, fmt.Errorf("ChatService.ExportSession: failed to render PDF: %w", err)
+		}
+		return &ChatExport{Filename: filename + ".pdf", ContentType: "application/pdf", Data: data}, nil
+	case ExportFormatMarkdown, "markdown":
+		return &ChatExport{
+			Filename:    filename + ".md",
+			ContentType: "text/markdown; charset=utf-8",
+			Data:        []byte(renderChatMarkdown(session, exportedAt)),
+		}, nil
+	case ExportFormatJSON:
+		data, err := renderChatJSON(session, exportedAt)
+		if err != nil {
+			return nil, fmt.Errorf("ChatService.ExportSession: failed to render JSON: %w", err)
+		}
+		return &ChatExport{Filename: filename + ".json", ContentType: "application/json", Data: data}, nil
+	default:
+		return nil, fmt.Errorf("unsupported export format %q", format)
+	}
+}
+
+// userLocation is the timezone exported timestamps are shown in, the
+// session owner's own
+func (s *ChatService) userLocation(userID uint) (*time.Location, error) {
+	timezone, err := s.userRepo.FindTimezone(userID)
+	if err != nil {
+		return nil, err
+	}
+	return clock.Location(timezone), nil
+}
+
+func exportSpeaker(msg *models.ChatMessage) string {
+	if msg.IsAI() {
+		return "Ruang Tenang AI"
+	}
+	return "Pengguna"
+}
+
+// exportContent is the text shown for a message; voice notes are marked as such
+func exportContent(msg *models.ChatMessage) string {
+	if msg.Type == "audio" {
+		return "[Pesan suara] " + msg.Content
+	}
+	return msg.Content
+}
+
+func renderChatPDF(session *models.ChatSession, exportedAt time.Time) ([]byte, error) {
+	loc := exportedAt.Location()
+	doc := pdf.New()
+
+	doc.Paragraph(session.Title, pdf.Title)
+	doc.Space(4)
+	doc.Paragraph(fmt.Sprintf("Dimulai %s  ·  %d pesan  ·  Diekspor %s",
+		session.CreatedAt.In(loc).Format(exportTimeLayout), len(session.Messages), exportedAt.Format(exportTimeLayout)), pdf.Small)
+	doc.Rule()
+
+	for i := range session.Messages {
+		msg := &session.Messages[i]
+		doc.Paragraph(fmt.Sprintf("%s  ·  %s", exportSpeaker(msg), msg.CreatedAt.In(loc).Format(exportTimeLayout)), pdf.Heading)
+		doc.Space(2)
+		doc.Paragraph(exportContent(msg), pdf.Body)
+		doc.Space(12)
+	}
+
+	doc.Rule()
+	doc.Paragraph(exportDisclaimer, pdf.Small)
+
+	return doc.Bytes()
+}
+
+func renderChatMarkdown(session *models.ChatSession, exportedAt time.Time) string {
+	loc := exportedAt.Location()
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "# %s\n\n", session.Title)
+	fmt.Fprintf(&sb, "- Dimulai: %s\n", session.CreatedAt.In(loc).Format(exportTimeLayout))
+	fmt.Fprintf(&sb, "- Jumlah pesan: %d\n", len(session.Messages))
+	fmt.Fprintf(&sb, "- Diekspor: %s\n\n---\n\n", exportedAt.Format(exportTimeLayout))
+
+	for i := range session.Messages {
+		msg := &session.Messages[i]
+		fmt.Fprintf(&sb, "**%s** · _%s_\n\n", exportSpeaker(msg), msg.CreatedAt.In(loc).Format(exportTimeLayout))
+		sb.WriteString(strings.TrimSpace(exportContent(msg)))
+		sb.WriteString("\n\n")
+	}
+
+	fmt.Fprintf(&sb, "---\n\n> %s\n", exportDisclaimer)
+	return sb.String()
+}
+
+func renderChatJSON(session *models.ChatSession, exportedAt time.Time) ([]byte, error) {
+	messages := make([]dto.ChatMessageDTO, len(session.Messages))
+	for i := range session.Messages {
+		messages[i] = *toChatMessageDTO(&session.Messages[i])
+	}
+
+	return json.MarshalIndent(dto.ChatExportDTO{
+		ID:         session.ID,
+		Title:      session.Title,
+		CreatedAt:  session.CreatedAt,
+		ExportedAt: exportedAt,
+		Messages:   messages,
+		Disclaimer: exportDisclaimer,
+	}, "", "  ")
+}
@@ -0,0 +1,40 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Alfian57/ruang-tenang-api/internal/dto"
+	"github.com/Alfian57/ruang-tenang-api/pkg/clock"
+	"github.com/Alfian57/ruang-tenang-api/pkg/llm"
+	"github.com/Alfian57/ruang-tenang-api/pkg/stt"
+)
+
+func TestExportUsesOwnerTimezone(t *testing.T) {
+	for _, timezone := range []string{"Asia/Jakarta", "Asia/Jayapura", "Europe/Amsterdam"} {
+		t.Run(timezone, func(t *testing.T) {
+			chatService, user, session := newTestChatService(t, llm.NewStubProvider(), stt.NewStubTranscriber())
+			if err := chatService.userRepo.UpdateTimezone(user.ID, timezone); err != nil {
+				t.Fatalf("update timezone: %v", err)
+			}
+			req := &dto.SendMessageRequest{Content: "Halo"}
+			if _, err := chatService.SendMessageStream(context.Background(), session.ID, user.ID, req,
+				func(*dto.ChatMessageDTO) {}, func(string) error { return nil }); err != nil {
+				t.Fatalf("send: %v", err)
+			}
+
+			export, err := chatService.ExportSession(session.ID, user.ID, ExportFormatMarkdown)
+			if err != nil {
+				t.Fatalf("export: %v", err)
+			}
+
+			// Start, export time and both messages
+			zone, _ := time.Now().In(clock.Location(timezone)).Zone()
+			if got := string(export.Data); strings.Count(got, " "+zone) < 4 {
+				t.Errorf("export timestamps are not in %s:\n%s", zone, got)
+			}
+		})
+	}
+}
@@ -4,43 +4,103 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"math/rand"
 	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/Alfian57/ruang-tenang-api/internal/config"
 	"github.com/Alfian57/ruang-tenang-api/internal/dto"
 	"github.com/Alfian57/ruang-tenang-api/internal/models"
 	"github.com/Alfian57/ruang-tenang-api/internal/repositories"
-	"github.com/google/generative-ai-go/genai"
-	"google.golang.org/api/option"
+	"github.com/Alfian57/ruang-tenang-api/pkg/llm"
+	"github.com/Alfian57/ruang-tenang-api/pkg/logger"
+	"github.com/Alfian57/ruang-tenang-api/pkg/safety"
+	"github.com/Alfian57/ruang-tenang-api/pkg/stt"
+	"go.uber.org/zap"
 )
 
+const (
+	defaultSummaryInterval     = 10
+	defaultHistoryTokenBudget  = 3000
+	summaryTimeout             = 60 * time.Second
+	summaryPromptInstruction   = "Anda merangkum sesi konseling antara pengguna dan Ruang Tenang AI. Perbarui ringkasan yang ada dengan percakapan baru. Pertahankan fakta penting tentang pengguna, perasaan, masalah utama, saran yang sudah diberikan, dan komitmen yang dibuat. Tulis dalam bahasa Indonesia, maksimal 200 kata, sebagai paragraf singkat tanpa salam."
+	summaryContextIntroduction = "\n\nRingkasan percakapan sebelumnya dalam sesi ini:\n"
+
+	titleTimeout           = 30 * time.Second
+	titleMaxWords          = 6
+	titleMaxLength         = 60
+	titlePromptInstruction = "Buat judul singkat (maksimal 6 kata) untuk percakapan berikut dalam bahasa yang sama dengan pengguna. Balas hanya dengan judulnya saja, tanpa tanda kutip atau tanda baca di akhir."
+
+	systemPromptPath    = "prompts/ai_prompt.txt"
+	defaultSystemPrompt = "Anda adalah asisten kesehatan mental yang empatik, suportif, dan menenangkan bernama Ruang Tenang AI. Tugas Anda adalah mendengarkan keluh kesah pengguna, memberikan validasi emosional, dan saran-saran praktis untuk manajemen stres atau kecemasan. Jangan memberikan diagnosis medis. Gunakan bahasa Indonesia yang sopan, hangat, dan tidak menghakimi."
+	aiFallbackResponse  = "Maaf, saya sedang mengalami gangguan koneksi. Silakan coba lagi nanti."
+	// aiInterruptedResponse answers a message whose sender disconnected before
+	// any reply was generated
+	aiInterruptedResponse = "Balasan tidak sempat dibuat karena koneksi terputus. Silakan kirim ulang pesanmu."
+
+	audioURLPrefix       = "/uploads/audio/"
+	audioUploadDir       = "uploads/audio"
+	transcriptionTimeout = 60 * time.Second
+
+	// crisisPromptAddendum is appended to the system prompt for high-risk messages
+	crisisPromptAddendum = "\n\nPENTING: Pesan terakhir pengguna menunjukkan kemungkinan risiko bunuh diri atau menyakiti diri sendiri. Tanggapi dengan tenang dan penuh kepedulian, tanyakan apakah pengguna aman saat ini, dorong untuk segera menghubungi layanan darurat atau orang terdekat, dan jangan memberikan informasi yang dapat membahayakan."
+)
+
+// audioMimeTypes maps the extensions accepted by /upload/audio to their MIME type
+var audioMimeTypes = map[string]string{
+	".mp3":  "audio/mpeg",
+	".wav":  "audio/wav",
+	".ogg":  "audio/ogg",
+	".webm": "audio/webm",
+}
+
 type ChatService struct {
 	sessionRepo         *repositories.ChatSessionRepository
 	messageRepo         *repositories.ChatMessageRepository
-	genaiClient         *genai.Client
-	genaiModel          *genai.GenerativeModel
+	userRepo            *repositories.UserRepository
+	llmProvider         llm.Provider
+	transcriber         stt.Transcriber
+	promptService       *PromptService
+	crisisService       *CrisisService
+	feedbackService     *FeedbackService
 	gamificationService *GamificationService
+
+	// summaryInterval is how many new messages trigger a summary refresh
+	summaryInterval int
+	// historyTokenBudget caps the estimated tokens of replayed messages
+	historyTokenBudget int
+	// trashRetention is how long trashed sessions are kept before purging
+	trashRetention time.Duration
 }
 
-func NewChatService(sessionRepo *repositories.ChatSessionRepository, messageRepo *repositories.ChatMessageRepository, cfg *config.Config, gamificationService *GamificationService) *ChatService {
-	ctx := context.Background()
-	client, err := genai.NewClient(ctx, option.WithAPIKey(cfg.GeminiAPIKey))
-	var model *genai.GenerativeModel
-	if err == nil {
-		// Use gemini-flash-latest to alias the latest stable version
-		model = client.GenerativeModel("gemini-flash-latest")
-	} else {
-		fmt.Printf("Failed to create Gemini client: %v\n", err)
+func NewChatService(sessionRepo *repositories.ChatSessionRepository, messageRepo *repositories.ChatMessageRepository, userRepo *repositories.UserRepository, cfg *config.Config, llmProvider llm.Provider, transcriber stt.Transcriber, promptService *PromptService, crisisService *CrisisService, feedbackService *FeedbackService, gamificationService *GamificationService) *ChatService {
+	summaryInterval := cfg.ChatSummaryInterval
+	if summaryInterval < 1 {
+		summaryInterval = defaultSummaryInterval
+	}
+	historyTokenBudget := cfg.ChatHistoryTokenBudget
+	if historyTokenBudget < 1 {
+		historyTokenBudget = defaultHistoryTokenBudget
+	}
+	trashRetentionDays := cfg.ChatTrashRetentionDays
+	if trashRetentionDays < 1 {
+		trashRetentionDays = defaultTrashRetentionDays
 	}
 
 	return &ChatService{
 		sessionRepo:         sessionRepo,
 		messageRepo:         messageRepo,
-		genaiClient:         client,
-		genaiModel:          model,
+		userRepo:            userRepo,
+		llmProvider:         llmProvider,
+		transcriber:         transcriber,
+		promptService:       promptService,
+		crisisService:       crisisService,
+		feedbackService:     feedbackService,
 		gamificationService: gamificationService,
+		summaryInterval:     summaryInterval,
+		historyTokenBudget:  historyTokenBudget,
+		trashRetention:      time.Duration(trashRetentionDays) * 24 * time.Hour,
 	}
 }
 
@@ -61,6 +121,8 @@ func (s *ChatService) GetSessions(userID uint, params dto.ChatSessionQueryParams
 			Title:       session.Title,
 			IsTrash:     session.IsTrash,
 			IsFavorite:  session.IsFavorite,
+			TrashedAt:   session.TrashedAt,
+			PurgeAt:     s.purgeAt(session.TrashedAt),
 			LastMessage: lastMsg,
 			CreatedAt:   session.CreatedAt.Format("2006-01-02T15:04:05Z"),
 		})
@@ -86,6 +148,7 @@ func (s *ChatService) GetSessionByID(id, userID uint) (*dto.ChatSessionDTO, erro
 			Role:       string(msg.Role),
 			Content:    msg.Content,
 			Type:       msg.Type,
+			AudioURL:   msg.AudioURL,
 			IsLiked:    msg.IsLiked,
 			IsDisliked: msg.IsDisliked,
 			CreatedAt:  msg.CreatedAt,
@@ -97,6 +160,8 @@ func (s *ChatService) GetSessionByID(id, userID uint) (*dto.ChatSessionDTO, erro
 		Title:      session.Title,
 		IsTrash:    session.IsTrash,
 		IsFavorite: session.IsFavorite,
+		TrashedAt:  session.TrashedAt,
+		PurgeAt:    s.purgeAt(session.TrashedAt),
 		Messages:   messages,
 		CreatedAt:  session.CreatedAt,
 		UpdatedAt:  session.UpdatedAt,
@@ -104,9 +169,14 @@ func (s *ChatService) GetSessionByID(id, userID uint) (*dto.ChatSessionDTO, erro
 }
 
 func (s *ChatService) CreateSession(userID uint, req *dto.CreateChatSessionRequest) (*models.ChatSession, error) {
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		title = models.DefaultChatSessionTitle
+	}
+
 	session := &models.ChatSession{
 		UserID: userID,
-		Title:  req.Title,
+		Title:  title,
 	}
 
 	if err := s.sessionRepo.Create(session); err != nil {
@@ -116,14 +186,77 @@ func (s *ChatService) CreateSession(userID uint, req *dto.CreateChatSessionReque
 	return session, nil
 }
 
-func (s *ChatService) SendMessage(sessionID, userID uint, req *dto.SendMessageRequest) (*dto.ChatMessageDTO, *dto.ChatMessageDTO, error) {
+func (s *ChatService) SendMessage(ctx context.Context, sessionID, userID uint, req *dto.SendMessageRequest) (*dto.ChatMessageDTO, *dto.ChatMessageDTO, error) {
+	session, userMsg, assessment, err := s.createUserMessage(ctx, sessionID, userID, req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Generate AI response
+	systemPrompt, promptID := s.systemPromptFor(session, req.Locale, assessment)
+	aiResponseText := finishReply(ctx, s.generateReply(ctx, systemPrompt, s.buildHistory(session, userMsg.Content)), assessment)
+
+	aiMsg, err := s.saveAIReply(session, userMsg, userID, aiResponseText, promptID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return toChatMessageDTO(userMsg), toChatMessageDTO(aiMsg), nil
+}
+
+// SendMessageStream persists the user message, reports it through onUserMessage
+// and then streams the AI reply through onToken. The AI message is saved when
+// the stream completes, or with the partial reply if the client disconnects.
+func (s *ChatService) SendMessageStream(ctx context.Context, sessionID, userID uint, req *dto.SendMessageRequest, onUserMessage func(*dto.ChatMessageDTO), onToken llm.TokenFunc) (*dto.ChatMessageDTO, error) {
+	session, userMsg, assessment, err := s.createUserMessage(ctx, sessionID, userID, req)
+	if err != nil {
+		return nil, err
+	}
+	onUserMessage(toChatMessageDTO(userMsg))
+
+	systemPrompt, promptID := s.systemPromptFor(session, req.Locale, assessment)
+	history := s.buildHistory(session, userMsg.Content)
+	aiResponseText, err := s.llmProvider.Stream(ctx, systemPrompt, history, onToken)
+	// When the client went away, whatever was already delivered is kept
+	if err != nil && ctx.Err() == nil {
+		logger.Error("LLM stream failed",
+			zap.String("provider", s.llmProvider.Name()),
+			zap.String("model", s.llmProvider.Model()),
+			zap.Error(err),
+		)
+		if aiResponseText == "" {
+			aiResponseText = aiFallbackResponse
+			_ = onToken(aiResponseText)
+		}
+	}
+
+	// The crisis resources are saved even when the client left, so they are
+	// there when the session is reopened; only streaming them is skipped
+	if assessment.Level == safety.RiskHigh && ctx.Err() == nil {
+		_ = onToken("\n\n" + safety.CrisisResources)
+	}
+	aiResponseText = finishReply(ctx, aiResponseText, assessment)
+
+	aiMsg, err := s.saveAIReply(session, userMsg, userID, aiResponseText, promptID)
+	if err != nil {
+		return nil, err
+	}
+
+	return toChatMessageDTO(aiMsg), nil
+}
+
+// createUserMessage checks session ownership, screens the incoming user
+// message for crisis risk and stores it
+func (s *ChatService) createUserMessage(ctx context.Context, sessionID, userID uint, req *dto.SendMessageRequest) (*models.ChatSession, *models.ChatMessage, safety.Assessment, error) {
+	var assessment safety.Assessment
+
 	session, err := s.sessionRepo.FindByIDWithMessages(sessionID)
 	if err != nil {
-		return nil, nil, fmt.Errorf("ChatService.SendMessage: session not found: %w", err)
+		return nil, nil, assessment, fmt.Errorf("ChatService.SendMessage: session not found: %w", err)
 	}
 
 	if session.UserID != userID {
-		return nil, nil, fmt.Errorf("ChatService.SendMessage: unauthorized access to session %d", sessionID)
+		return nil, nil, assessment, fmt.Errorf("ChatService.SendMessage: unauthorized access to session %d", sessionID)
 	}
 
 	// Determine message type, default to "text"
@@ -132,124 +265,164 @@ func (s *ChatService) SendMessage(sessionID, userID uint, req *dto.SendMessageRe
 		msgType = "text"
 	}
 
-	// Create user message
+	// Audio messages are answered from their transcript
+	content := req.Content
+	audioURL := ""
+	if msgType == "audio" {
+		content, err = s.transcribe(ctx, req.AudioURL)
+		if err != nil {
+			return nil, nil, assessment, err
+		}
+		audioURL = req.AudioURL
+	} else if strings.TrimSpace(content) == "" {
+		return nil, nil, assessment, errors.New("content is required")
+	}
+
+	assessment = s.crisisService.Assess(ctx, content)
+
 	userMsg := &models.ChatMessage{
 		ChatSessionID: sessionID,
 		Role:          models.ChatRoleUser,
-		Content:       req.Content,
+		Content:       content,
 		Type:          msgType,
+		AudioURL:      audioURL,
+		RiskLevel:     string(assessment.Level),
 	}
 
 	if err := s.messageRepo.Create(userMsg); err != nil {
-		return nil, nil, fmt.Errorf("ChatService.SendMessage: failed to create user message: %w", err)
+		return nil, nil, assessment, fmt.Errorf("ChatService.SendMessage: failed to create user message: %w", err)
 	}
 
-	// Generate AI response
-	aiResponseText := "Maaf, saya sedang mengalami gangguan koneksi. Silakan coba lagi nanti."
-	if s.genaiModel != nil {
-		ctx := context.Background()
-
-		// Build history
-		cs := s.genaiModel.StartChat()
+	if err := s.crisisService.RecordFlag(userID, userMsg, assessment); err != nil {
+		logger.Error("Failed to record crisis flag",
+			zap.Uint("message_id", userMsg.ID),
+			zap.Error(err),
+		)
+	}
 
-		// Load system prompt from file
-		systemPrompt := "Anda adalah asisten kesehatan mental yang empatik, suportif, dan menenangkan bernama Ruang Tenang AI. Tugas Anda adalah mendengarkan keluh kesah pengguna, memberikan validasi emosional, dan saran-saran praktis untuk manajemen stres atau kecemasan. Jangan memberikan diagnosis medis. Gunakan bahasa Indonesia yang sopan, hangat, dan tidak menghakimi."
-		if promptData, err := os.ReadFile("prompts/ai_prompt.txt"); err == nil {
-			systemPrompt = string(promptData)
-		}
+	return session, userMsg, assessment, nil
+}
 
-		// Note: gemini-pro text-only input often takes history by just appending.
-		// However, creating a chat session properly is better.
-		// We need to map our history to genai history.
-		// For simplicity/safety with current SDK version, we'll just send the current message with system prompt prepended context if history is empty,
-		// or iterate history.
-
-		// Let's rely on StartChat and manually populate history if needed,
-		// but simple call for now:
-		cs.History = []*genai.Content{}
-		// Prepend system prompt as the first part of context if possible, or just instruction.
-		// Simplest valid approach for mental health context:
-
-		// Map existing messages to history
-		// Limit to last 10 messages for context window efficiency
-		startIdx := 0
-		if len(session.Messages) > 10 {
-			startIdx = len(session.Messages) - 10
-		}
+// transcribe reads an audio file uploaded through /upload/audio and converts
+// it to text
+func (s *ChatService) transcribe(ctx context.Context, audioURL string) (string, error) {
+	if !strings.HasPrefix(audioURL, audioURLPrefix) {
+		return "", ErrAudioNotFound
+	}
 
-		// Add system instruction as first user part conceptually (or relies on model instruction)
-		// For this implementation, we will append recent history.
+	// Only the file name is taken from the URL so it can't escape the upload dir
+	name := filepath.Base(strings.TrimPrefix(audioURL, audioURLPrefix))
+	audio, err := os.ReadFile(filepath.Join(audioUploadDir, name))
+	if err != nil {
+		return "", ErrAudioNotFound
+	}
 
-		// Add System Prompt as the very first history item from "user" role to set behavior
-		cs.History = append(cs.History, &genai.Content{
-			Role: "user",
-			Parts: []genai.Part{
-				genai.Text(systemPrompt),
-			},
-		})
-		cs.History = append(cs.History, &genai.Content{
-			Role: "model",
-			Parts: []genai.Part{
-				genai.Text("Baik, saya mengerti. Saya siap mendengarkan dan membantu Anda dengan penuh empati."),
-			},
-		})
+	mimeType, ok := audioMimeTypes[strings.ToLower(filepath.Ext(name))]
+	if !ok {
+		mimeType = "audio/mpeg"
+	}
 
-		for i := startIdx; i < len(session.Messages); i++ {
-			msg := session.Messages[i]
-			role := "user"
-			if msg.Role == models.ChatRoleAI {
-				role = "model"
-			}
-			cs.History = append(cs.History, &genai.Content{
-				Role: role,
-				Parts: []genai.Part{
-					genai.Text(msg.Content),
-				},
-			})
-		}
+	ctx, cancel := context.WithTimeout(ctx, transcriptionTimeout)
+	defer cancel()
 
-		resp, err := cs.SendMessage(ctx, genai.Text(req.Content))
-		if err == nil && len(resp.Candidates) > 0 {
-			if len(resp.Candidates[0].Content.Parts) > 0 {
-				if txt, ok := resp.Candidates[0].Content.Parts[0].(genai.Text); ok {
-					aiResponseText = string(txt)
-				}
-			}
-		} else {
-			fmt.Printf("Gemini Error: %v\n", err)
-		}
+	transcript, err := s.transcriber.Transcribe(ctx, audio, mimeType)
+	if err != nil {
+		logger.Error("Failed to transcribe audio message",
+			zap.String("transcriber", s.transcriber.Name()),
+			zap.String("audio_url", audioURL),
+			zap.Error(err),
+		)
+		return "", ErrTranscriptionFailed
 	}
+	return transcript, nil
+}
 
+// saveAIReply stores the AI message, bumps the session, refreshes the
+// conversation summary when due and awards chat EXP
+func (s *ChatService) saveAIReply(session *models.ChatSession, userMsg *models.ChatMessage, userID uint, content string, promptID *uint) (*models.ChatMessage, error) {
 	aiMsg := &models.ChatMessage{
-		ChatSessionID: sessionID,
-		Role:          models.ChatRoleAI,
-		Content:       aiResponseText,
+		ChatSessionID:  session.ID,
+		Role:           models.ChatRoleAI,
+		Content:        content,
+		Type:           "text",
+		SystemPromptID: promptID,
+		Provider:       s.llmProvider.Name(),
+		Model:          s.llmProvider.Model(),
 	}
 
 	if err := s.messageRepo.Create(aiMsg); err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 
 	// Update session timestamp
-	session.UpdatedAt = time.Now()
-	_ = s.sessionRepo.Update(session)
+	_ = s.sessionRepo.Touch(session.ID)
+
+	messages := append(append([]models.ChatMessage{}, session.Messages...), *userMsg, *aiMsg)
+	if s.summaryDue(session, len(messages)) {
+		go s.refreshSummary(session.ID, session.Summary, session.SummarizedCount, messages)
+	}
+
+	// Name untitled sessions after their first exchange
+	if len(session.Messages) == 0 && session.Title == models.DefaultChatSessionTitle {
+		go func() {
+			ctx, cancel := context.WithTimeout(context.Background(), titleTimeout)
+			defer cancel()
+			title := s.generateTitle(ctx, userMsg.Content, aiMsg.Content)
+			if err := s.sessionRepo.UpdateTitle(session.ID, title); err != nil {
+				logger.Warn("Failed to save chat title", zap.Uint("session_id", session.ID), zap.Error(err))
+			}
+		}()
+	}
 
 	// Award EXP
 	_ = s.gamificationService.AwardExp(userID, "chat_ai", 10) // Should use constant, importing pkg/gamification
 
+	return aiMsg, nil
+}
+
+func toChatMessageDTO(msg *models.ChatMessage) *dto.ChatMessageDTO {
 	return &dto.ChatMessageDTO{
-			ID:        userMsg.ID,
-			Role:      string(userMsg.Role),
-			Content:   userMsg.Content,
-			Type:      userMsg.Type,
-			CreatedAt: userMsg.CreatedAt,
-		}, &dto.ChatMessageDTO{
-			ID:        aiMsg.ID,
-			Role:      string(aiMsg.Role),
-			Content:   aiMsg.Content,
-			Type:      "text",
-			CreatedAt: aiMsg.CreatedAt,
-		}, nil
+		ID:         msg.ID,
+		Role:       string(msg.Role),
+		Content:    msg.Content,
+		Type:       msg.Type,
+		AudioURL:   msg.AudioURL,
+		IsLiked:    msg.IsLiked,
+		IsDisliked: msg.IsDisliked,
+		CreatedAt:  msg.CreatedAt,
+	}
+}
+
+// RegenerateTitle rebuilds the session title from its first user message and AI reply
+func (s *ChatService) RegenerateTitle(ctx context.Context, sessionID, userID uint) (string, error) {
+	session, err := s.sessionRepo.FindByIDWithMessages(sessionID)
+	if err != nil {
+		return "", errors.New("session not found")
+	}
+
+	if session.UserID != userID {
+		return "", errors.New("unauthorized")
+	}
+
+	var firstUser, firstAI string
+	for _, msg := range session.Messages {
+		if firstUser == "" && msg.IsUser() {
+			firstUser = msg.Content
+		} else if firstUser != "" && firstAI == "" && msg.IsAI() {
+			firstAI = msg.Content
+			break
+		}
+	}
+	if firstUser == "" {
+		return "", errors.New("session has no messages yet")
+	}
+
+	title := s.generateTitle(ctx, firstUser, firstAI)
+	if err := s.sessionRepo.UpdateTitle(sessionID, title); err != nil {
+		return "", errors.New("failed to update title")
+	}
+
+	return title, nil
 }
 
 func (s *ChatService) ToggleTrash(sessionID, userID uint) error {
@@ -291,26 +464,253 @@ func (s *ChatService) DeleteSession(sessionID, userID uint) error {
 	return s.sessionRepo.Delete(sessionID)
 }
 
-func (s *ChatService) ToggleMessageLike(messageID, userID uint) error {
-	// Verification logic could be added here (e.g., check if message belongs to user's session)
-	// For now, assuming ID access check is sufficient or will be handled by repo finding
-	return s.messageRepo.ToggleLike(messageID)
+func (s *ChatService) ToggleMessageLike(messageID, userID uint, reason string) error {
+	return s.toggleFeedback(messageID, userID, models.FeedbackLike, reason)
+}
+
+func (s *ChatService) ToggleMessageDislike(messageID, userID uint, reason string) error {
+	return s.toggleFeedback(messageID, userID, models.FeedbackDislike, reason)
+}
+
+// toggleFeedback flips the rating of an AI message in one of the user's own
+// sessions and records the change as a feedback event
+func (s *ChatService) toggleFeedback(messageID, userID uint, rating models.FeedbackRating, reason string) error {
+	msg, err := s.messageRepo.FindByID(messageID)
+	if err != nil {
+		return errors.New("message not found")
+	}
+
+	session, err := s.sessionRepo.FindByID(msg.ChatSessionID)
+	if err != nil || session.UserID != userID {
+		return errors.New("unauthorized")
+	}
+
+	if !msg.IsAI() {
+		return errors.New("only AI messages can be rated")
+	}
+
+	current := models.FeedbackCleared
+	if msg.IsLiked {
+		current = models.FeedbackLike
+	} else if msg.IsDisliked {
+		current = models.FeedbackDislike
+	}
+	if current == rating {
+		rating = models.FeedbackCleared
+	}
+
+	return s.feedbackService.Record(msg, userID, rating, strings.TrimSpace(reason))
+}
+
+// loadSystemPrompt returns the active prompt version for the locale from the
+// prompt registry. Without one it reads the prompt from disk, falling back to
+// the built-in default; the returned ID is nil in that case.
+func (s *ChatService) loadSystemPrompt(locale string, sessionID uint) (string, *uint) {
+	if prompt := s.promptService.Resolve(models.SystemPromptChat, locale, sessionID); prompt != nil {
+		return prompt.Content, &prompt.ID
+	}
+	if promptData, err := os.ReadFile(systemPromptPath); err == nil {
+		return string(promptData), nil
+	}
+	return defaultSystemPrompt, nil
+}
+
+// systemPromptFor returns the system prompt with the running session summary
+// prepended to the conversation context, adding crisis guidance for high-risk
+// messages, together with the ID of the prompt version used
+func (s *ChatService) systemPromptFor(session *models.ChatSession, locale string, assessment safety.Assessment) (string, *uint) {
+	prompt, promptID := s.loadSystemPrompt(locale, session.ID)
+	if session.Summary != "" {
+		prompt += summaryContextIntroduction + session.Summary
+	}
+	if assessment.Level == safety.RiskHigh {
+		prompt += crisisPromptAddendum
+	}
+	return prompt, promptID
+}
+
+// buildHistory maps the messages not yet covered by the session summary plus
+// the new user message to provider messages, dropping the oldest turns once
+// the token budget is exhausted
+func (s *ChatService) buildHistory(session *models.ChatSession, content string) []llm.Message {
+	messages := session.Messages
+	if session.SummarizedCount > 0 && session.SummarizedCount <= len(messages) {
+		messages = messages[session.SummarizedCount:]
+	}
+
+	budget := s.historyTokenBudget - llm.EstimateTokens(content)
+	startIdx := len(messages)
+	for startIdx > 0 {
+		cost := llm.EstimateTokens(messages[startIdx-1].Content)
+		if cost > budget {
+			break
+		}
+		budget -= cost
+		startIdx--
+	}
+
+	history := make([]llm.Message, 0, len(messages)-startIdx+1)
+	for _, msg := range messages[startIdx:] {
+		history = append(history, toLLMMessage(msg))
+	}
+
+	return append(history, llm.Message{Role: llm.RoleUser, Content: content})
+}
+
+// summaryDue reports whether enough unsummarised messages have piled up to
+// fold another batch into the summary. The newest summaryInterval messages
+// are always kept verbatim.
+func (s *ChatService) summaryDue(session *models.ChatSession, total int) bool {
+	return total-session.SummarizedCount >= 2*s.summaryInterval
+}
+
+// refreshSummary folds every message except the newest summaryInterval into
+// the running summary. It runs in the background after a reply is saved.
+func (s *ChatService) refreshSummary(sessionID uint, summary string, summarizedCount int, messages []models.ChatMessage) {
+	ctx, cancel := context.WithTimeout(context.Background(), summaryTimeout)
+	defer cancel()
+
+	upTo := len(messages) - s.summaryInterval
+	if upTo <= summarizedCount {
+		return
+	}
+
+	var transcript strings.Builder
+	if summary != "" {
+		transcript.WriteString("Ringkasan sebelumnya:\n" + summary + "\n\n")
+	}
+	transcript.WriteString("Percakapan baru:\n")
+	for _, msg := range messages[summarizedCount:upTo] {
+		speaker := "Pengguna"
+		if msg.Role == models.ChatRoleAI {
+			speaker = "AI"
+		}
+		transcript.WriteString(speaker + ": " + msg.Content + "\n")
+	}
+
+	newSummary, err := s.llmProvider.Generate(ctx, summaryPromptInstruction, []llm.Message{
+		{Role: llm.RoleUser, Content: transcript.String()},
+	})
+	if err != nil {
+		logger.Warn("Failed to refresh chat summary",
+			zap.Uint("session_id", sessionID),
+			zap.Error(err),
+		)
+		return
+	}
+
+	// Refreshes run concurrently for quick replies; one that started from an
+	// older summary is dropped rather than overwriting a newer one
+	saved, err := s.sessionRepo.UpdateSummary(sessionID, strings.TrimSpace(newSummary), summarizedCount, upTo)
+	if err != nil {
+		logger.Warn("Failed to save chat summary",
+			zap.Uint("session_id", sessionID),
+			zap.Error(err),
+		)
+	} else if !saved {
+		logger.Debug("Discarded stale chat summary", zap.Uint("session_id", sessionID))
+	}
 }
 
-func (s *ChatService) ToggleMessageDislike(messageID, userID uint) error {
-	return s.messageRepo.ToggleDislike(messageID)
+// generateTitle asks the model for a short session title, falling back to the
+// opening words of the user's message
+func (s *ChatService) generateTitle(ctx context.Context, userContent, aiContent string) string {
+	transcript := "Pengguna: " + userContent
+	if aiContent != "" {
+		transcript += "\nAI: " + aiContent
+	}
+
+	reply, err := s.llmProvider.Generate(ctx, titlePromptInstruction, []llm.Message{
+		{Role: llm.RoleUser, Content: transcript},
+	})
+	if err == nil {
+		if title := cleanTitle(reply); title != "" {
+			return title
+		}
+	} else {
+		logger.Warn("Failed to generate chat title", zap.Error(err))
+	}
+
+	return heuristicTitle(userContent)
 }
 
-// generateAIResponse generates a placeholder AI response
-// TODO: Integrate with OpenAI/Gemini API
-func (s *ChatService) generateAIResponse(userMessage string) string {
-	responses := []string{
-		"Terima kasih sudah mempercayai saya. Mari kita bicarakan apa yang sedang kamu rasakan.",
-		"Saya di sini untukmu. Tidak apa-apa untuk merasa seperti ini. Apa yang ingin kamu ceritakan?",
-		"Perasaanmu sangat berarti. Saya harap kamu tahu bahwa selalu ada harapan dan bantuan tersedia.",
-		"Kamu sangat berani untuk berbagi ini. Bagaimana jika kita coba teknik pernapasan sederhana bersama?",
+// cleanTitle keeps the first line of a model reply, strips quotes and trailing
+// punctuation and rejects replies that are clearly not a title
+func cleanTitle(reply string) string {
+	title := strings.TrimSpace(strings.SplitN(strings.TrimSpace(reply), "\n", 2)[0])
+	title = strings.Trim(title, "\"'`*#")
+	title = strings.TrimRight(title, ".!?:; ")
+	title = strings.TrimSpace(strings.TrimPrefix(title, "Judul:"))
+
+	if title == "" || len(strings.Fields(title)) > titleMaxWords*2 {
+		return ""
 	}
+	return truncateTitle(title)
+}
 
-	rand.Seed(time.Now().UnixNano())
-	return fmt.Sprintf("%s 💚", responses[rand.Intn(len(responses))])
+// heuristicTitle uses the first few words of the user's message
+func heuristicTitle(content string) string {
+	words := strings.Fields(content)
+	if len(words) == 0 {
+		return models.DefaultChatSessionTitle
+	}
+
+	truncated := len(words) > titleMaxWords
+	if truncated {
+		words = words[:titleMaxWords]
+	}
+
+	title := strings.TrimRight(strings.Join(words, " "), ".,!?:;")
+	if truncated {
+		title += "..."
+	}
+	return truncateTitle(title)
+}
+
+func truncateTitle(title string) string {
+	runes := []rune(title)
+	if len(runes) <= titleMaxLength {
+		return title
+	}
+	return strings.TrimSpace(string(runes[:titleMaxLength-3])) + "..."
+}
+
+func toLLMMessage(msg models.ChatMessage) llm.Message {
+	role := llm.RoleUser
+	if msg.Role == models.ChatRoleAI {
+		role = llm.RoleAssistant
+	}
+	return llm.Message{Role: role, Content: msg.Content}
+}
+
+// generateReply asks the configured provider for a reply, returning a friendly
+// fallback message if the provider fails
+func (s *ChatService) generateReply(ctx context.Context, systemPrompt string, history []llm.Message) string {
+	reply, err := s.llmProvider.Generate(ctx, systemPrompt, history)
+	if err != nil {
+		if ctx.Err() != nil {
+			// The client went away; finishReply explains the missing reply
+			return ""
+		}
+		logger.Error("LLM generation failed",
+			zap.String("provider", s.llmProvider.Name()),
+			zap.String("model", s.llmProvider.Model()),
+			zap.Error(err),
+		)
+		return aiFallbackResponse
+	}
+	return reply
+}
+
+// finishReply appends the crisis resources to replies to high-risk messages.
+// When the client left before any reply text arrived, a short note is saved
+// instead, so the user's message is never left unanswered in the session.
+func finishReply(ctx context.Context, reply string, assessment safety.Assessment) string {
+	if assessment.Level == safety.RiskHigh {
+		return strings.TrimLeft(safety.WithCrisisResources(reply), "\n")
+	}
+	if reply == "" && ctx.Err() != nil {
+		return aiInterruptedResponse
+	}
+	return reply
 }
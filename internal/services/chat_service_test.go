@@ -0,0 +1,351 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Alfian57/ruang-tenang-api/internal/config"
+	"github.com/Alfian57/ruang-tenang-api/internal/dto"
+	"github.com/Alfian57/ruang-tenang-api/internal/models"
+	"github.com/Alfian57/ruang-tenang-api/internal/repositories"
+	"github.com/Alfian57/ruang-tenang-api/pkg/llm"
+	"github.com/Alfian57/ruang-tenang-api/pkg/logger"
+	"github.com/Alfian57/ruang-tenang-api/pkg/safety"
+	"github.com/Alfian57/ruang-tenang-api/pkg/stt"
+	"go.uber.org/zap"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+func TestMain(m *testing.M) {
+	logger.Log = zap.NewNop()
+	logger.Sugar = logger.Log.Sugar()
+	os.Exit(m.Run())
+}
+
+// testDB opens the Postgres database in TEST_DATABASE_URL and returns a
+// transaction that is rolled back after the test. Tests are skipped when no
+// database is configured.
+func testDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: gormlogger.Default.LogMode(gormlogger.Silent)})
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	err = db.AutoMigrate(
+		&models.User{},
+		&models.ChatSession{},
+		&models.ChatMessage{},
+		&models.CrisisFlag{},
+		&models.SystemPrompt{},
+		&models.ChatFeedback{},
+		&models.UserActivity{},
+		&models.ExpHistory{},
+	)
+	if err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	tx := db.Begin()
+	t.Cleanup(func() { tx.Rollback() })
+	return tx
+}
+
+// recordingProvider answers like the stub and keeps the last history it was
+// asked to answer
+type recordingProvider struct {
+	*llm.StubProvider
+	history []llm.Message
+}
+
+func (p *recordingProvider) Generate(ctx context.Context, systemPrompt string, history []llm.Message) (string, error) {
+	p.history = history
+	return p.StubProvider.Generate(ctx, systemPrompt, history)
+}
+
+func (p *recordingProvider) Stream(ctx context.Context, systemPrompt string, history []llm.Message, onToken llm.TokenFunc) (string, error) {
+	p.history = history
+	return p.StubProvider.Stream(ctx, systemPrompt, history, onToken)
+}
+
+// lastTurn is the message the provider was asked to answer
+func (p *recordingProvider) lastTurn() string {
+	if len(p.history) == 0 {
+		return ""
+	}
+	return p.history[len(p.history)-1].Content
+}
+
+// newTestChatService wires a ChatService to the database and a session owned
+// by a fresh user
+func newTestChatService(t *testing.T, provider llm.Provider, transcriber stt.Transcriber) (*ChatService, *models.User, *models.ChatSession) {
+	t.Helper()
+
+	db := testDB(t)
+	promptRepo := repositories.NewSystemPromptRepository(db)
+	chatService := NewChatService(
+		repositories.NewChatSessionRepository(db),
+		repositories.NewChatMessageRepository(db),
+		repositories.NewUserRepository(db),
+		&config.Config{},
+		provider,
+		transcriber,
+		NewPromptService(promptRepo),
+		NewCrisisService(repositories.NewCrisisFlagRepository(db), safety.NewLexiconClassifier()),
+		NewFeedbackService(repositories.NewChatFeedbackRepository(db), promptRepo),
+		NewGamificationService(db),
+	)
+
+	user := &models.User{
+		Name:     "Chat Test",
+		Email:    fmt.Sprintf("chat-test-%d@example.com", time.Now().UnixNano()),
+		Password: "x",
+		Role:     models.RoleMember,
+	}
+	if err := db.Create(user).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+
+	// A titled session skips the background title generation
+	session, err := chatService.CreateSession(user.ID, &dto.CreateChatSessionRequest{Title: "Test"})
+	if err != nil {
+		t.Fatalf("create session: %v", err)
+	}
+
+	return chatService, user, session
+}
+
+func TestChatRoundTripWithStubProvider(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		stream  bool
+	}{
+		{name: "reply", content: "Aku merasa cemas menjelang ujian"},
+		{name: "streamed reply", content: "Aku sulit tidur akhir-akhir ini", stream: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := &recordingProvider{StubProvider: llm.NewStubProvider()}
+			chatService, user, session := newTestChatService(t, provider, stt.NewStubTranscriber())
+			req := &dto.SendMessageRequest{Content: tt.content}
+
+			var userMsg, aiMsg *dto.ChatMessageDTO
+			var streamed strings.Builder
+			var err error
+			if tt.stream {
+				aiMsg, err = chatService.SendMessageStream(context.Background(), session.ID, user.ID, req,
+					func(msg *dto.ChatMessageDTO) { userMsg = msg },
+					func(token string) error {
+						streamed.WriteString(token)
+						return nil
+					})
+			} else {
+				userMsg, aiMsg, err = chatService.SendMessage(context.Background(), session.ID, user.ID, req)
+			}
+			if err != nil {
+				t.Fatalf("send: %v", err)
+			}
+
+			want, _ := llm.NewStubProvider().Generate(context.Background(), "", []llm.Message{{Role: llm.RoleUser, Content: tt.content}})
+			if userMsg == nil || userMsg.Content != tt.content {
+				t.Errorf("user message = %+v, want content %q", userMsg, tt.content)
+			}
+			if provider.lastTurn() != tt.content {
+				t.Errorf("provider answered %q, want %q", provider.lastTurn(), tt.content)
+			}
+			if aiMsg.Content != want {
+				t.Errorf("AI reply = %q, want %q", aiMsg.Content, want)
+			}
+			if tt.stream && streamed.String() != want {
+				t.Errorf("streamed %q, want %q", streamed.String(), want)
+			}
+
+			saved, err := chatService.GetSessionByID(session.ID, user.ID)
+			if err != nil {
+				t.Fatalf("get session: %v", err)
+			}
+			if len(saved.Messages) != 2 || saved.Messages[1].Content != want {
+				t.Errorf("saved messages = %+v, want the exchange", saved.Messages)
+			}
+		})
+	}
+}
+
+func TestStreamKeepsCrisisResourcesWhenClientLeaves(t *testing.T) {
+	tests := []struct {
+		name        string
+		leaveAfter  int // tokens delivered before the client disconnects
+		wantPartial bool
+	}{
+		{name: "mid reply", leaveAfter: 1, wantPartial: true},
+		{name: "before any reply", leaveAfter: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chatService, user, session := newTestChatService(t, llm.NewStubProvider(), stt.NewStubTranscriber())
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tt.leaveAfter == 0 {
+				cancel()
+			}
+
+			delivered := 0
+			req := &dto.SendMessageRequest{Content: "Aku ingin bunuh diri"}
+			_, err := chatService.SendMessageStream(ctx, session.ID, user.ID, req,
+				func(*dto.ChatMessageDTO) {},
+				func(token string) error {
+					if strings.Contains(token, safety.CrisisResources) {
+						t.Error("crisis resources streamed to a disconnected client")
+					}
+					delivered++
+					if delivered == tt.leaveAfter {
+						cancel()
+					}
+					return nil
+				})
+			if err != nil {
+				t.Fatalf("send: %v", err)
+			}
+
+			saved, err := chatService.GetSessionByID(session.ID, user.ID)
+			if err != nil {
+				t.Fatalf("get session: %v", err)
+			}
+			if len(saved.Messages) != 2 {
+				t.Fatalf("saved %d messages, want 2", len(saved.Messages))
+			}
+			reply := saved.Messages[1].Content
+			if !strings.HasSuffix(reply, safety.CrisisResources) {
+				t.Errorf("saved reply %q lacks the crisis resources", reply)
+			}
+			if partial := reply != safety.CrisisResources; partial != tt.wantPartial {
+				t.Errorf("saved reply %q, want partial reply %v", reply, tt.wantPartial)
+			}
+		})
+	}
+}
+
+func TestClientLeavingBeforeReplySavesNote(t *testing.T) {
+	for _, stream := range []bool{false, true} {
+		t.Run(fmt.Sprintf("stream=%v", stream), func(t *testing.T) {
+			chatService, user, session := newTestChatService(t, llm.NewStubProvider(), stt.NewStubTranscriber())
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			req := &dto.SendMessageRequest{Content: "Aku merasa cemas"}
+			var err error
+			if stream {
+				_, err = chatService.SendMessageStream(ctx, session.ID, user.ID, req,
+					func(*dto.ChatMessageDTO) {},
+					func(string) error {
+						t.Error("token streamed to a disconnected client")
+						return nil
+					})
+			} else {
+				_, _, err = chatService.SendMessage(ctx, session.ID, user.ID, req)
+			}
+			if err != nil {
+				t.Fatalf("send: %v", err)
+			}
+
+			saved, err := chatService.GetSessionByID(session.ID, user.ID)
+			if err != nil {
+				t.Fatalf("get session: %v", err)
+			}
+			if len(saved.Messages) != 2 || saved.Messages[1].Content != aiInterruptedResponse {
+				t.Errorf("saved messages = %+v, want the user message answered by the interruption note", saved.Messages)
+			}
+		})
+	}
+}
+
+func TestStaleSummaryRefreshIsDropped(t *testing.T) {
+	chatService, _, session := newTestChatService(t, llm.NewStubProvider(), stt.NewStubTranscriber())
+
+	messages := make([]models.ChatMessage, 4*chatService.summaryInterval)
+	for i := range messages {
+		messages[i] = models.ChatMessage{Role: models.ChatRoleUser, Content: fmt.Sprintf("pesan %d", i)}
+	}
+
+	// Two refreshes start from the same empty summary; the one covering more
+	// messages finishes first
+	newer := len(messages)
+	older := 2 * chatService.summaryInterval
+	chatService.refreshSummary(session.ID, "", 0, messages[:newer])
+	chatService.refreshSummary(session.ID, "", 0, messages[:older])
+
+	saved, err := chatService.sessionRepo.FindByID(session.ID)
+	if err != nil {
+		t.Fatalf("find session: %v", err)
+	}
+	if want := newer - chatService.summaryInterval; saved.SummarizedCount != want {
+		t.Errorf("summarized %d messages, want %d from the newer refresh", saved.SummarizedCount, want)
+	}
+}
+
+func TestAudioMessageIsAnsweredFromTranscript(t *testing.T) {
+	const transcript = "Aku merasa lelah dengan semuanya"
+
+	tests := []struct {
+		name    string
+		content string // what the client sent alongside the audio
+		stream  bool
+	}{
+		{name: "reply", content: ""},
+		{name: "reply ignores client content", content: "something else"},
+		{name: "streamed reply", content: "something else", stream: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Audio is read from uploads/audio relative to the working directory
+			t.Chdir(t.TempDir())
+			if err := os.MkdirAll(audioUploadDir, 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(audioUploadDir+"/voice.webm", []byte("fake audio"), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			provider := &recordingProvider{StubProvider: llm.NewStubProvider()}
+			chatService, user, session := newTestChatService(t, provider, stt.NewStubTranscriberWithText(transcript))
+			req := &dto.SendMessageRequest{Type: "audio", AudioURL: audioURLPrefix + "voice.webm", Content: tt.content}
+
+			var userMsg *dto.ChatMessageDTO
+			var err error
+			if tt.stream {
+				_, err = chatService.SendMessageStream(context.Background(), session.ID, user.ID, req,
+					func(msg *dto.ChatMessageDTO) { userMsg = msg },
+					func(string) error { return nil })
+			} else {
+				userMsg, _, err = chatService.SendMessage(context.Background(), session.ID, user.ID, req)
+			}
+			if err != nil {
+				t.Fatalf("send: %v", err)
+			}
+
+			if userMsg.Content != transcript {
+				t.Errorf("user message content = %q, want the transcript", userMsg.Content)
+			}
+			if provider.lastTurn() != transcript {
+				t.Errorf("provider answered %q, want the transcript %q", provider.lastTurn(), transcript)
+			}
+		})
+	}
+}
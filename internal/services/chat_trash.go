@@ -0,0 +1,126 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Alfian57/ruang-tenang-api/pkg/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	defaultTrashRetentionDays = 30
+	purgeBatchSize            = 100
+)
+
+// RestoreSession takes a session out of the trash
+func (s *ChatService) RestoreSession(sessionID, userID uint) error {
+	session, err := s.sessionRepo.FindByID(sessionID)
+	if err != nil {
+		return errors.New("session not found")
+	}
+
+	if session.UserID != userID {
+		return errors.New("unauthorized")
+	}
+
+	if !session.IsTrash {
+		return errors.New("session is not in trash")
+	}
+
+	return s.sessionRepo.Restore(sessionID)
+}
+
+// EmptyTrash permanently deletes every session in the user's trash and
+// returns how many were removed
+func (s *ChatService) EmptyTrash(userID uint) (int, error) {
+	ids, err := s.sessionRepo.FindTrashedIDs(userID)
+	if err != nil {
+		return 0, fmt.Errorf("ChatService.EmptyTrash: %w", err)
+	}
+
+	if err := s.purgeSessions(ids); err != nil {
+		return 0, fmt.Errorf("ChatService.EmptyTrash: %w", err)
+	}
+	return len(ids), nil
+}
+
+// PurgeUserSessions permanently deletes all of the user's sessions, for
+// account deletion
+func (s *ChatService) PurgeUserSessions(userID uint) error {
+	ids, err := s.sessionRepo.FindAllIDsByUserID(userID)
+	if err != nil {
+		return fmt.Errorf("ChatService.PurgeUserSessions: %w", err)
+	}
+
+	if err := s.purgeSessions(ids); err != nil {
+		return fmt.Errorf("ChatService.PurgeUserSessions: %w", err)
+	}
+	return nil
+}
+
+// PurgeExpiredTrash permanently deletes sessions that have been in the trash,
+// or soft deleted, for longer than the retention period
+func (s *ChatService) PurgeExpiredTrash(ctx context.Context) (int, error) {
+	cutoff := time.Now().Add(-s.trashRetention)
+	purged := 0
+
+	for ctx.Err() == nil {
+		ids, err := s.sessionRepo.FindPurgeableIDs(cutoff, purgeBatchSize)
+		if err != nil {
+			return purged, err
+		}
+		if len(ids) == 0 {
+			break
+		}
+
+		if err := s.purgeSessions(ids); err != nil {
+			return purged, err
+		}
+		purged += len(ids)
+
+		if len(ids) < purgeBatchSize {
+			break
+		}
+	}
+
+	return purged, ctx.Err()
+}
+
+// purgeAt is when a trashed session will be permanently deleted
+func (s *ChatService) purgeAt(trashedAt *time.Time) *time.Time {
+	if trashedAt == nil {
+		return nil
+	}
+	at := trashedAt.Add(s.trashRetention)
+	return &at
+}
+
+// purgeSessions deletes the sessions from the database, then removes their
+// uploaded voice notes from disk
+func (s *ChatService) purgeSessions(ids []uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	audioURLs, err := s.sessionRepo.Purge(ids)
+	if err != nil {
+		return err
+	}
+
+	for _, url := range audioURLs {
+		if !strings.HasPrefix(url, audioURLPrefix) {
+			continue
+		}
+		path := filepath.Join(audioUploadDir, filepath.Base(strings.TrimPrefix(url, audioURLPrefix)))
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			logger.Warn("Failed to remove purged audio file", zap.String("path", path), zap.Error(err))
+		}
+	}
+	return nil
+}
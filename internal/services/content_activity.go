@@ -0,0 +1,25 @@
+package services
+
+import (
+	"time"
+
+	"github.com/Alfian57/ruang-tenang-api/internal/models"
+	"github.com/Alfian57/ruang-tenang-api/internal/repositories"
+)
+
+// Opening the same song or article again within this window, e.g. replaying
+// or reloading it, is not recorded again
+const contentActivityDedupWindow = 30 * time.Minute
+
+func recordContentActivity(repo *repositories.ContentActivityRepository, userID uint, contentType models.ContentType, contentID uint, categoryID *uint) error {
+	if repo.ExistsSince(userID, contentType, contentID, time.Now().Add(-contentActivityDedupWindow)) {
+		return nil
+	}
+
+	return repo.Create(&models.ContentActivity{
+		UserID:      userID,
+		ContentType: contentType,
+		ContentID:   contentID,
+		CategoryID:  categoryID,
+	})
+}
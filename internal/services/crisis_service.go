@@ -0,0 +1,123 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Alfian57/ruang-tenang-api/internal/dto"
+	"github.com/Alfian57/ruang-tenang-api/internal/models"
+	"github.com/Alfian57/ruang-tenang-api/internal/repositories"
+	"github.com/Alfian57/ruang-tenang-api/pkg/safety"
+)
+
+// flagThreshold is the lowest risk level that creates a crisis flag for review
+const flagThreshold = safety.RiskMedium
+
+type CrisisService struct {
+	flagRepo   *repositories.CrisisFlagRepository
+	classifier safety.Classifier
+}
+
+func NewCrisisService(flagRepo *repositories.CrisisFlagRepository, classifier safety.Classifier) *CrisisService {
+	return &CrisisService{
+		flagRepo:   flagRepo,
+		classifier: classifier,
+	}
+}
+
+// Assess screens a user message. Classification errors degrade to RiskNone so
+// screening never blocks a conversation.
+func (s *CrisisService) Assess(ctx context.Context, text string) safety.Assessment {
+	assessment, err := s.classifier.Classify(ctx, text)
+	if err != nil || assessment.Level == "" {
+		return safety.Assessment{Level: safety.RiskNone}
+	}
+	return assessment
+}
+
+// RecordFlag stores a crisis flag for a saved message when its risk warrants review
+func (s *CrisisService) RecordFlag(userID uint, msg *models.ChatMessage, assessment safety.Assessment) error {
+	if !assessment.Level.AtLeast(flagThreshold) {
+		return nil
+	}
+
+	flag := &models.CrisisFlag{
+		UserID:        userID,
+		ChatSessionID: msg.ChatSessionID,
+		ChatMessageID: msg.ID,
+		RiskLevel:     string(assessment.Level),
+		MatchedTerms:  strings.Join(assessment.Matches, ","),
+		Source:        assessment.Source,
+		Status:        models.CrisisFlagOpen,
+	}
+	return s.flagRepo.Create(flag)
+}
+
+func (s *CrisisService) GetFlags(params dto.CrisisFlagQueryParams) ([]dto.CrisisFlagDTO, int64, error) {
+	flags, total, err := s.flagRepo.FindAll(params.Status, params.RiskLevel, params.Page, params.Limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	result := make([]dto.CrisisFlagDTO, len(flags))
+	for i := range flags {
+		result[i] = toCrisisFlagDTO(&flags[i])
+	}
+	return result, total, nil
+}
+
+func (s *CrisisService) GetFlag(id uint) (*dto.CrisisFlagDTO, error) {
+	flag, err := s.flagRepo.FindByID(id)
+	if err != nil {
+		return nil, errors.New("flag not found")
+	}
+	result := toCrisisFlagDTO(flag)
+	return &result, nil
+}
+
+func (s *CrisisService) ReviewFlag(id, reviewerID uint, req *dto.ReviewCrisisFlagRequest) (*dto.CrisisFlagDTO, error) {
+	flag, err := s.flagRepo.FindByID(id)
+	if err != nil {
+		return nil, errors.New("flag not found")
+	}
+
+	now := time.Now()
+	flag.Status = models.CrisisFlagStatus(req.Status)
+	flag.ReviewNotes = req.Notes
+	flag.ReviewedBy = &reviewerID
+	flag.ReviewedAt = &now
+
+	if err := s.flagRepo.Update(flag); err != nil {
+		return nil, errors.New("failed to update flag")
+	}
+
+	result := toCrisisFlagDTO(flag)
+	return &result, nil
+}
+
+func toCrisisFlagDTO(flag *models.CrisisFlag) dto.CrisisFlagDTO {
+	matches := []string{}
+	if flag.MatchedTerms != "" {
+		matches = strings.Split(flag.MatchedTerms, ",")
+	}
+
+	return dto.CrisisFlagDTO{
+		ID:             flag.ID,
+		UserID:         flag.UserID,
+		UserName:       flag.User.Name,
+		UserEmail:      flag.User.Email,
+		ChatSessionID:  flag.ChatSessionID,
+		ChatMessageID:  flag.ChatMessageID,
+		MessageContent: flag.ChatMessage.Content,
+		RiskLevel:      flag.RiskLevel,
+		MatchedTerms:   matches,
+		Source:         flag.Source,
+		Status:         string(flag.Status),
+		ReviewNotes:    flag.ReviewNotes,
+		ReviewedBy:     flag.ReviewedBy,
+		ReviewedAt:     flag.ReviewedAt,
+		CreatedAt:      flag.CreatedAt,
+	}
+}
@@ -0,0 +1,238 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	"sync"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/Alfian57/ruang-tenang-api/internal/models"
+	"github.com/Alfian57/ruang-tenang-api/internal/repositories"
+	"github.com/Alfian57/ruang-tenang-api/pkg/clock"
+	"github.com/Alfian57/ruang-tenang-api/pkg/logger"
+	"github.com/Alfian57/ruang-tenang-api/pkg/mailer"
+	"go.uber.org/zap"
+)
+
+// Email templates, each with a <name>.subject.txt, <name>.txt and <name>.html
+// file per locale
+const (
+	EmailPasswordReset   = "password_reset"
+	EmailPasswordChanged = "password_changed"
+	EmailVerifyEmail     = "verify_email"
+	EmailAccountDeletion = "account_deletion"
+	EmailNotification    = "notification"
+)
+
+const (
+	defaultEmailLocale = "id"
+	emailAppName       = "Ruang Tenang"
+
+	emailBatchSize   = 20
+	emailSendTimeout = 30 * time.Second
+	emailLease       = 5 * time.Minute // longer than a batch can take to send
+	emailMaxAttempts = 6
+	emailRetryBase   = time.Minute // doubles per attempt: 1m, 2m, 4m, 8m, 16m
+)
+
+//go:embed email_templates
+var emailTemplateFS embed.FS
+
+var emailLocales = map[string]bool{"id": true, "en": true}
+
+// RenderedEmail is a template rendered for one recipient
+type RenderedEmail struct {
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// EmailService renders templated emails into the outbox and delivers them
+// from a background job, retrying failed sends with backoff
+type EmailService struct {
+	outboxRepo *repositories.EmailOutboxRepository
+	mailer     mailer.Mailer
+
+	mu        sync.Mutex
+	templates map[string]*emailTemplate // keyed by locale/name
+}
+
+type emailTemplate struct {
+	subject *texttemplate.Template
+	text    *texttemplate.Template
+	html    *htmltemplate.Template
+}
+
+func NewEmailService(outboxRepo *repositories.EmailOutboxRepository, m mailer.Mailer) *EmailService {
+	return &EmailService{
+		outboxRepo: outboxRepo,
+		mailer:     m,
+		templates:  make(map[string]*emailTemplate),
+	}
+}
+
+// Enqueue renders an email and queues it for delivery. The locale falls back
+// to Indonesian when the template isn't translated.
+func (s *EmailService) Enqueue(to, name, locale string, data map[string]interface{}) error {
+	locale = emailLocale(locale)
+
+	rendered, err := s.Render(name, locale, data)
+	if err != nil {
+		return err
+	}
+
+	return s.outboxRepo.Create(&models.EmailOutbox{
+		Recipient:     to,
+		Template:      name,
+		Locale:        locale,
+		Subject:       rendered.Subject,
+		TextBody:      rendered.Text,
+		HTMLBody:      rendered.HTML,
+		Status:        models.EmailPending,
+		NextAttemptAt: time.Now(),
+	})
+}
+
+// Render fills a template with data. AppName and Locale are always available
+// to templates.
+func (s *EmailService) Render(name, locale string, data map[string]interface{}) (*RenderedEmail, error) {
+	locale = emailLocale(locale)
+
+	tmpl, err := s.template(name, locale)
+	if err != nil {
+		return nil, err
+	}
+
+	values := map[string]interface{}{
+		"AppName": emailAppName,
+		"Locale":  locale,
+	}
+	for k, v := range data {
+		values[k] = v
+	}
+
+	var subject, text, html bytes.Buffer
+	if err := tmpl.subject.Execute(&subject, values); err != nil {
+		return nil, fmt.Errorf("render %s subject: %w", name, err)
+	}
+	values["Subject"] = strings.TrimSpace(subject.String())
+
+	if err := tmpl.text.Execute(&text, values); err != nil {
+		return nil, fmt.Errorf("render %s text: %w", name, err)
+	}
+	if err := tmpl.html.ExecuteTemplate(&html, "layout", values); err != nil {
+		return nil, fmt.Errorf("render %s html: %w", name, err)
+	}
+
+	return &RenderedEmail{
+		Subject: values["Subject"].(string),
+		Text:    text.String(),
+		HTML:    html.String(),
+	}, nil
+}
+
+// ProcessQueue sends the emails that are due. Failures are retried with
+// exponential backoff until emailMaxAttempts, then marked failed.
+func (s *EmailService) ProcessQueue(ctx context.Context) error {
+	emails, err := s.outboxRepo.ClaimDue(emailBatchSize, emailLease)
+	if err != nil {
+		return err
+	}
+
+	for i := range emails {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		s.deliver(ctx, &emails[i])
+	}
+
+	return nil
+}
+
+func (s *EmailService) deliver(ctx context.Context, email *models.EmailOutbox) {
+	sendCtx, cancel := context.WithTimeout(ctx, emailSendTimeout)
+	defer cancel()
+
+	attempts := email.Attempts + 1
+	err := s.mailer.Send(sendCtx, mailer.Message{
+		To:      email.Recipient,
+		Subject: email.Subject,
+		Text:    email.TextBody,
+		HTML:    email.HTMLBody,
+	})
+
+	if err == nil {
+		if err := s.outboxRepo.MarkSent(email.ID, attempts); err != nil {
+			logger.Error("Failed to mark email as sent", zap.Uint("email_id", email.ID), zap.Error(err))
+		}
+		return
+	}
+
+	var retryAt *time.Time
+	if attempts < emailMaxAttempts {
+		next := time.Now().Add(emailRetryBase << (attempts - 1))
+		retryAt = &next
+	}
+
+	logger.Warn("Email delivery failed",
+		zap.Uint("email_id", email.ID),
+		zap.String("template", email.Template),
+		zap.String("mailer", s.mailer.Name()),
+		zap.Int("attempt", attempts),
+		zap.Bool("will_retry", retryAt != nil),
+		zap.Error(err),
+	)
+
+	if err := s.outboxRepo.MarkFailed(email.ID, attempts, err.Error(), retryAt); err != nil {
+		logger.Error("Failed to record email failure", zap.Uint("email_id", email.ID), zap.Error(err))
+	}
+}
+
+func (s *EmailService) template(name, locale string) (*emailTemplate, error) {
+	key := locale + "/" + name
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if tmpl, ok := s.templates[key]; ok {
+		return tmpl, nil
+	}
+
+	base := "email_templates/" + key
+	subject, err := texttemplate.ParseFS(emailTemplateFS, base+".subject.txt")
+	if err != nil {
+		return nil, fmt.Errorf("unknown email template %q: %w", key, err)
+	}
+	text, err := texttemplate.ParseFS(emailTemplateFS, base+".txt")
+	if err != nil {
+		return nil, fmt.Errorf("email template %q: %w", key, err)
+	}
+	html, err := htmltemplate.ParseFS(emailTemplateFS, "email_templates/layout.html", base+".html")
+	if err != nil {
+		return nil, fmt.Errorf("email template %q: %w", key, err)
+	}
+
+	tmpl := &emailTemplate{subject: subject, text: text, html: html}
+	s.templates[key] = tmpl
+	return tmpl, nil
+}
+
+// emailLocale maps a requested locale (e.g. an Accept-Language header) to one
+// the templates are translated into
+func emailLocale(locale string) string {
+	locale = NormalizeLocale(locale)
+	if emailLocales[locale] {
+		return locale
+	}
+	return defaultEmailLocale
+}
+
+// emailTime formats a timestamp for email bodies in Indonesian western time
+func emailTime(t time.Time) string {
+	return t.In(clock.Default()).Format("02-01-2006 15:04 MST")
+}
@@ -0,0 +1,123 @@
+package services
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/Alfian57/ruang-tenang-api/internal/models"
+	"github.com/Alfian57/ruang-tenang-api/pkg/logger"
+	"github.com/Alfian57/ruang-tenang-api/pkg/utils"
+	"go.uber.org/zap"
+)
+
+const (
+	// emailVerificationTTL is how long a verification link stays valid
+	emailVerificationTTL = 24 * time.Hour
+	// verificationResendLimit caps resend requests per user per hour
+	verificationResendLimit = 3
+)
+
+// sendVerification emails a link confirming that the user owns email, which
+// is either their current address or the one they are changing to
+func (s *AuthService) sendVerification(user *models.User, email, locale string) error {
+	token, err := utils.GenerateRandomString(32)
+	if err != nil {
+		return err
+	}
+
+	verification := &models.EmailVerification{
+		UserID:    user.ID,
+		Email:     email,
+		TokenHash: utils.HashToken(token),
+		ExpiresAt: time.Now().Add(emailVerificationTTL),
+	}
+	if err := s.verificationRepo.Replace(verification); err != nil {
+		return err
+	}
+
+	return s.emailService.Enqueue(email, EmailVerifyEmail, locale, map[string]interface{}{
+		"Name":           user.Name,
+		"VerifyURL":      s.verifyURL + "?token=" + url.QueryEscape(token),
+		"ExpiresInHours": int(emailVerificationTTL.Hours()),
+		"IsChange":       user.Email != "" && email != user.Email,
+	})
+}
+
+// ConfirmEmail redeems a verification token. For an email change the new
+// address replaces the old one only now.
+func (s *AuthService) ConfirmEmail(token string) (*models.User, error) {
+	verification, err := s.verificationRepo.FindByTokenHash(utils.HashToken(token))
+	if err != nil {
+		return nil, ErrInvalidVerificationToken
+	}
+
+	if s.userRepo.ExistsByEmailExcept(verification.Email, verification.UserID) {
+		return nil, errors.New("email already taken")
+	}
+
+	confirmed, err := s.verificationRepo.Confirm(verification)
+	if err != nil {
+		return nil, errors.New("failed to verify email")
+	}
+	if !confirmed {
+		return nil, ErrInvalidVerificationToken
+	}
+	s.userStates.forget(verification.UserID)
+
+	return s.userRepo.FindByID(verification.UserID)
+}
+
+// ResendVerification emails a fresh link for the pending email change, or
+// for the current address if it isn't verified yet
+func (s *AuthService) ResendVerification(userID uint, locale string) error {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+
+	email := user.Email
+	if pending, err := s.verificationRepo.FindPendingByUserID(userID); err == nil {
+		email = pending.Email
+	}
+	if email == "" {
+		return ErrNoEmail
+	}
+	if email == user.Email && user.IsEmailVerified() {
+		return ErrEmailAlreadyVerified
+	}
+
+	if ok, retryAfter := s.verificationResends.Allow(strconv.FormatUint(uint64(user.ID), 10)); !ok {
+		return &RateLimitError{Err: ErrTooManyVerificationRequests, RetryAfter: retryAfter}
+	}
+
+	if err := s.sendVerification(user, email, locale); err != nil {
+		logger.Error("Failed to queue verification email", zap.Uint("user_id", user.ID), zap.Error(err))
+		return errors.New("failed to send verification email")
+	}
+	return nil
+}
+
+// PendingEmail returns the address the user is changing to, if they
+// requested a change that isn't confirmed yet
+func (s *AuthService) PendingEmail(user *models.User) string {
+	pending, err := s.verificationRepo.FindPendingByUserID(user.ID)
+	if err != nil || pending.Email == user.Email {
+		return ""
+	}
+	return pending.Email
+}
+
+// RequireVerifiedEmail is an access policy for community features such as
+// posting in forums or writing articles
+func (s *AuthService) RequireVerifiedEmail(userID uint) error {
+	state, err := s.loadUserState(userID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+	if !state.verified {
+		return ErrEmailNotVerified
+	}
+	return nil
+}
@@ -1,7 +1,106 @@
 package services
 
-import "errors"
+import (
+	"errors"
+	"time"
+)
 
 var (
 	ErrLevelExists = errors.New("level already exists")
+
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	ErrSessionRevoked      = &CodedError{Code: "session_revoked", Message: "session has been signed out"}
+	ErrTokenRevoked        = &CodedError{Code: "token_revoked", Message: "token is no longer valid, please sign in again"}
+	ErrAccountBlocked      = &CodedError{Code: "account_blocked", Message: "your account has been blocked"}
+	ErrSessionNotFound     = errors.New("session not found")
+
+	ErrTooManyResetRequests = &CodedError{Code: "too_many_requests", Message: "too many password reset requests, please try again later"}
+
+	ErrEmailNotVerified            = &CodedError{Code: "email_not_verified", Message: "please verify your email address first"}
+	ErrEmailAlreadyVerified        = errors.New("email is already verified")
+	ErrInvalidVerificationToken    = errors.New("invalid or expired verification link")
+	ErrTooManyVerificationRequests = &CodedError{Code: "too_many_requests", Message: "too many verification emails requested, please try again later"}
+
+	ErrInvalidOAuthState     = errors.New("invalid or expired sign-in attempt, please start again")
+	ErrSocialLoginFailed     = errors.New("could not verify the sign-in with the provider")
+	ErrProviderUnavailable   = errors.New("sign-in provider is unavailable, please try again later")
+	ErrSocialEmailUnverified = &CodedError{Code: "email_not_verified", Message: "the provider account has no verified email address"}
+	ErrSocialAccountExists   = &CodedError{Code: "account_exists", Message: "an account with this email already exists; sign in with your password and link the provider from your profile"}
+	ErrIdentityLinked        = errors.New("this provider account is already linked to another user")
+	ErrProviderAlreadyLinked = errors.New("a different account of this provider is already linked")
+	ErrIdentityNotFound      = errors.New("provider is not linked")
+	ErrLastSignInMethod      = errors.New("cannot unlink your only sign-in method; set a password first")
+
+	ErrInvalidTwoFactorChallenge = errors.New("sign-in expired, please enter your password again")
+	ErrInvalidTwoFactorCode      = errors.New("invalid authentication code")
+	ErrTwoFactorEnabled          = errors.New("two-factor authentication is already enabled")
+	ErrTwoFactorNotEnabled       = errors.New("two-factor authentication is not enabled")
+	ErrTwoFactorNotSetUp         = errors.New("start two-factor setup first")
+	ErrTwoFactorMandatory        = errors.New("two-factor authentication is required for your account")
+	ErrTwoFactorSetupRequired    = &CodedError{Code: "two_factor_required", Message: "enable two-factor authentication to use the admin panel"}
+	ErrTooManyTwoFactorAttempts  = &CodedError{Code: "too_many_requests", Message: "too many authentication attempts, please try again later"}
+
+	ErrAccountLocked        = &CodedError{Code: "account_locked", Message: "too many failed sign-in attempts, the account is temporarily locked"}
+	ErrTooManyLoginAttempts = &CodedError{Code: "too_many_requests", Message: "too many failed sign-in attempts, please wait before trying again"}
+
+	ErrInvalidPseudonym     = errors.New("pseudonym must be 3-30 letters, numbers, dots, dashes or underscores")
+	ErrPseudonymTaken       = errors.New("pseudonym already taken")
+	ErrPseudonymRequired    = errors.New("anonymous accounts must keep a pseudonym")
+	ErrNotAnonymous         = errors.New("account already has an email address")
+	ErrNoEmail              = errors.New("add an email address by upgrading your account first")
+	ErrTooManyRegistrations = &CodedError{Code: "too_many_requests", Message: "too many accounts created from this network, please try again later"}
+
+	ErrIncorrectPassword     = errors.New("password is incorrect")
+	ErrDeletionScheduled     = errors.New("account deletion is already scheduled")
+	ErrDeletionNotScheduled  = errors.New("account deletion is not scheduled")
+	ErrTooManyExportRequests = &CodedError{Code: "too_many_requests", Message: "too many data exports requested, please try again later"}
+
+	ErrPromptNotFound = errors.New("prompt not found")
+	ErrPromptInUse    = errors.New("prompt version has already been used; create a new version instead")
+	ErrPromptActive   = errors.New("cannot delete an active prompt version")
+
+	ErrMoodNotFound   = errors.New("mood entry not found")
+	ErrInvalidMoodTag = errors.New("tags must be 1-30 letters, numbers, spaces, dashes or underscores")
+
+	ErrInvalidReminderTime      = errors.New("reminder times and quiet hours must be HH:MM")
+	ErrInvalidTimezone          = errors.New("unknown timezone")
+	ErrReminderIncomplete       = errors.New("enabled reminders need at least one time, day and channel")
+	ErrChannelUnavailable       = errors.New("notification channel is not available")
+	ErrNotificationNotFound     = errors.New("notification not found")
+	ErrPushUnavailable          = errors.New("push notifications are not configured")
+	ErrInvalidPushSubscription  = errors.New("invalid push subscription")
+	ErrPushSubscriptionNotFound = errors.New("push subscription not found")
+
+	ErrAudioNotFound       = errors.New("audio file not found; upload it via /upload/audio first")
+	ErrTranscriptionFailed = errors.New("failed to transcribe audio message")
 )
+
+// CodedError carries a machine-readable code that handlers return alongside
+// the message, so clients can react without parsing text
+type CodedError struct {
+	Code    string
+	Message string
+}
+
+func (e *CodedError) Error() string {
+	return e.Message
+}
+
+func (e *CodedError) ErrorCode() string {
+	return e.Code
+}
+
+// RateLimitError wraps a coded error with how long the client should wait
+// before retrying
+type RateLimitError struct {
+	Err        *CodedError
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RateLimitError) Unwrap() error {
+	return e.Err
+}
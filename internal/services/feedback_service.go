@@ -0,0 +1,152 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Alfian57/ruang-tenang-api/internal/dto"
+	"github.com/Alfian57/ruang-tenang-api/internal/models"
+	"github.com/Alfian57/ruang-tenang-api/internal/repositories"
+	"github.com/Alfian57/ruang-tenang-api/pkg/clock"
+)
+
+const (
+	defaultFeedbackDays = 30
+	maxFeedbackDays     = 365
+)
+
+type FeedbackService struct {
+	feedbackRepo *repositories.ChatFeedbackRepository
+	promptRepo   *repositories.SystemPromptRepository
+}
+
+func NewFeedbackService(feedbackRepo *repositories.ChatFeedbackRepository, promptRepo *repositories.SystemPromptRepository) *FeedbackService {
+	return &FeedbackService{
+		feedbackRepo: feedbackRepo,
+		promptRepo:   promptRepo,
+	}
+}
+
+// Record stores a rating event for an AI message, copying the prompt version
+// and model that produced it
+func (s *FeedbackService) Record(msg *models.ChatMessage, userID uint, rating models.FeedbackRating, reason string) error {
+	return s.feedbackRepo.Record(&models.ChatFeedback{
+		ChatMessageID:  msg.ID,
+		UserID:         userID,
+		Rating:         rating,
+		Reason:         reason,
+		SystemPromptID: msg.SystemPromptID,
+		Provider:       msg.Provider,
+		Model:          msg.Model,
+	})
+}
+
+func (s *FeedbackService) GetFeedback(params dto.FeedbackQueryParams) ([]dto.ChatFeedbackDTO, int64, error) {
+	since := feedbackSince(params.Days)
+	feedback, total, err := s.feedbackRepo.FindAll(params.Rating, since, params.Page, params.Limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	result := make([]dto.ChatFeedbackDTO, len(feedback))
+	for i, f := range feedback {
+		result[i] = dto.ChatFeedbackDTO{
+			ID:             f.ID,
+			ChatMessageID:  f.ChatMessageID,
+			UserID:         f.UserID,
+			Rating:         string(f.Rating),
+			Reason:         f.Reason,
+			MessageContent: f.ChatMessage.Content,
+			SystemPromptID: f.SystemPromptID,
+			Provider:       f.Provider,
+			Model:          f.Model,
+			CreatedAt:      f.CreatedAt,
+		}
+	}
+	return result, total, nil
+}
+
+// GetAnalytics aggregates satisfaction over the last days by day, prompt
+// version and model
+func (s *FeedbackService) GetAnalytics(days int) (*dto.FeedbackAnalyticsDTO, error) {
+	if days < 1 || days > maxFeedbackDays {
+		days = defaultFeedbackDays
+	}
+	since := feedbackSince(days)
+	// Feedback comes from many users, so days follow the app's default timezone
+	loc := clock.Default()
+
+	byDay, err := s.feedbackRepo.SatisfactionBy(repositories.FeedbackGroupDay, since, loc)
+	if err != nil {
+		return nil, err
+	}
+	byPrompt, err := s.feedbackRepo.SatisfactionBy(repositories.FeedbackGroupPrompt, since, loc)
+	if err != nil {
+		return nil, err
+	}
+	byModel, err := s.feedbackRepo.SatisfactionBy(repositories.FeedbackGroupModel, since, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &dto.FeedbackAnalyticsDTO{
+		Days:     days,
+		ByDay:    toFeedbackBuckets(byDay, nil),
+		ByPrompt: toFeedbackBuckets(byPrompt, s.promptLabels()),
+		ByModel:  toFeedbackBuckets(byModel, nil),
+	}
+
+	var overall repositories.FeedbackAggregate
+	for _, agg := range byDay {
+		overall.Likes += agg.Likes
+		overall.Dislikes += agg.Dislikes
+	}
+	result.Overall = toFeedbackBucket(overall, "")
+
+	return result, nil
+}
+
+// promptLabels maps prompt IDs to a readable "name/locale vN" label
+func (s *FeedbackService) promptLabels() map[string]string {
+	labels := map[string]string{"": "file prompt"}
+	prompts, err := s.promptRepo.FindAll("", "")
+	if err != nil {
+		return labels
+	}
+	for _, p := range prompts {
+		labels[strconv.FormatUint(uint64(p.ID), 10)] = fmt.Sprintf("%s/%s v%d", p.Name, p.Locale, p.Version)
+	}
+	return labels
+}
+
+func feedbackSince(days int) time.Time {
+	if days < 1 || days > maxFeedbackDays {
+		days = defaultFeedbackDays
+	}
+	return time.Now().AddDate(0, 0, -days)
+}
+
+func toFeedbackBuckets(aggs []repositories.FeedbackAggregate, labels map[string]string) []dto.FeedbackBucketDTO {
+	result := make([]dto.FeedbackBucketDTO, len(aggs))
+	for i, agg := range aggs {
+		result[i] = toFeedbackBucket(agg, labels[agg.Key])
+	}
+	return result
+}
+
+func toFeedbackBucket(agg repositories.FeedbackAggregate, label string) dto.FeedbackBucketDTO {
+	total := agg.Likes + agg.Dislikes
+	var rate float64
+	if total > 0 {
+		rate = float64(agg.Likes) / float64(total) * 100
+	}
+	return dto.FeedbackBucketDTO{
+		Key:              agg.Key,
+		Label:            label,
+		Likes:            agg.Likes,
+		Dislikes:         agg.Dislikes,
+		Total:            total,
+		SatisfactionRate: rate,
+	}
+}
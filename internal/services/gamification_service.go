@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/Alfian57/ruang-tenang-api/internal/models"
+	"github.com/Alfian57/ruang-tenang-api/pkg/clock"
 	"github.com/Alfian57/ruang-tenang-api/pkg/gamification"
 	"gorm.io/gorm"
 )
@@ -17,11 +18,17 @@ func NewGamificationService(db *gorm.DB) *GamificationService {
 }
 
 // AwardExp adds EXP to a user if the daily limit for the activity hasn't been reached.
+// Days are counted in the user's timezone.
 func (s *GamificationService) AwardExp(userID uint, activityType gamification.ActivityType, points int64) error {
 	return s.db.Transaction(func(tx *gorm.DB) error {
 		// 1. Check daily limit if applicable
 		if limit := getDailyLimit(activityType); limit > 0 {
-			today := time.Now().Truncate(24 * time.Hour)
+			var user models.User
+			if err := tx.Select("id", "timezone").First(&user, userID).Error; err != nil {
+				return err
+			}
+			today := clock.Date(time.Now(), clock.Location(user.Timezone))
+
 			var count int64
 			err := tx.Model(&models.UserActivity{}).
 				Where("user_id = ? AND activity_type = ? AND date = ?", userID, activityType, today).
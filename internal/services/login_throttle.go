@@ -0,0 +1,172 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Alfian57/ruang-tenang-api/internal/config"
+	"github.com/Alfian57/ruang-tenang-api/internal/models"
+	"github.com/Alfian57/ruang-tenang-api/pkg/lockout"
+	"github.com/Alfian57/ruang-tenang-api/pkg/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	// Wrong passwords for one account start adding a doubling delay after
+	// the third, before the account locks at LOGIN_MAX_FAILURES
+	loginDelayAfter = 3
+	// Shared networks make many users sign in from one IP, so IP delays
+	// start later
+	loginIPDelayAfter = 10
+	loginBaseDelay    = time.Second
+	loginMaxDelay     = 30 * time.Second
+)
+
+// loginKey identifies an account for throttling by the email or pseudonym
+// it signs in with. Unknown ones are tracked too so lockouts don't reveal
+// which accounts exist.
+func loginKey(identifier string) string {
+	return strings.ToLower(strings.TrimSpace(identifier))
+}
+
+// accountLoginName is what the user signs in with
+func accountLoginName(user *models.User) string {
+	if user.IsAnonymous {
+		return user.PseudonymOrEmpty()
+	}
+	return user.Email
+}
+
+// checkLoginThrottle rejects sign-ins from a locked account or IP, or made
+// before the delay since the last failure has passed
+func (s *AuthService) checkLoginThrottle(email, ip string) error {
+	if retryAfter, _, err := s.loginByIP.Check(ip); err != nil {
+		logger.Error("Failed to check login throttle", zap.Error(err))
+	} else if retryAfter > 0 {
+		return &RateLimitError{Err: ErrTooManyLoginAttempts, RetryAfter: retryAfter}
+	}
+
+	retryAfter, locked, err := s.loginByAccount.Check(loginKey(email))
+	if err != nil {
+		// Fail open: a store outage shouldn't stop everyone from signing in
+		logger.Error("Failed to check login throttle", zap.Error(err))
+		return nil
+	}
+	if locked {
+		return &RateLimitError{Err: ErrAccountLocked, RetryAfter: retryAfter}
+	}
+	if retryAfter > 0 {
+		return &RateLimitError{Err: ErrTooManyLoginAttempts, RetryAfter: retryAfter}
+	}
+	return nil
+}
+
+// loginFailed counts a wrong password and returns the error for the
+// response. user is nil when no account has the email.
+func (s *AuthService) loginFailed(email string, user *models.User, ip string) error {
+	var lockErr error
+
+	if until, failures, err := s.loginByIP.Fail(ip); err != nil {
+		logger.Error("Failed to record login failure", zap.Error(err))
+	} else if !until.IsZero() {
+		s.recordLockout(models.LockoutScopeIP, email, user, ip, failures, until)
+		lockErr = &RateLimitError{Err: ErrTooManyLoginAttempts, RetryAfter: time.Until(until)}
+	}
+
+	if until, failures, err := s.loginByAccount.Fail(loginKey(email)); err != nil {
+		logger.Error("Failed to record login failure", zap.Error(err))
+	} else if !until.IsZero() {
+		s.recordLockout(models.LockoutScopeAccount, email, user, ip, failures, until)
+		lockErr = &RateLimitError{Err: ErrAccountLocked, RetryAfter: time.Until(until)}
+	}
+
+	if lockErr != nil {
+		return lockErr
+	}
+	return errors.New("invalid email or password")
+}
+
+// loginSucceeded clears the account's failures. The IP keeps its count so
+// signing in to one account doesn't reset guessing at others.
+func (s *AuthService) loginSucceeded(email string) {
+	if err := s.loginByAccount.Reset(loginKey(email)); err != nil {
+		logger.Error("Failed to reset login failures", zap.Error(err))
+	}
+}
+
+func (s *AuthService) recordLockout(scope models.LockoutScope, email string, user *models.User, ip string, failures int, until time.Time) {
+	record := &models.LoginLockout{
+		Scope:       scope,
+		Email:       loginKey(email),
+		IPAddress:   ip,
+		Failures:    failures,
+		LockedUntil: until,
+	}
+	if user != nil {
+		record.UserID = &user.ID
+	}
+
+	logger.Warn("Sign-in locked after repeated failures",
+		zap.String("scope", string(scope)),
+		zap.String("email", record.Email),
+		zap.String("ip", ip),
+		zap.Time("locked_until", until),
+	)
+	if err := s.lockoutRepo.Create(record); err != nil {
+		logger.Error("Failed to record login lockout", zap.Error(err))
+	}
+}
+
+// UnlockAccount lets an admin end a user's account lockout early
+func (s *AuthService) UnlockAccount(userID, adminID uint) error {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+
+	if err := s.loginByAccount.Reset(loginKey(accountLoginName(user))); err != nil {
+		return errors.New("failed to unlock account")
+	}
+	if err := s.lockoutRepo.MarkUnlocked(userID, adminID); err != nil {
+		logger.Error("Failed to record account unlock", zap.Uint("user_id", userID), zap.Error(err))
+	}
+
+	logger.Info("Account unlocked", zap.Uint("user_id", userID), zap.Uint("admin_id", adminID))
+	return nil
+}
+
+// GetLockouts lists recorded lockouts, optionally for one user
+func (s *AuthService) GetLockouts(userID uint, page, limit int) ([]models.LoginLockout, int64, error) {
+	return s.lockoutRepo.FindAll(userID, page, limit)
+}
+
+// PruneLoginAttempts forgets failure counters that have run out
+func (s *AuthService) PruneLoginAttempts() (int64, error) {
+	byAccount, err := s.loginByAccount.Prune()
+	if err != nil {
+		return byAccount, err
+	}
+	byIP, err := s.loginByIP.Prune()
+	return byAccount + byIP, err
+}
+
+func newLoginGuards(store lockout.Store, cfg *config.Config) (byAccount, byIP *lockout.Guard) {
+	byAccount = lockout.New(store, "account", lockout.Policy{
+		MaxFailures:  cfg.LoginMaxFailures,
+		Window:       cfg.LoginFailureWindow,
+		LockDuration: cfg.LoginLockoutDuration,
+		DelayAfter:   loginDelayAfter,
+		BaseDelay:    loginBaseDelay,
+		MaxDelay:     loginMaxDelay,
+	})
+	byIP = lockout.New(store, "ip", lockout.Policy{
+		MaxFailures:  cfg.LoginIPMaxFailures,
+		Window:       cfg.LoginFailureWindow,
+		LockDuration: cfg.LoginLockoutDuration,
+		DelayAfter:   loginIPDelayAfter,
+		BaseDelay:    loginBaseDelay,
+		MaxDelay:     loginMaxDelay,
+	})
+	return byAccount, byIP
+}
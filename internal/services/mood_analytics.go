@@ -0,0 +1,308 @@
+package services
+
+import (
+	"math"
+	"time"
+
+	"github.com/Alfian57/ruang-tenang-api/internal/dto"
+	"github.com/Alfian57/ruang-tenang-api/internal/models"
+	"github.com/Alfian57/ruang-tenang-api/pkg/clock"
+)
+
+const (
+	defaultAnalyticsDays = 30
+	minAnalyticsDays     = 7
+	maxAnalyticsDays     = 365
+	rollingWindowDays    = 7
+
+	dateLayout = "2006-01-02"
+)
+
+// moodOrder lists moods from most positive to most negative; it also breaks
+// ties when picking a day's dominant mood
+var moodOrder = []models.MoodType{
+	models.MoodHappy,
+	models.MoodNeutral,
+	models.MoodDisappointed,
+	models.MoodAngry,
+	models.MoodSad,
+	models.MoodCrying,
+}
+
+var weekdayKeys = []string{"monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday"}
+
+// Parts of the day by local hour: night 22-05, morning 05-11, afternoon
+// 11-17, evening 17-22
+var timeOfDayKeys = []string{"morning", "afternoon", "evening", "night"}
+
+func timeOfDay(hour int) string {
+	switch {
+	case hour >= 5 && hour < 11:
+		return "morning"
+	case hour >= 11 && hour < 17:
+		return "afternoon"
+	case hour >= 17 && hour < 22:
+		return "evening"
+	default:
+		return "night"
+	}
+}
+
+// valenceSum accumulates mood valences for averaging
+type valenceSum struct {
+	sum   int
+	count int
+}
+
+func (v *valenceSum) add(mood models.MoodType) {
+	v.sum += mood.Valence()
+	v.count++
+}
+
+func (v *valenceSum) merge(other valenceSum) {
+	v.sum += other.sum
+	v.count += other.count
+}
+
+// average is rounded to two decimals, or nil without check-ins
+func (v valenceSum) average() *float64 {
+	if v.count == 0 {
+		return nil
+	}
+	avg := math.Round(float64(v.sum)/float64(v.count)*100) / 100
+	return &avg
+}
+
+type moodDay struct {
+	valenceSum
+	moods map[models.MoodType]int
+}
+
+func (d *moodDay) dominantMood() string {
+	best, bestCount := models.MoodType(""), 0
+	for _, mood := range moodOrder {
+		if d.moods[mood] > bestCount {
+			best, bestCount = mood, d.moods[mood]
+		}
+	}
+	return string(best)
+}
+
+// GetMoodAnalytics summarizes the user's check-ins over the last days for
+// the mood dashboard: a calendar with rolling averages, weekly and monthly
+// aggregates, logging streaks, week-over-week change and weekday and
+// time-of-day patterns
+func (s *MoodService) GetMoodAnalytics(userID uint, days int) (*dto.MoodAnalyticsDTO, error) {
+	if days <= 0 {
+		days = defaultAnalyticsDays
+	}
+	if days < minAnalyticsDays {
+		days = minAnalyticsDays
+	}
+	if days > maxAnalyticsDays {
+		days = maxAnalyticsDays
+	}
+
+	loc, err := s.userLocation(userID)
+	if err != nil {
+		return nil, err
+	}
+	today := clock.Today(loc)
+	from := today.AddDate(0, 0, -(days - 1))
+
+	// Rolling averages and the week-over-week comparison look further back
+	// than the calendar
+	since := from.AddDate(0, 0, -(rollingWindowDays - 1))
+	if earliest := today.AddDate(0, 0, -(2*rollingWindowDays - 1)); earliest.Before(since) {
+		since = earliest
+	}
+
+	points, err := s.moodRepo.FindPointsSince(userID, since)
+	if err != nil {
+		return nil, err
+	}
+	checkInDays, err := s.moodRepo.FindCheckInDays(userID, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &dto.MoodAnalyticsDTO{
+		From:         from.Format(dateLayout),
+		To:           today.Format(dateLayout),
+		Timezone:     loc.String(),
+		ValenceScale: make(map[string]int, len(moodOrder)),
+		MoodCounts:   make(map[string]int, len(moodOrder)),
+		Streak:       moodStreak(checkInDays, today, loc),
+	}
+	for _, mood := range moodOrder {
+		result.ValenceScale[string(mood)] = mood.Valence()
+		result.MoodCounts[string(mood)] = 0
+	}
+
+	byDay := make(map[string]*moodDay)
+	var total valenceSum
+	weekdays := make([]valenceSum, len(weekdayKeys))
+	parts := make(map[string]*valenceSum, len(timeOfDayKeys))
+	for _, key := range timeOfDayKeys {
+		parts[key] = &valenceSum{}
+	}
+
+	for _, p := range points {
+		local := p.CreatedAt.In(loc)
+		key := local.Format(dateLayout)
+		day, ok := byDay[key]
+		if !ok {
+			day = &moodDay{moods: make(map[models.MoodType]int)}
+			byDay[key] = day
+		}
+		day.add(p.Mood)
+		day.moods[p.Mood]++
+
+		if local.Before(from) {
+			continue
+		}
+		total.add(p.Mood)
+		result.MoodCounts[string(p.Mood)]++
+		weekdays[(int(local.Weekday())+6)%7].add(p.Mood)
+		parts[timeOfDay(local.Hour())].add(p.Mood)
+	}
+
+	result.TotalCheckIns = total.count
+	result.AverageValence = total.average()
+	result.WeekOverWeek = weekOverWeek(byDay, today)
+
+	result.Calendar = make([]dto.MoodDayDTO, 0, days)
+	var week, month *dto.MoodPeriodDTO
+	var weekSum, monthSum valenceSum
+	for d := from; !d.After(today); d = d.AddDate(0, 0, 1) {
+		key := d.Format(dateLayout)
+		entry := dto.MoodDayDTO{
+			Date:           key,
+			RollingAverage: rollingAverage(byDay, d),
+		}
+		day := byDay[key]
+		if day != nil {
+			entry.CheckIns = day.count
+			entry.AverageValence = day.average()
+			entry.DominantMood = day.dominantMood()
+			result.DaysLogged++
+		}
+		result.Calendar = append(result.Calendar, entry)
+
+		weekStart := d.AddDate(0, 0, -((int(d.Weekday()) + 6) % 7)).Format(dateLayout)
+		if week == nil || week.Start != weekStart {
+			if week != nil {
+				week.AverageValence = weekSum.average()
+				result.Weekly = append(result.Weekly, *week)
+			}
+			week, weekSum = &dto.MoodPeriodDTO{Start: weekStart}, valenceSum{}
+		}
+		monthStart := time.Date(d.Year(), d.Month(), 1, 0, 0, 0, 0, loc).Format(dateLayout)
+		if month == nil || month.Start != monthStart {
+			if month != nil {
+				month.AverageValence = monthSum.average()
+				result.Monthly = append(result.Monthly, *month)
+			}
+			month, monthSum = &dto.MoodPeriodDTO{Start: monthStart}, valenceSum{}
+		}
+		if day != nil {
+			week.CheckIns += day.count
+			week.DaysLogged++
+			weekSum.merge(day.valenceSum)
+			month.CheckIns += day.count
+			month.DaysLogged++
+			monthSum.merge(day.valenceSum)
+		}
+	}
+	week.AverageValence = weekSum.average()
+	result.Weekly = append(result.Weekly, *week)
+	month.AverageValence = monthSum.average()
+	result.Monthly = append(result.Monthly, *month)
+
+	result.DayOfWeek = make([]dto.MoodPatternDTO, len(weekdayKeys))
+	for i, key := range weekdayKeys {
+		result.DayOfWeek[i] = dto.MoodPatternDTO{Key: key, CheckIns: weekdays[i].count, AverageValence: weekdays[i].average()}
+	}
+	result.TimeOfDay = make([]dto.MoodPatternDTO, len(timeOfDayKeys))
+	for i, key := range timeOfDayKeys {
+		result.TimeOfDay[i] = dto.MoodPatternDTO{Key: key, CheckIns: parts[key].count, AverageValence: parts[key].average()}
+	}
+
+	return result, nil
+}
+
+// rollingAverage averages every check-in in the 7 days ending on day
+func rollingAverage(byDay map[string]*moodDay, day time.Time) *float64 {
+	var sum valenceSum
+	for i := 0; i < rollingWindowDays; i++ {
+		if d := byDay[day.AddDate(0, 0, -i).Format(dateLayout)]; d != nil {
+			sum.merge(d.valenceSum)
+		}
+	}
+	return sum.average()
+}
+
+// weekOverWeek compares the 7 days ending today with the 7 days before
+func weekOverWeek(byDay map[string]*moodDay, today time.Time) dto.MoodChangeDTO {
+	var current, previous valenceSum
+	for i := 0; i < 2*rollingWindowDays; i++ {
+		d := byDay[today.AddDate(0, 0, -i).Format(dateLayout)]
+		if d == nil {
+			continue
+		}
+		if i < rollingWindowDays {
+			current.merge(d.valenceSum)
+		} else {
+			previous.merge(d.valenceSum)
+		}
+	}
+
+	change := dto.MoodChangeDTO{
+		CurrentAverage:   current.average(),
+		PreviousAverage:  previous.average(),
+		CurrentCheckIns:  current.count,
+		PreviousCheckIns: previous.count,
+	}
+	if change.CurrentAverage != nil && change.PreviousAverage != nil {
+		diff := math.Round((*change.CurrentAverage-*change.PreviousAverage)*100) / 100
+		change.Change = &diff
+	}
+	return change
+}
+
+// moodStreak counts runs of consecutive logged days from the distinct
+// check-in dates sorted newest first. Today not being logged yet doesn't
+// break the current streak.
+func moodStreak(checkInDays []time.Time, today time.Time, loc *time.Location) dto.MoodStreakDTO {
+	var streak dto.MoodStreakDTO
+	if len(checkInDays) == 0 {
+		return streak
+	}
+
+	// Dates are read as midnight UTC; compare them as days in loc
+	days := make([]time.Time, len(checkInDays))
+	for i, d := range checkInDays {
+		days[i] = time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, loc)
+	}
+	streak.LastLoggedOn = days[0].Format(dateLayout)
+
+	run := 0
+	for i, day := range days {
+		if i > 0 && days[i-1].AddDate(0, 0, -1).Equal(day) {
+			run++
+		} else {
+			run = 1
+		}
+		if run > streak.Longest {
+			streak.Longest = run
+		}
+		if run == i+1 {
+			streak.Current = run
+		}
+	}
+
+	if days[0].Before(today.AddDate(0, 0, -1)) {
+		streak.Current = 0
+	}
+	return streak
+}
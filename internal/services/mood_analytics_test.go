@@ -0,0 +1,68 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Alfian57/ruang-tenang-api/internal/dto"
+	"github.com/Alfian57/ruang-tenang-api/pkg/clock"
+)
+
+func TestMoodStreak(t *testing.T) {
+	loc := clock.Location("Asia/Jayapura")
+	today := time.Date(2026, 3, 10, 0, 0, 0, 0, loc)
+
+	// dates builds check-in days as the repository returns them
+	dates := func(days ...string) []time.Time {
+		var result []time.Time
+		for _, day := range days {
+			d, err := time.Parse(dateLayout, day)
+			if err != nil {
+				t.Fatal(err)
+			}
+			result = append(result, d)
+		}
+		return result
+	}
+
+	tests := []struct {
+		name string
+		days []time.Time
+		want dto.MoodStreakDTO
+	}{
+		{name: "never logged", want: dto.MoodStreakDTO{}},
+		{
+			name: "logged today",
+			days: dates("2026-03-10", "2026-03-09", "2026-03-08"),
+			want: dto.MoodStreakDTO{Current: 3, Longest: 3, LastLoggedOn: "2026-03-10"},
+		},
+		{
+			name: "not yet today",
+			days: dates("2026-03-09", "2026-03-08"),
+			want: dto.MoodStreakDTO{Current: 2, Longest: 2, LastLoggedOn: "2026-03-09"},
+		},
+		{
+			name: "broken streak",
+			days: dates("2026-03-07", "2026-03-06"),
+			want: dto.MoodStreakDTO{Current: 0, Longest: 2, LastLoggedOn: "2026-03-07"},
+		},
+		{
+			name: "longest in the past",
+			days: dates("2026-03-10", "2026-03-05", "2026-03-04", "2026-03-03", "2026-03-01"),
+			want: dto.MoodStreakDTO{Current: 1, Longest: 3, LastLoggedOn: "2026-03-10"},
+		},
+		{
+			name: "across a month end",
+			days: dates("2026-03-01", "2026-02-28", "2026-02-27"),
+			want: dto.MoodStreakDTO{Current: 0, Longest: 3, LastLoggedOn: "2026-03-01"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := moodStreak(tt.days, today, loc); got != tt.want {
+				t.Errorf("moodStreak = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
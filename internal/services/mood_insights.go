@@ -0,0 +1,285 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/Alfian57/ruang-tenang-api/internal/dto"
+	"github.com/Alfian57/ruang-tenang-api/internal/models"
+	"github.com/Alfian57/ruang-tenang-api/pkg/clock"
+	"github.com/Alfian57/ruang-tenang-api/pkg/gamification"
+)
+
+const (
+	defaultInsightDays = 90
+	minInsightDays     = 14
+
+	minInsightSamples    = 3   // mood days needed on each side of a comparison
+	minInsightDifference = 0.3 // valence difference worth telling the user about
+	maxInsights          = 5
+	defaultInsightLocale = "id"
+
+	activityReadArticle = "read_article"
+	activityListenSong  = "listen_song"
+
+	lagSameDay = "same_day"
+	lagNextDay = "next_day"
+)
+
+// insightPhrases describe activities for statements, per locale. Song
+// phrases take the category name.
+var insightPhrases = map[string]map[string]string{
+	"id": {
+		string(gamification.ActivityChatAI):        "mengobrol dengan AI",
+		string(gamification.ActivityForumComment):  "berdiskusi di forum",
+		string(gamification.ActivityUploadArticle): "menulis artikel",
+		activityReadArticle:                        "membaca artikel",
+		activityListenSong:                         "mendengarkan lagu %s",
+	},
+	"en": {
+		string(gamification.ActivityChatAI):        "chat with the AI",
+		string(gamification.ActivityForumComment):  "take part in the forum",
+		string(gamification.ActivityUploadArticle): "write an article",
+		activityReadArticle:                        "read articles",
+		activityListenSong:                         "listen to %s songs",
+	},
+}
+
+// insightStatements are indexed by lag, then direction
+var insightStatements = map[string]map[string]map[string]string{
+	"id": {
+		lagSameDay: {"better": "Mood kamu cenderung lebih baik pada hari kamu %s.", "worse": "Mood kamu cenderung lebih buruk pada hari kamu %s."},
+		lagNextDay: {"better": "Mood kamu cenderung lebih baik sehari setelah kamu %s.", "worse": "Mood kamu cenderung lebih buruk sehari setelah kamu %s."},
+	},
+	"en": {
+		lagSameDay: {"better": "Your mood tends to be better on days you %s.", "worse": "Your mood tends to be worse on days you %s."},
+		lagNextDay: {"better": "Your mood tends to be better the day after you %s.", "worse": "Your mood tends to be worse the day after you %s."},
+	},
+}
+
+// moodFeature is an activity and the local days the user did it
+type moodFeature struct {
+	activity   string
+	categoryID *uint
+	label      string
+	days       map[string]bool
+}
+
+// meanSum averages per-day values so busy days don't outweigh quiet ones
+type meanSum struct {
+	sum   float64
+	count int
+}
+
+func (m *meanSum) add(v float64) {
+	m.sum += v
+	m.count++
+}
+
+func (m meanSum) average() *float64 {
+	if m.count < minInsightSamples {
+		return nil
+	}
+	avg := math.Round(m.sum/float64(m.count)*100) / 100
+	return &avg
+}
+
+// GetMoodInsights correlates the user's daily mood with chatting with the
+// AI, forum participation, writing and reading articles and listening to
+// each song category, on the same day and the day after
+func (s *MoodService) GetMoodInsights(userID uint, days int, locale string) (*dto.MoodInsightsDTO, error) {
+	if days <= 0 {
+		days = defaultInsightDays
+	}
+	if days < minInsightDays {
+		days = minInsightDays
+	}
+	if days > maxAnalyticsDays {
+		days = maxAnalyticsDays
+	}
+
+	loc, err := s.userLocation(userID)
+	if err != nil {
+		return nil, err
+	}
+	today := clock.Today(loc)
+	from := today.AddDate(0, 0, -(days - 1))
+
+	points, err := s.moodRepo.FindPointsSince(userID, from)
+	if err != nil {
+		return nil, err
+	}
+	// One extra day so the first mood day has a previous day
+	features, err := s.moodFeatures(userID, from.AddDate(0, 0, -1), loc)
+	if err != nil {
+		return nil, err
+	}
+
+	byDay := make(map[string]*valenceSum)
+	for _, p := range points {
+		key := p.CreatedAt.In(loc).Format(dateLayout)
+		if byDay[key] == nil {
+			byDay[key] = &valenceSum{}
+		}
+		byDay[key].add(p.Mood)
+	}
+	dayMeans := make(map[string]float64, len(byDay))
+	for key, sum := range byDay {
+		dayMeans[key] = float64(sum.sum) / float64(sum.count)
+	}
+
+	result := &dto.MoodInsightsDTO{
+		From:         from.Format(dateLayout),
+		To:           today.Format(dateLayout),
+		DaysWithMood: len(dayMeans),
+		Insights:     []dto.MoodInsightDTO{},
+		Correlations: []dto.MoodCorrelationDTO{},
+	}
+
+	best := make(map[*moodFeature]dto.MoodCorrelationDTO)
+	for _, f := range features {
+		for _, lag := range []string{lagSameDay, lagNextDay} {
+			c := correlate(f, lag, dayMeans, loc)
+			result.Correlations = append(result.Correlations, c)
+
+			if c.Difference == nil || math.Abs(*c.Difference) < minInsightDifference {
+				continue
+			}
+			if prev, ok := best[f]; !ok || math.Abs(*c.Difference) > math.Abs(*prev.Difference) {
+				best[f] = c
+			}
+		}
+	}
+
+	lang := insightLocale(locale)
+	for f, c := range best {
+		direction := "better"
+		if *c.Difference < 0 {
+			direction = "worse"
+		}
+		result.Insights = append(result.Insights, dto.MoodInsightDTO{
+			Statement:  fmt.Sprintf(insightStatements[lang][c.Lag][direction], insightPhrase(lang, f)),
+			Activity:   c.Activity,
+			CategoryID: c.CategoryID,
+			Lag:        c.Lag,
+			Direction:  direction,
+			Difference: *c.Difference,
+		})
+	}
+	sort.Slice(result.Insights, func(i, j int) bool {
+		return math.Abs(result.Insights[i].Difference) > math.Abs(result.Insights[j].Difference)
+	})
+	if len(result.Insights) > maxInsights {
+		result.Insights = result.Insights[:maxInsights]
+	}
+
+	return result, nil
+}
+
+// moodFeatures collects the days of each activity since the given time, from
+// EXP history and played songs and read articles
+func (s *MoodService) moodFeatures(userID uint, since time.Time, loc *time.Location) ([]*moodFeature, error) {
+	expActivities, err := s.expHistoryRepo.FindActivityTimesSince(userID, since)
+	if err != nil {
+		return nil, err
+	}
+	contentActivities, err := s.activityRepo.FindPointsSince(userID, since)
+	if err != nil {
+		return nil, err
+	}
+
+	var features []*moodFeature
+	byKey := make(map[string]*moodFeature)
+	mark := func(key string, at time.Time, newFeature func() *moodFeature) {
+		f, ok := byKey[key]
+		if !ok {
+			f = newFeature()
+			f.days = make(map[string]bool)
+			byKey[key] = f
+			features = append(features, f)
+		}
+		f.days[at.In(loc).Format(dateLayout)] = true
+	}
+
+	for _, a := range expActivities {
+		if _, ok := insightPhrases[defaultInsightLocale][a.ActivityType]; !ok {
+			continue
+		}
+		activity := a.ActivityType
+		mark(activity, a.CreatedAt, func() *moodFeature { return &moodFeature{activity: activity} })
+	}
+	for _, a := range contentActivities {
+		switch a.ContentType {
+		case models.ContentArticle:
+			mark(activityReadArticle, a.CreatedAt, func() *moodFeature { return &moodFeature{activity: activityReadArticle} })
+		case models.ContentSong:
+			if a.CategoryID == nil || a.CategoryName == "" {
+				continue
+			}
+			categoryID, label := *a.CategoryID, a.CategoryName
+			key := activityListenSong + ":" + strconv.FormatUint(uint64(categoryID), 10)
+			mark(key, a.CreatedAt, func() *moodFeature {
+				return &moodFeature{activity: activityListenSong, categoryID: &categoryID, label: label}
+			})
+		}
+	}
+
+	return features, nil
+}
+
+// correlate splits the mood days by whether the activity happened on the
+// same day or the day before, and compares their average valence
+func correlate(f *moodFeature, lag string, dayMeans map[string]float64, loc *time.Location) dto.MoodCorrelationDTO {
+	var with, without meanSum
+	for key, mean := range dayMeans {
+		day, err := time.ParseInLocation(dateLayout, key, loc)
+		if err != nil {
+			continue
+		}
+		if lag == lagNextDay {
+			day = day.AddDate(0, 0, -1)
+		}
+		if f.days[day.Format(dateLayout)] {
+			with.add(mean)
+		} else {
+			without.add(mean)
+		}
+	}
+
+	c := dto.MoodCorrelationDTO{
+		Activity:       f.activity,
+		CategoryID:     f.categoryID,
+		Label:          f.label,
+		Lag:            lag,
+		ActivityDays:   len(f.days),
+		DaysWith:       with.count,
+		DaysWithout:    without.count,
+		AverageWith:    with.average(),
+		AverageWithout: without.average(),
+	}
+	if c.AverageWith != nil && c.AverageWithout != nil {
+		diff := math.Round((with.sum/float64(with.count)-without.sum/float64(without.count))*100) / 100
+		c.Difference = &diff
+	}
+	return c
+}
+
+func insightPhrase(lang string, f *moodFeature) string {
+	phrase := insightPhrases[lang][f.activity]
+	if f.activity == activityListenSong {
+		return fmt.Sprintf(phrase, f.label)
+	}
+	return phrase
+}
+
+// insightLocale maps a requested locale to one the statements are written in
+func insightLocale(locale string) string {
+	locale = NormalizeLocale(locale)
+	if _, ok := insightStatements[locale]; ok {
+		return locale
+	}
+	return defaultInsightLocale
+}
@@ -1,89 +1,154 @@
 package services
 
 import (
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/Alfian57/ruang-tenang-api/internal/dto"
 	"github.com/Alfian57/ruang-tenang-api/internal/models"
 	"github.com/Alfian57/ruang-tenang-api/internal/repositories"
+	"github.com/Alfian57/ruang-tenang-api/pkg/clock"
 )
 
+const moodTagSuggestionLimit = 50
+
+// Tags are short labels of letters, digits, spaces, dashes and underscores
+var moodTagPattern = regexp.MustCompile(`^[\p{L}\p{N}][\p{L}\p{N} _-]{0,29}$`)
+
 type MoodService struct {
-	moodRepo *repositories.UserMoodRepository
+	moodRepo       *repositories.UserMoodRepository
+	expHistoryRepo *repositories.ExpHistoryRepository
+	activityRepo   *repositories.ContentActivityRepository
+	userRepo       *repositories.UserRepository
 }
 
-func NewMoodService(moodRepo *repositories.UserMoodRepository) *MoodService {
-	return &MoodService{moodRepo: moodRepo}
+func NewMoodService(moodRepo *repositories.UserMoodRepository, expHistoryRepo *repositories.ExpHistoryRepository, activityRepo *repositories.ContentActivityRepository, userRepo *repositories.UserRepository) *MoodService {
+	return &MoodService{
+		moodRepo:       moodRepo,
+		expHistoryRepo: expHistoryRepo,
+		activityRepo:   activityRepo,
+		userRepo:       userRepo,
+	}
 }
 
+// RecordMood logs a new check-in. Users can check in several times a day to
+// track how their mood changes.
 func (s *MoodService) RecordMood(userID uint, req *dto.CreateMoodRequest) (*dto.UserMoodDTO, error) {
-	// Check if user already has a mood recorded for today
-	existingMood, err := s.moodRepo.FindTodayByUserID(userID)
+	mood := &models.UserMood{UserID: userID}
+	if err := applyMoodRequest(mood, req); err != nil {
+		return nil, err
+	}
 
-	if err == nil && existingMood != nil {
-		// Update existing mood for today
-		existingMood.Mood = models.MoodType(req.Mood)
-		if err := s.moodRepo.Update(existingMood); err != nil {
-			return nil, err
-		}
-		return &dto.UserMoodDTO{
-			ID:        existingMood.ID,
-			Mood:      string(existingMood.Mood),
-			Emoji:     existingMood.GetMoodEmoji(),
-			CreatedAt: existingMood.CreatedAt,
-		}, nil
+	if err := s.moodRepo.Create(mood); err != nil {
+		return nil, err
 	}
 
-	// Create new mood entry
-	mood := &models.UserMood{
-		UserID: userID,
-		Mood:   models.MoodType(req.Mood),
+	return toUserMoodDTO(mood), nil
+}
+
+// GetMood returns one of the user's check-ins
+func (s *MoodService) GetMood(userID, moodID uint) (*dto.UserMoodDTO, error) {
+	mood, err := s.moodRepo.FindByIDAndUserID(moodID, userID)
+	if err != nil {
+		return nil, ErrMoodNotFound
 	}
+	return toUserMoodDTO(mood), nil
+}
 
-	if err := s.moodRepo.Create(mood); err != nil {
+// UpdateMood replaces the mood, intensity, note, context and tags of one of
+// the user's check-ins
+func (s *MoodService) UpdateMood(userID, moodID uint, req *dto.UpdateMoodRequest) (*dto.UserMoodDTO, error) {
+	mood, err := s.moodRepo.FindByIDAndUserID(moodID, userID)
+	if err != nil {
+		return nil, ErrMoodNotFound
+	}
+
+	if err := applyMoodRequest(mood, req); err != nil {
+		return nil, err
+	}
+	if err := s.moodRepo.Update(mood); err != nil {
 		return nil, err
 	}
+	mood.UpdatedAt = time.Now()
 
-	return &dto.UserMoodDTO{
-		ID:        mood.ID,
-		Mood:      string(mood.Mood),
-		Emoji:     mood.GetMoodEmoji(),
-		CreatedAt: mood.CreatedAt,
-	}, nil
+	return toUserMoodDTO(mood), nil
+}
+
+// DeleteMood permanently deletes one of the user's check-ins
+func (s *MoodService) DeleteMood(userID, moodID uint) error {
+	deleted, err := s.moodRepo.Delete(moodID, userID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrMoodNotFound
+	}
+	return nil
+}
+
+// GetMoodTags lists the activities and triggers the user has tagged before,
+// most used first, for suggestions
+func (s *MoodService) GetMoodTags(userID uint) (*dto.MoodTagsDTO, error) {
+	usage, err := s.moodRepo.GetTagUsage(userID, moodTagSuggestionLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	tags := &dto.MoodTagsDTO{Activities: []dto.MoodTagDTO{}, Triggers: []dto.MoodTagDTO{}}
+	for _, u := range usage {
+		tag := dto.MoodTagDTO{Name: u.Name, Count: u.Count}
+		switch u.Kind {
+		case models.MoodTagActivity:
+			tags.Activities = append(tags.Activities, tag)
+		case models.MoodTagTrigger:
+			tags.Triggers = append(tags.Triggers, tag)
+		}
+	}
+	return tags, nil
 }
 
 func (s *MoodService) GetMoodHistory(userID uint, params *dto.MoodQueryParams) (*dto.MoodHistoryDTO, error) {
 	var startDate, endDate *time.Time
 
+	loc, err := s.userLocation(userID)
+	if err != nil {
+		return nil, err
+	}
+
 	if params.StartDate != "" {
-		t, err := time.Parse("2006-01-02", params.StartDate)
+		t, err := time.ParseInLocation("2006-01-02", params.StartDate, loc)
 		if err == nil {
 			startDate = &t
 		}
 	}
 
 	if params.EndDate != "" {
-		t, err := time.Parse("2006-01-02", params.EndDate)
+		t, err := time.ParseInLocation("2006-01-02", params.EndDate, loc)
 		if err == nil {
-			// Add 24 hours to include the end date
-			t = t.Add(24 * time.Hour)
+			// Move to the next day to include the end date
+			t = t.AddDate(0, 0, 1)
 			endDate = &t
 		}
 	}
 
-	moods, total, err := s.moodRepo.FindByUserID(userID, startDate, endDate, params.Page, params.Limit)
+	tag := ""
+	if params.Tag != "" {
+		var err error
+		if tag, err = normalizeMoodTag(params.Tag); err != nil {
+			// No check-in can carry an invalid tag
+			return &dto.MoodHistoryDTO{Moods: []dto.UserMoodDTO{}}, nil
+		}
+	}
+
+	moods, total, err := s.moodRepo.FindByUserID(userID, startDate, endDate, params.Mood, tag, params.Page, params.Limit)
 	if err != nil {
 		return nil, err
 	}
 
 	var result []dto.UserMoodDTO
-	for _, mood := range moods {
-		result = append(result, dto.UserMoodDTO{
-			ID:        mood.ID,
-			Mood:      string(mood.Mood),
-			Emoji:     mood.GetMoodEmoji(),
-			CreatedAt: mood.CreatedAt,
-		})
+	for i := range moods {
+		result = append(result, *toUserMoodDTO(&moods[i]))
 	}
 
 	return &dto.MoodHistoryDTO{
@@ -98,14 +163,94 @@ func (s *MoodService) GetLatestMood(userID uint) (*dto.UserMoodDTO, error) {
 		return nil, err
 	}
 
-	return &dto.UserMoodDTO{
-		ID:        mood.ID,
-		Mood:      string(mood.Mood),
-		Emoji:     mood.GetMoodEmoji(),
-		CreatedAt: mood.CreatedAt,
-	}, nil
+	return toUserMoodDTO(mood), nil
 }
 
+// GetMoodStats counts check-ins by mood over the last days calendar days in
+// the user's timezone, today included
 func (s *MoodService) GetMoodStats(userID uint, days int) (map[string]int, error) {
-	return s.moodRepo.GetMoodStats(userID, days)
+	if days < 1 {
+		days = 1
+	}
+	loc, err := s.userLocation(userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.moodRepo.GetMoodStats(userID, days, loc)
+}
+
+// userLocation is the timezone the user's days are counted in
+func (s *MoodService) userLocation(userID uint) (*time.Location, error) {
+	timezone, err := s.userRepo.FindTimezone(userID)
+	if err != nil {
+		return nil, err
+	}
+	return clock.Location(timezone), nil
+}
+
+// applyMoodRequest copies the request onto the check-in, normalizing its tags
+func applyMoodRequest(mood *models.UserMood, req *dto.CreateMoodRequest) error {
+	activities, err := normalizeMoodTags(req.Activities)
+	if err != nil {
+		return err
+	}
+	triggers, err := normalizeMoodTags(req.Triggers)
+	if err != nil {
+		return err
+	}
+
+	mood.Mood = models.MoodType(req.Mood)
+	mood.Intensity = req.Intensity
+	mood.Note = strings.TrimSpace(req.Note)
+	mood.SocialContext = req.SocialContext
+	mood.Tags = make([]models.UserMoodTag, 0, len(activities)+len(triggers))
+	for _, name := range activities {
+		mood.Tags = append(mood.Tags, models.UserMoodTag{Kind: models.MoodTagActivity, Name: name})
+	}
+	for _, name := range triggers {
+		mood.Tags = append(mood.Tags, models.UserMoodTag{Kind: models.MoodTagTrigger, Name: name})
+	}
+	return nil
+}
+
+// normalizeMoodTags lowercases and de-duplicates tags so "Olahraga" and
+// "olahraga " count as one
+func normalizeMoodTags(names []string) ([]string, error) {
+	seen := make(map[string]bool, len(names))
+	tags := make([]string, 0, len(names))
+	for _, name := range names {
+		tag, err := normalizeMoodTag(name)
+		if err != nil {
+			return nil, err
+		}
+		if seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+func normalizeMoodTag(name string) (string, error) {
+	tag := strings.ToLower(strings.Join(strings.Fields(name), " "))
+	if !moodTagPattern.MatchString(tag) {
+		return "", ErrInvalidMoodTag
+	}
+	return tag, nil
+}
+
+func toUserMoodDTO(mood *models.UserMood) *dto.UserMoodDTO {
+	return &dto.UserMoodDTO{
+		ID:            mood.ID,
+		Mood:          string(mood.Mood),
+		Emoji:         mood.GetMoodEmoji(),
+		Intensity:     mood.Intensity,
+		Note:          mood.Note,
+		SocialContext: mood.SocialContext,
+		Activities:    mood.TagNames(models.MoodTagActivity),
+		Triggers:      mood.TagNames(models.MoodTagTrigger),
+		CreatedAt:     mood.CreatedAt,
+		UpdatedAt:     mood.UpdatedAt,
+	}
 }
@@ -0,0 +1,137 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Alfian57/ruang-tenang-api/internal/models"
+	"github.com/Alfian57/ruang-tenang-api/internal/repositories"
+	"github.com/Alfian57/ruang-tenang-api/pkg/logger"
+	"github.com/Alfian57/ruang-tenang-api/pkg/webpush"
+	"go.uber.org/zap"
+)
+
+// pushTTL is how long push services hold a notification for an offline
+// browser; an old reminder isn't worth showing
+const pushTTL = 4 * time.Hour
+
+// InAppChannel delivers to the inbox. The notification row is the inbox
+// entry, so there is nothing to send.
+type InAppChannel struct{}
+
+func NewInAppChannel() *InAppChannel {
+	return &InAppChannel{}
+}
+
+func (c *InAppChannel) Name() string {
+	return models.ChannelInApp
+}
+
+func (c *InAppChannel) Send(ctx context.Context, notification *models.Notification) error {
+	return nil
+}
+
+// EmailChannel hands notifications to the email outbox, which retries on its
+// own. Only verified addresses are emailed.
+type EmailChannel struct {
+	emailService *EmailService
+	userRepo     *repositories.UserRepository
+}
+
+func NewEmailChannel(emailService *EmailService, userRepo *repositories.UserRepository) *EmailChannel {
+	return &EmailChannel{emailService: emailService, userRepo: userRepo}
+}
+
+func (c *EmailChannel) Name() string {
+	return models.ChannelEmail
+}
+
+func (c *EmailChannel) Send(ctx context.Context, notification *models.Notification) error {
+	user, err := c.userRepo.FindByID(notification.UserID)
+	if err != nil {
+		return err
+	}
+	if user.Email == "" || !user.IsEmailVerified() {
+		return errUndeliverable
+	}
+
+	return c.emailService.Enqueue(user.Email, EmailNotification, notification.Locale, map[string]interface{}{
+		"Name":  user.Name,
+		"Title": notification.Title,
+		"Body":  notification.Body,
+		"Link":  notification.Link,
+	})
+}
+
+// WebPushChannel sends notifications to every browser the user subscribed
+// and drops subscriptions the push service no longer accepts
+type WebPushChannel struct {
+	client   *webpush.Client
+	pushRepo *repositories.PushSubscriptionRepository
+}
+
+func NewWebPushChannel(client *webpush.Client, pushRepo *repositories.PushSubscriptionRepository) *WebPushChannel {
+	return &WebPushChannel{client: client, pushRepo: pushRepo}
+}
+
+func (c *WebPushChannel) Name() string {
+	return models.ChannelWebPush
+}
+
+// pushPayload is the JSON the service worker receives in its push event
+type pushPayload struct {
+	ID    uint   `json:"id"`
+	Type  string `json:"type"`
+	Title string `json:"title"`
+	Body  string `json:"body"`
+	Link  string `json:"link,omitempty"`
+}
+
+// Send succeeds when at least one browser accepted the notification, so a
+// retry doesn't notify the others twice
+func (c *WebPushChannel) Send(ctx context.Context, notification *models.Notification) error {
+	subs, err := c.pushRepo.FindByUserID(notification.UserID)
+	if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(pushPayload{
+		ID:    notification.ID,
+		Type:  notification.Type,
+		Title: notification.Title,
+		Body:  notification.Body,
+		Link:  notification.Link,
+	})
+	if err != nil {
+		return err
+	}
+
+	delivered := 0
+	var lastErr error
+	for _, sub := range subs {
+		err := c.client.Send(ctx, webpush.Subscription{Endpoint: sub.Endpoint, P256dh: sub.P256dh, Auth: sub.Auth}, payload, pushTTL)
+		switch {
+		case err == nil:
+			delivered++
+		case errors.Is(err, webpush.ErrSubscriptionGone), errors.Is(err, webpush.ErrPrivateEndpoint):
+			// Expired, or saved before private endpoints were refused
+			if err := c.pushRepo.Delete(sub.ID); err != nil {
+				logger.Error("Failed to delete push subscription", zap.Uint("subscription_id", sub.ID), zap.Error(err))
+			}
+		default:
+			lastErr = err
+		}
+	}
+
+	switch {
+	case delivered > 0:
+		return nil
+	case lastErr != nil:
+		return fmt.Errorf("push to %d browsers failed: %w", len(subs), lastErr)
+	default:
+		return errUndeliverable
+	}
+}
@@ -0,0 +1,263 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Alfian57/ruang-tenang-api/internal/dto"
+	"github.com/Alfian57/ruang-tenang-api/internal/models"
+	"github.com/Alfian57/ruang-tenang-api/internal/repositories"
+	"github.com/Alfian57/ruang-tenang-api/pkg/logger"
+	"github.com/Alfian57/ruang-tenang-api/pkg/webpush"
+	"go.uber.org/zap"
+)
+
+const (
+	notificationBatchSize   = 50
+	notificationSendTimeout = 30 * time.Second
+	notificationLease       = 5 * time.Minute
+	notificationMaxAttempts = 5
+	notificationRetryBase   = time.Minute // doubles per attempt: 1m, 2m, 4m, 8m
+)
+
+// errUndeliverable is returned by channels that can't reach the user at all,
+// e.g. email for an account without a verified address; it isn't retried
+var errUndeliverable = errors.New("channel cannot reach the user")
+
+// NotificationChannel delivers notifications of its channel. Channels are
+// registered with the NotificationService; rows for channels that aren't
+// registered stay pending until one is.
+type NotificationChannel interface {
+	Name() string
+	Send(ctx context.Context, notification *models.Notification) error
+}
+
+// NotificationMessage is what to tell the user, whatever the channel
+type NotificationMessage struct {
+	Type   string
+	Locale string
+	Title  string
+	Body   string
+	Link   string
+}
+
+// NotificationService queues notifications in an outbox, one row per
+// channel, and delivers them from a background job through the registered
+// channels. It also serves the in-app inbox and Web Push subscriptions.
+type NotificationService struct {
+	notificationRepo *repositories.NotificationRepository
+	pushRepo         *repositories.PushSubscriptionRepository
+	push             *webpush.Client // nil when Web Push isn't configured
+	channels         map[string]NotificationChannel
+	channelNames     []string
+}
+
+func NewNotificationService(
+	notificationRepo *repositories.NotificationRepository,
+	pushRepo *repositories.PushSubscriptionRepository,
+	push *webpush.Client,
+	channels ...NotificationChannel,
+) *NotificationService {
+	s := &NotificationService{
+		notificationRepo: notificationRepo,
+		pushRepo:         pushRepo,
+		push:             push,
+		channels:         make(map[string]NotificationChannel, len(channels)),
+	}
+	for _, ch := range channels {
+		s.channels[ch.Name()] = ch
+		s.channelNames = append(s.channelNames, ch.Name())
+	}
+	return s
+}
+
+// Channels lists the registered channel names
+func (s *NotificationService) Channels() []string {
+	return append([]string(nil), s.channelNames...)
+}
+
+// HasChannel reports whether notifications can be delivered on the channel
+func (s *NotificationService) HasChannel(name string) bool {
+	_, ok := s.channels[name]
+	return ok
+}
+
+// Notify queues the message for the user on each of the channels that is
+// registered
+func (s *NotificationService) Notify(userID uint, msg NotificationMessage, channels []string) error {
+	now := time.Now()
+	var notifications []models.Notification
+	for _, channel := range channels {
+		if !s.HasChannel(channel) {
+			continue
+		}
+		notifications = append(notifications, models.Notification{
+			UserID:        userID,
+			Type:          msg.Type,
+			Channel:       channel,
+			Locale:        msg.Locale,
+			Title:         msg.Title,
+			Body:          msg.Body,
+			Link:          msg.Link,
+			Status:        models.NotificationPending,
+			NextAttemptAt: now,
+		})
+	}
+	if len(notifications) == 0 {
+		return nil
+	}
+	return s.notificationRepo.CreateBatch(notifications)
+}
+
+// ProcessQueue delivers the notifications that are due. Failures are retried
+// with exponential backoff until notificationMaxAttempts, then marked failed.
+func (s *NotificationService) ProcessQueue(ctx context.Context) error {
+	notifications, err := s.notificationRepo.ClaimDue(notificationBatchSize, notificationLease)
+	if err != nil {
+		return err
+	}
+
+	for i := range notifications {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		s.deliver(ctx, &notifications[i])
+	}
+
+	return nil
+}
+
+func (s *NotificationService) deliver(ctx context.Context, notification *models.Notification) {
+	channel, ok := s.channels[notification.Channel]
+	if !ok {
+		// Left pending for an instance that has the channel, e.g. after Web
+		// Push keys were removed; the lease delays the next look at it
+		return
+	}
+
+	sendCtx, cancel := context.WithTimeout(ctx, notificationSendTimeout)
+	defer cancel()
+
+	attempts := notification.Attempts + 1
+	err := channel.Send(sendCtx, notification)
+	if err == nil {
+		if err := s.notificationRepo.MarkSent(notification.ID, attempts); err != nil {
+			logger.Error("Failed to mark notification as sent", zap.Uint("notification_id", notification.ID), zap.Error(err))
+		}
+		return
+	}
+
+	var retryAt *time.Time
+	if attempts < notificationMaxAttempts && !errors.Is(err, errUndeliverable) {
+		next := time.Now().Add(notificationRetryBase << (attempts - 1))
+		retryAt = &next
+	}
+
+	if !errors.Is(err, errUndeliverable) {
+		logger.Warn("Notification delivery failed",
+			zap.Uint("notification_id", notification.ID),
+			zap.String("type", notification.Type),
+			zap.String("channel", notification.Channel),
+			zap.Int("attempt", attempts),
+			zap.Bool("will_retry", retryAt != nil),
+			zap.Error(err),
+		)
+	}
+
+	if err := s.notificationRepo.MarkFailed(notification.ID, attempts, err.Error(), retryAt); err != nil {
+		logger.Error("Failed to record notification failure", zap.Uint("notification_id", notification.ID), zap.Error(err))
+	}
+}
+
+// GetNotifications lists the user's in-app notifications, newest first
+func (s *NotificationService) GetNotifications(userID uint, params *dto.NotificationQueryParams) ([]dto.NotificationDTO, int64, error) {
+	notifications, total, err := s.notificationRepo.FindInbox(userID, params.Unread, params.Page, params.Limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	result := make([]dto.NotificationDTO, len(notifications))
+	for i, n := range notifications {
+		result[i] = dto.NotificationDTO{
+			ID:        n.ID,
+			Type:      n.Type,
+			Title:     n.Title,
+			Body:      n.Body,
+			Link:      n.Link,
+			ReadAt:    n.ReadAt,
+			CreatedAt: n.CreatedAt,
+		}
+	}
+	return result, total, nil
+}
+
+func (s *NotificationService) GetUnreadCount(userID uint) (*dto.UnreadNotificationsDTO, error) {
+	count, err := s.notificationRepo.CountUnread(userID)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.UnreadNotificationsDTO{Unread: count}, nil
+}
+
+func (s *NotificationService) MarkRead(userID, notificationID uint) error {
+	found, err := s.notificationRepo.MarkRead(notificationID, userID)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrNotificationNotFound
+	}
+	return nil
+}
+
+func (s *NotificationService) MarkAllRead(userID uint) (*dto.MarkAllNotificationsReadDTO, error) {
+	marked, err := s.notificationRepo.MarkAllRead(userID)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.MarkAllNotificationsReadDTO{Marked: marked}, nil
+}
+
+// GetPushConfig returns the VAPID public key browsers subscribe with
+func (s *NotificationService) GetPushConfig() dto.PushConfigDTO {
+	if s.push == nil {
+		return dto.PushConfigDTO{}
+	}
+	return dto.PushConfigDTO{Enabled: true, PublicKey: s.push.PublicKey()}
+}
+
+// SubscribePush saves a browser's push subscription for the user
+func (s *NotificationService) SubscribePush(userID uint, req *dto.PushSubscriptionRequest, userAgent string) error {
+	if s.push == nil {
+		return ErrPushUnavailable
+	}
+
+	sub := webpush.Subscription{Endpoint: req.Endpoint, P256dh: req.Keys.P256dh, Auth: req.Keys.Auth}
+	if err := sub.Validate(); err != nil {
+		return ErrInvalidPushSubscription
+	}
+
+	if len(userAgent) > 255 {
+		userAgent = userAgent[:255]
+	}
+	return s.pushRepo.Upsert(&models.PushSubscription{
+		UserID:    userID,
+		Endpoint:  sub.Endpoint,
+		P256dh:    sub.P256dh,
+		Auth:      sub.Auth,
+		UserAgent: userAgent,
+	})
+}
+
+// UnsubscribePush removes a browser's push subscription, e.g. on sign out
+func (s *NotificationService) UnsubscribePush(userID uint, endpoint string) error {
+	found, err := s.pushRepo.DeleteByEndpoint(userID, endpoint)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrPushSubscriptionNotFound
+	}
+	return nil
+}
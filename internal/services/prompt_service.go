@@ -0,0 +1,208 @@
+package services
+
+import (
+	"strings"
+
+	"github.com/Alfian57/ruang-tenang-api/internal/dto"
+	"github.com/Alfian57/ruang-tenang-api/internal/models"
+	"github.com/Alfian57/ruang-tenang-api/internal/repositories"
+)
+
+// DefaultPromptLocale is used when no prompt exists for the requested locale
+const DefaultPromptLocale = "id"
+
+type PromptService struct {
+	promptRepo *repositories.SystemPromptRepository
+}
+
+func NewPromptService(promptRepo *repositories.SystemPromptRepository) *PromptService {
+	return &PromptService{promptRepo: promptRepo}
+}
+
+// Resolve picks the active prompt version for a locale, falling back to the
+// default locale. When several versions are active, one is chosen by weight
+// using stickyKey so a chat session keeps talking to the same variant.
+// Returns nil when no prompt is configured.
+func (s *PromptService) Resolve(name, locale string, stickyKey uint) *models.SystemPrompt {
+	locale = NormalizeLocale(locale)
+
+	prompts, err := s.promptRepo.FindActive(name, locale)
+	if (err != nil || len(prompts) == 0) && locale != DefaultPromptLocale {
+		prompts, err = s.promptRepo.FindActive(name, DefaultPromptLocale)
+	}
+	if err != nil || len(prompts) == 0 {
+		return nil
+	}
+
+	totalWeight := 0
+	for _, p := range prompts {
+		totalWeight += promptWeight(p)
+	}
+
+	pick := int(stickyKey % uint(totalWeight))
+	for i := range prompts {
+		pick -= promptWeight(prompts[i])
+		if pick < 0 {
+			return &prompts[i]
+		}
+	}
+	return &prompts[len(prompts)-1]
+}
+
+func (s *PromptService) GetAll(params dto.SystemPromptQueryParams) ([]dto.SystemPromptDTO, error) {
+	prompts, err := s.promptRepo.FindAll(params.Name, NormalizeLocale(params.Locale))
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]dto.SystemPromptDTO, len(prompts))
+	for i := range prompts {
+		result[i] = s.toDTO(&prompts[i])
+	}
+	return result, nil
+}
+
+func (s *PromptService) GetByID(id uint) (*dto.SystemPromptDTO, error) {
+	prompt, err := s.promptRepo.FindByID(id)
+	if err != nil {
+		return nil, ErrPromptNotFound
+	}
+	result := s.toDTO(prompt)
+	return &result, nil
+}
+
+// Create stores a new, inactive version of a prompt
+func (s *PromptService) Create(adminID uint, req *dto.CreateSystemPromptRequest) (*dto.SystemPromptDTO, error) {
+	name := req.Name
+	if name == "" {
+		name = models.SystemPromptChat
+	}
+
+	prompt := &models.SystemPrompt{
+		Name:      name,
+		Locale:    NormalizeLocale(req.Locale),
+		Content:   req.Content,
+		Notes:     req.Notes,
+		Weight:    req.Weight,
+		CreatedBy: &adminID,
+	}
+	if prompt.Weight == 0 {
+		prompt.Weight = 100
+	}
+
+	if err := s.promptRepo.Create(prompt); err != nil {
+		return nil, err
+	}
+
+	result := s.toDTO(prompt)
+	return &result, nil
+}
+
+// Update edits a version that has not produced any messages yet. Used versions
+// are immutable so feedback stays attributable; create a new version instead.
+func (s *PromptService) Update(id uint, req *dto.UpdateSystemPromptRequest) (*dto.SystemPromptDTO, error) {
+	prompt, err := s.promptRepo.FindByID(id)
+	if err != nil {
+		return nil, ErrPromptNotFound
+	}
+
+	if usage, _ := s.promptRepo.CountUsage(id); usage > 0 && req.Content != prompt.Content {
+		return nil, ErrPromptInUse
+	}
+
+	prompt.Content = req.Content
+	prompt.Notes = req.Notes
+	if req.Weight > 0 {
+		prompt.Weight = req.Weight
+	}
+
+	if err := s.promptRepo.Update(prompt); err != nil {
+		return nil, err
+	}
+
+	result := s.toDTO(prompt)
+	return &result, nil
+}
+
+func (s *PromptService) Activate(id uint, exclusive bool) (*dto.SystemPromptDTO, error) {
+	prompt, err := s.promptRepo.FindByID(id)
+	if err != nil {
+		return nil, ErrPromptNotFound
+	}
+
+	if err := s.promptRepo.Activate(prompt, exclusive); err != nil {
+		return nil, err
+	}
+
+	result := s.toDTO(prompt)
+	return &result, nil
+}
+
+func (s *PromptService) Deactivate(id uint) (*dto.SystemPromptDTO, error) {
+	prompt, err := s.promptRepo.FindByID(id)
+	if err != nil {
+		return nil, ErrPromptNotFound
+	}
+
+	prompt.IsActive = false
+	if err := s.promptRepo.Update(prompt); err != nil {
+		return nil, err
+	}
+
+	result := s.toDTO(prompt)
+	return &result, nil
+}
+
+// Delete removes a version that is inactive and was never used
+func (s *PromptService) Delete(id uint) error {
+	prompt, err := s.promptRepo.FindByID(id)
+	if err != nil {
+		return ErrPromptNotFound
+	}
+
+	if prompt.IsActive {
+		return ErrPromptActive
+	}
+	if usage, _ := s.promptRepo.CountUsage(id); usage > 0 {
+		return ErrPromptInUse
+	}
+
+	return s.promptRepo.Delete(id)
+}
+
+func (s *PromptService) toDTO(prompt *models.SystemPrompt) dto.SystemPromptDTO {
+	usage, _ := s.promptRepo.CountUsage(prompt.ID)
+	return dto.SystemPromptDTO{
+		ID:         prompt.ID,
+		Name:       prompt.Name,
+		Locale:     prompt.Locale,
+		Version:    prompt.Version,
+		Content:    prompt.Content,
+		Notes:      prompt.Notes,
+		IsActive:   prompt.IsActive,
+		Weight:     prompt.Weight,
+		UsageCount: usage,
+		CreatedAt:  prompt.CreatedAt,
+		UpdatedAt:  prompt.UpdatedAt,
+	}
+}
+
+func promptWeight(prompt models.SystemPrompt) int {
+	if prompt.Weight < 1 {
+		return 1
+	}
+	return prompt.Weight
+}
+
+// NormalizeLocale reduces a locale or Accept-Language value such as
+// "en-US,en;q=0.9" to its primary language subtag ("en")
+func NormalizeLocale(locale string) string {
+	locale = strings.ToLower(strings.TrimSpace(locale))
+	if i := strings.IndexAny(locale, ",;"); i >= 0 {
+		locale = locale[:i]
+	}
+	if i := strings.IndexAny(locale, "-_"); i >= 0 {
+		locale = locale[:i]
+	}
+	return locale
+}
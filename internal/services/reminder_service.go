@@ -0,0 +1,380 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Alfian57/ruang-tenang-api/internal/config"
+	"github.com/Alfian57/ruang-tenang-api/internal/dto"
+	"github.com/Alfian57/ruang-tenang-api/internal/models"
+	"github.com/Alfian57/ruang-tenang-api/internal/repositories"
+	"github.com/Alfian57/ruang-tenang-api/pkg/clock"
+	"github.com/Alfian57/ruang-tenang-api/pkg/logger"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+const (
+	reminderBatchSize = 100
+	reminderLease     = 5 * time.Minute
+	reminderMaxDelay  = time.Hour // a reminder this late, e.g. after downtime, is skipped
+
+	defaultReminderTime   = "20:00"
+	defaultReminderLocale = "id"
+
+	clockLayout = "15:04"
+)
+
+type reminderMessage struct {
+	title string
+	body  string
+}
+
+var reminderMessages = map[string]reminderMessage{
+	"id": {
+		title: "Bagaimana perasaanmu hari ini?",
+		body:  "Luangkan sebentar untuk mencatat mood kamu hari ini.",
+	},
+	"en": {
+		title: "How are you feeling today?",
+		body:  "Take a moment to log your mood for today.",
+	},
+}
+
+// ReminderService keeps mood check-in reminder preferences and, from a
+// background job, notifies users whose reminder is due unless they already
+// logged a mood today. Reminder times are in the user's timezone.
+type ReminderService struct {
+	prefRepo            *repositories.ReminderPreferenceRepository
+	moodRepo            *repositories.UserMoodRepository
+	userRepo            *repositories.UserRepository
+	notificationService *NotificationService
+	checkInURL          string
+}
+
+func NewReminderService(
+	prefRepo *repositories.ReminderPreferenceRepository,
+	moodRepo *repositories.UserMoodRepository,
+	userRepo *repositories.UserRepository,
+	notificationService *NotificationService,
+	cfg *config.Config,
+) *ReminderService {
+	checkInURL := cfg.MoodCheckInURL
+	if checkInURL == "" {
+		checkInURL = strings.TrimRight(cfg.ClientOrigin, "/") + "/mood"
+	}
+
+	return &ReminderService{
+		prefRepo:            prefRepo,
+		moodRepo:            moodRepo,
+		userRepo:            userRepo,
+		notificationService: notificationService,
+		checkInURL:          checkInURL,
+	}
+}
+
+// GetPreference returns the user's reminder settings, or the defaults for a
+// user who never saved any
+func (s *ReminderService) GetPreference(userID uint) (*dto.ReminderPreferenceDTO, error) {
+	pref, err := s.prefRepo.FindByUserID(userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		pref = &models.ReminderPreference{
+			UserID:   userID,
+			Times:    defaultReminderTime,
+			Days:     models.AllReminderDays,
+			Channels: models.ChannelInApp,
+			Locale:   defaultReminderLocale,
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	timezone, err := s.userRepo.FindTimezone(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.toReminderPreferenceDTO(pref, timezone), nil
+}
+
+// UpdatePreference validates and saves the user's reminder settings and
+// schedules the next reminder
+func (s *ReminderService) UpdatePreference(userID uint, req *dto.UpdateReminderPreferenceRequest) (*dto.ReminderPreferenceDTO, error) {
+	times := make([]string, 0, len(req.Times))
+	seen := make(map[string]bool)
+	for _, t := range req.Times {
+		hhmm, ok := normalizeClock(t)
+		if !ok {
+			return nil, ErrInvalidReminderTime
+		}
+		if !seen[hhmm] {
+			seen[hhmm] = true
+			times = append(times, hhmm)
+		}
+	}
+	sort.Strings(times)
+
+	quietStart, quietEnd := "", ""
+	if req.QuietStart != "" || req.QuietEnd != "" {
+		var okStart, okEnd bool
+		quietStart, okStart = normalizeClock(req.QuietStart)
+		quietEnd, okEnd = normalizeClock(req.QuietEnd)
+		if !okStart || !okEnd {
+			return nil, ErrInvalidReminderTime
+		}
+		if quietStart == quietEnd {
+			quietStart, quietEnd = "", ""
+		}
+	}
+
+	days := 0
+	for _, day := range req.Days {
+		for i, key := range weekdayKeys {
+			if key == day {
+				days |= 1 << ((i + 1) % 7)
+			}
+		}
+	}
+
+	var channels []string
+	for _, channel := range req.Channels {
+		if !s.notificationService.HasChannel(channel) {
+			return nil, ErrChannelUnavailable
+		}
+		if !containsString(channels, channel) {
+			channels = append(channels, channel)
+		}
+	}
+
+	if req.Enabled && (len(times) == 0 || days == 0 || len(channels) == 0) {
+		return nil, ErrReminderIncomplete
+	}
+
+	timezone, err := s.userRepo.FindTimezone(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	pref, err := s.prefRepo.FindByUserID(userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		pref = &models.ReminderPreference{UserID: userID}
+	} else if err != nil {
+		return nil, err
+	}
+
+	pref.Enabled = req.Enabled
+	pref.Times = strings.Join(times, ",")
+	pref.Days = days
+	pref.QuietStart = quietStart
+	pref.QuietEnd = quietEnd
+	pref.Channels = strings.Join(channels, ",")
+	pref.Locale = reminderLocale(req.Locale)
+	pref.NextReminderAt = nil
+	if pref.Enabled {
+		pref.NextReminderAt = nextReminder(pref, clock.Location(timezone), time.Now())
+	}
+
+	if err := s.prefRepo.Save(pref); err != nil {
+		return nil, err
+	}
+
+	return s.toReminderPreferenceDTO(pref, timezone), nil
+}
+
+// Reschedule recomputes the user's next reminder, for when their timezone
+// changes
+func (s *ReminderService) Reschedule(userID uint) error {
+	pref, err := s.prefRepo.FindByUserID(userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if !pref.Enabled {
+		return nil
+	}
+
+	timezone, err := s.userRepo.FindTimezone(userID)
+	if err != nil {
+		return err
+	}
+
+	return s.prefRepo.SetNextReminder(pref.ID, nextReminder(pref, clock.Location(timezone), time.Now()))
+}
+
+// ProcessDue sends the reminders that are due. Each reminder is rescheduled
+// before it is sent, so a crash can lose a reminder but never repeat one.
+func (s *ReminderService) ProcessDue(ctx context.Context) error {
+	prefs, err := s.prefRepo.ClaimDue(reminderBatchSize, reminderLease)
+	if err != nil {
+		return err
+	}
+
+	for i := range prefs {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		s.remind(&prefs[i])
+	}
+
+	return nil
+}
+
+func (s *ReminderService) remind(pref *models.ReminderPreference) {
+	due := *pref.NextReminderAt
+	now := time.Now()
+
+	timezone, err := s.userRepo.FindTimezone(pref.UserID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// The account is gone; stop until it is erased with its preference
+		if err := s.prefRepo.SetNextReminder(pref.ID, nil); err != nil {
+			logger.Error("Failed to stop mood reminder", zap.Uint("user_id", pref.UserID), zap.Error(err))
+		}
+		return
+	} else if err != nil {
+		logger.Error("Failed to load user timezone", zap.Uint("user_id", pref.UserID), zap.Error(err))
+		return
+	}
+	loc := clock.Location(timezone)
+
+	// Scheduled from now rather than from the due time, so reminders missed
+	// during downtime aren't sent one after another
+	if err := s.prefRepo.SetNextReminder(pref.ID, nextReminder(pref, loc, now)); err != nil {
+		logger.Error("Failed to schedule mood reminder", zap.Uint("user_id", pref.UserID), zap.Error(err))
+		return
+	}
+
+	if now.Sub(due) > reminderMaxDelay {
+		return
+	}
+	if _, err := s.moodRepo.FindTodayByUserID(pref.UserID, loc); err == nil {
+		return // already logged a mood today
+	}
+
+	msg := reminderMessages[reminderLocale(pref.Locale)]
+	err = s.notificationService.Notify(pref.UserID, NotificationMessage{
+		Type:   models.NotificationMoodReminder,
+		Locale: pref.Locale,
+		Title:  msg.title,
+		Body:   msg.body,
+		Link:   s.checkInURL,
+	}, pref.ChannelList())
+	if err != nil {
+		logger.Error("Failed to queue mood reminder", zap.Uint("user_id", pref.UserID), zap.Error(err))
+		return
+	}
+
+	if err := s.prefRepo.MarkReminded(pref.ID, now); err != nil {
+		logger.Error("Failed to record mood reminder", zap.Uint("user_id", pref.UserID), zap.Error(err))
+	}
+}
+
+func (s *ReminderService) toReminderPreferenceDTO(pref *models.ReminderPreference, timezone string) *dto.ReminderPreferenceDTO {
+	days := []string{}
+	for i, key := range weekdayKeys {
+		if pref.OnDay(time.Weekday((i + 1) % 7)) {
+			days = append(days, key)
+		}
+	}
+
+	times := pref.TimeList()
+	if times == nil {
+		times = []string{}
+	}
+	channels := pref.ChannelList()
+	if channels == nil {
+		channels = []string{}
+	}
+
+	return &dto.ReminderPreferenceDTO{
+		Enabled:           pref.Enabled,
+		Times:             times,
+		Days:              days,
+		Timezone:          timezone,
+		QuietStart:        pref.QuietStart,
+		QuietEnd:          pref.QuietEnd,
+		Channels:          channels,
+		AvailableChannels: s.notificationService.Channels(),
+		Locale:            pref.Locale,
+		NextReminderAt:    pref.NextReminderAt,
+		LastRemindedAt:    pref.LastRemindedAt,
+	}
+}
+
+// nextReminder finds the first reminder time after the given time on an
+// enabled day and outside quiet hours, or nil when there is none
+func nextReminder(pref *models.ReminderPreference, loc *time.Location, after time.Time) *time.Time {
+	local := after.In(loc)
+
+	// Eight days covers a reminder on a single weekday that already passed today
+	for d := 0; d <= 7; d++ {
+		day := time.Date(local.Year(), local.Month(), local.Day()+d, 0, 0, 0, 0, loc)
+		if !pref.OnDay(day.Weekday()) {
+			continue
+		}
+		for _, hhmm := range pref.TimeList() {
+			minute, ok := clockMinute(hhmm)
+			if !ok || inQuietHours(pref, minute) {
+				continue
+			}
+			at := time.Date(day.Year(), day.Month(), day.Day(), minute/60, minute%60, 0, 0, loc)
+			if at.After(after) {
+				return &at
+			}
+		}
+	}
+	return nil
+}
+
+// inQuietHours reports whether a minute of the day falls in the quiet hours,
+// which may span midnight
+func inQuietHours(pref *models.ReminderPreference, minute int) bool {
+	start, okStart := clockMinute(pref.QuietStart)
+	end, okEnd := clockMinute(pref.QuietEnd)
+	if !okStart || !okEnd || start == end {
+		return false
+	}
+	if start < end {
+		return minute >= start && minute < end
+	}
+	return minute >= start || minute < end
+}
+
+// normalizeClock parses "H:MM" or "HH:MM" and formats it as "HH:MM"
+func normalizeClock(s string) (string, bool) {
+	t, err := time.Parse(clockLayout, strings.TrimSpace(s))
+	if err != nil {
+		return "", false
+	}
+	return t.Format(clockLayout), true
+}
+
+// clockMinute converts "HH:MM" to minutes since midnight
+func clockMinute(s string) (int, bool) {
+	t, err := time.Parse(clockLayout, s)
+	if err != nil {
+		return 0, false
+	}
+	return t.Hour()*60 + t.Minute(), true
+}
+
+// reminderLocale maps a requested locale to one reminders are written in
+func reminderLocale(locale string) string {
+	locale = NormalizeLocale(locale)
+	if _, ok := reminderMessages[locale]; ok {
+		return locale
+	}
+	return defaultReminderLocale
+}
+
+func containsString(items []string, s string) bool {
+	for _, item := range items {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
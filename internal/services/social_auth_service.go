@@ -0,0 +1,295 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Alfian57/ruang-tenang-api/internal/dto"
+	"github.com/Alfian57/ruang-tenang-api/internal/models"
+	"github.com/Alfian57/ruang-tenang-api/internal/repositories"
+	"github.com/Alfian57/ruang-tenang-api/pkg/logger"
+	"github.com/Alfian57/ruang-tenang-api/pkg/oidc"
+	"github.com/Alfian57/ruang-tenang-api/pkg/utils"
+	"go.uber.org/zap"
+	"golang.org/x/oauth2"
+	"gorm.io/gorm"
+)
+
+// oauthStateTTL is how long the user has to finish signing in at the provider
+const oauthStateTTL = 10 * time.Minute
+
+// SocialAuthService signs users in with external identity providers and
+// manages the identities linked to their account
+type SocialAuthService struct {
+	authService  *AuthService
+	userRepo     *repositories.UserRepository
+	identityRepo *repositories.UserIdentityRepository
+	stateRepo    *repositories.OAuthStateRepository
+	providers    *oidc.Registry
+}
+
+func NewSocialAuthService(authService *AuthService, userRepo *repositories.UserRepository, identityRepo *repositories.UserIdentityRepository, stateRepo *repositories.OAuthStateRepository, providers *oidc.Registry) *SocialAuthService {
+	return &SocialAuthService{
+		authService:  authService,
+		userRepo:     userRepo,
+		identityRepo: identityRepo,
+		stateRepo:    stateRepo,
+		providers:    providers,
+	}
+}
+
+func (s *SocialAuthService) Providers() []string {
+	return s.providers.Names()
+}
+
+// Authorize starts a sign-in. linkUserID is set when a signed-in user links
+// the provider to their account instead.
+func (s *SocialAuthService) Authorize(ctx context.Context, providerName string, linkUserID *uint) (*dto.OIDCAuthorizeResponse, error) {
+	provider, err := s.providers.Get(providerName)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := utils.GenerateRandomString(32)
+	if err != nil {
+		return nil, errors.New("failed to start sign-in")
+	}
+	nonce, err := utils.GenerateRandomString(16)
+	if err != nil {
+		return nil, errors.New("failed to start sign-in")
+	}
+	verifier := oauth2.GenerateVerifier()
+
+	authURL, err := provider.AuthCodeURL(ctx, state, nonce, verifier)
+	if err != nil {
+		logger.Error("OIDC discovery failed", zap.String("provider", providerName), zap.Error(err))
+		return nil, ErrProviderUnavailable
+	}
+
+	expiresAt := time.Now().Add(oauthStateTTL)
+	err = s.stateRepo.Create(&models.OAuthState{
+		StateHash:    utils.HashToken(state),
+		Provider:     providerName,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		UserID:       linkUserID,
+		ExpiresAt:    expiresAt,
+	})
+	if err != nil {
+		return nil, errors.New("failed to start sign-in")
+	}
+
+	return &dto.OIDCAuthorizeResponse{
+		Provider:         providerName,
+		AuthorizationURL: authURL,
+		State:            state,
+		ExpiresAt:        expiresAt,
+	}, nil
+}
+
+// Login finishes a sign-in. A known identity signs in its user; otherwise a
+// verified provider email is linked to the matching verified account, or a
+// new account is created.
+func (s *SocialAuthService) Login(ctx context.Context, providerName string, req *dto.OIDCCallbackRequest, client ClientInfo) (*dto.LoginResponse, error) {
+	claims, err := s.exchange(ctx, providerName, req, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.resolveUser(providerName, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.IsBlocked {
+		return nil, ErrAccountBlocked
+	}
+
+	client.DeviceName = req.DeviceName
+	return s.authService.completeLogin(user, req.RememberMe, client)
+}
+
+func (s *SocialAuthService) resolveUser(providerName string, claims *oidc.Claims) (*models.User, error) {
+	identity, err := s.identityRepo.FindByProviderSubject(providerName, claims.Subject)
+	if err == nil {
+		if err := s.identityRepo.TouchLogin(identity.ID, claims.Email); err != nil {
+			logger.Warn("Failed to update identity login", zap.Uint("identity_id", identity.ID), zap.Error(err))
+		}
+		return &identity.User, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errors.New("failed to sign in")
+	}
+
+	// Matching by email is only safe when the provider vouches for it
+	if claims.Email == "" || !claims.EmailVerified {
+		return nil, ErrSocialEmailUnverified
+	}
+
+	now := time.Now()
+	identity = &models.UserIdentity{
+		Provider:    providerName,
+		Subject:     claims.Subject,
+		Email:       claims.Email,
+		LastLoginAt: &now,
+	}
+
+	user, err := s.userRepo.FindByEmail(claims.Email)
+	if err == nil {
+		// An unverified account may have been registered by someone else
+		// with this address; linking it would hand them the provider login
+		if !user.IsEmailVerified() {
+			return nil, ErrSocialAccountExists
+		}
+
+		identity.UserID = user.ID
+		if err := s.identityRepo.Create(identity); err != nil {
+			return nil, ErrSocialAccountExists
+		}
+		return user, nil
+	}
+
+	user = &models.User{
+		Name:            socialDisplayName(claims),
+		Email:           claims.Email,
+		Role:            models.RoleMember,
+		Avatar:          claims.Picture,
+		EmailVerifiedAt: &now,
+	}
+	if err := s.identityRepo.CreateWithUser(user, identity); err != nil {
+		return nil, errors.New("failed to create user")
+	}
+	return user, nil
+}
+
+// Link finishes linking a provider to the signed-in user's account
+func (s *SocialAuthService) Link(ctx context.Context, userID uint, providerName string, req *dto.OIDCCallbackRequest) (*dto.UserIdentityDTO, error) {
+	claims, err := s.exchange(ctx, providerName, req, &userID)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.identityRepo.FindByProviderSubject(providerName, claims.Subject)
+	if err == nil {
+		if existing.UserID != userID {
+			return nil, ErrIdentityLinked
+		}
+		result := toUserIdentityDTO(existing)
+		return &result, nil
+	}
+
+	identity := &models.UserIdentity{
+		UserID:   userID,
+		Provider: providerName,
+		Subject:  claims.Subject,
+		Email:    claims.Email,
+	}
+	if err := s.identityRepo.Create(identity); err != nil {
+		return nil, ErrProviderAlreadyLinked
+	}
+
+	result := toUserIdentityDTO(identity)
+	return &result, nil
+}
+
+// GetSignInMethods lists the user's linked providers and whether they have a password
+func (s *SocialAuthService) GetSignInMethods(userID uint) (*dto.SignInMethodsDTO, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, errors.New("user not found")
+	}
+
+	identities, err := s.identityRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &dto.SignInMethodsDTO{
+		HasPassword: user.HasPassword(),
+		Identities:  make([]dto.UserIdentityDTO, len(identities)),
+		Available:   s.providers.Names(),
+	}
+	for i := range identities {
+		result.Identities[i] = toUserIdentityDTO(&identities[i])
+	}
+	return result, nil
+}
+
+// Unlink removes a provider, as long as the user keeps a way to sign in
+func (s *SocialAuthService) Unlink(userID uint, providerName string) error {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+
+	if !user.HasPassword() {
+		count, err := s.identityRepo.CountByUserID(userID)
+		if err != nil {
+			return errors.New("failed to unlink provider")
+		}
+		if count <= 1 {
+			return ErrLastSignInMethod
+		}
+	}
+
+	unlinked, err := s.identityRepo.Delete(userID, providerName)
+	if err != nil {
+		return errors.New("failed to unlink provider")
+	}
+	if !unlinked {
+		return ErrIdentityNotFound
+	}
+	return nil
+}
+
+// PurgeExpiredStates removes sign-ins that were started but never finished
+func (s *SocialAuthService) PurgeExpiredStates() (int64, error) {
+	return s.stateRepo.DeleteExpired()
+}
+
+// exchange consumes the sign-in state and redeems the code. The state must
+// have been started for the same provider and the same purpose: signing in
+// (linkUserID nil) or linking for that user.
+func (s *SocialAuthService) exchange(ctx context.Context, providerName string, req *dto.OIDCCallbackRequest, linkUserID *uint) (*oidc.Claims, error) {
+	provider, err := s.providers.Get(providerName)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := s.stateRepo.Consume(utils.HashToken(req.State))
+	if err != nil || state.Provider != providerName {
+		return nil, ErrInvalidOAuthState
+	}
+	if (linkUserID == nil) != (state.UserID == nil) || (linkUserID != nil && *linkUserID != *state.UserID) {
+		return nil, ErrInvalidOAuthState
+	}
+
+	claims, err := provider.Exchange(ctx, req.Code, state.CodeVerifier, state.Nonce)
+	if err != nil {
+		logger.Warn("OIDC sign-in failed", zap.String("provider", providerName), zap.Error(err))
+		return nil, ErrSocialLoginFailed
+	}
+	return claims, nil
+}
+
+func socialDisplayName(claims *oidc.Claims) string {
+	name := strings.TrimSpace(claims.Name)
+	if name == "" {
+		name, _, _ = strings.Cut(claims.Email, "@")
+	}
+	if runes := []rune(name); len(runes) > 100 {
+		name = string(runes[:100])
+	}
+	return name
+}
+
+func toUserIdentityDTO(identity *models.UserIdentity) dto.UserIdentityDTO {
+	return dto.UserIdentityDTO{
+		Provider:    identity.Provider,
+		Email:       identity.Email,
+		LinkedAt:    identity.CreatedAt,
+		LastLoginAt: identity.LastLoginAt,
+	}
+}
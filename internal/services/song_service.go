@@ -1,6 +1,8 @@
 package services
 
 import (
+	"errors"
+
 	"github.com/Alfian57/ruang-tenang-api/internal/dto"
 	"github.com/Alfian57/ruang-tenang-api/internal/models"
 	"github.com/Alfian57/ruang-tenang-api/internal/repositories"
@@ -9,12 +11,14 @@ import (
 type SongService struct {
 	songRepo     *repositories.SongRepository
 	categoryRepo *repositories.SongCategoryRepository
+	activityRepo *repositories.ContentActivityRepository
 }
 
-func NewSongService(songRepo *repositories.SongRepository, categoryRepo *repositories.SongCategoryRepository) *SongService {
+func NewSongService(songRepo *repositories.SongRepository, categoryRepo *repositories.SongCategoryRepository, activityRepo *repositories.ContentActivityRepository) *SongService {
 	return &SongService{
 		songRepo:     songRepo,
 		categoryRepo: categoryRepo,
+		activityRepo: activityRepo,
 	}
 }
 
@@ -81,6 +85,17 @@ func (s *SongService) GetSongByID(id uint) (*dto.SongDTO, error) {
 	}, nil
 }
 
+// RecordPlay notes that the user listened to the song, for mood insights
+func (s *SongService) RecordPlay(userID, songID uint) error {
+	song, err := s.songRepo.FindByID(songID)
+	if err != nil {
+		return errors.New("song not found")
+	}
+
+	categoryID := song.SongCategoryID
+	return recordContentActivity(s.activityRepo, userID, models.ContentSong, song.ID, &categoryID)
+}
+
 func (s *SongService) CreateCategory(category *models.SongCategory) error {
 	return s.categoryRepo.Create(category)
 }
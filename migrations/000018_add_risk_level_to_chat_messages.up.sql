@@ -0,0 +1 @@
+ALTER TABLE chat_messages ADD COLUMN risk_level VARCHAR(10) DEFAULT 'none';
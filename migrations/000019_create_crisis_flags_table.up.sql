@@ -0,0 +1,18 @@
+CREATE TABLE crisis_flags (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    chat_session_id INTEGER NOT NULL REFERENCES chat_sessions(id) ON DELETE CASCADE,
+    chat_message_id INTEGER NOT NULL REFERENCES chat_messages(id) ON DELETE CASCADE,
+    risk_level VARCHAR(10) NOT NULL,
+    matched_terms TEXT,
+    source VARCHAR(100),
+    status VARCHAR(20) NOT NULL DEFAULT 'open',
+    review_notes TEXT,
+    reviewed_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    reviewed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_crisis_flags_status_created ON crisis_flags(status, created_at DESC);
+CREATE INDEX idx_crisis_flags_user ON crisis_flags(user_id);
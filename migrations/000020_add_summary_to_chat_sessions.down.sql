@@ -0,0 +1,2 @@
+ALTER TABLE chat_sessions DROP COLUMN summary;
+ALTER TABLE chat_sessions DROP COLUMN summarized_count;
@@ -0,0 +1,2 @@
+ALTER TABLE chat_sessions ADD COLUMN summary TEXT;
+ALTER TABLE chat_sessions ADD COLUMN summarized_count INTEGER DEFAULT 0;
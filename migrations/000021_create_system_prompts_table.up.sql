@@ -0,0 +1,16 @@
+CREATE TABLE system_prompts (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(50) NOT NULL,
+    locale VARCHAR(10) NOT NULL,
+    version INTEGER NOT NULL,
+    content TEXT NOT NULL,
+    notes VARCHAR(255),
+    is_active BOOLEAN DEFAULT FALSE,
+    weight INTEGER DEFAULT 100,
+    created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT idx_system_prompts_version UNIQUE (name, locale, version)
+);
+
+CREATE INDEX idx_system_prompts_active ON system_prompts(name, locale, is_active);
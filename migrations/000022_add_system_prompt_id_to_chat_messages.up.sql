@@ -0,0 +1,3 @@
+ALTER TABLE chat_messages ADD COLUMN system_prompt_id INTEGER REFERENCES system_prompts(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_chat_messages_system_prompt ON chat_messages(system_prompt_id);
@@ -0,0 +1,2 @@
+ALTER TABLE chat_messages DROP COLUMN model;
+ALTER TABLE chat_messages DROP COLUMN provider;
@@ -0,0 +1,2 @@
+ALTER TABLE chat_messages ADD COLUMN provider VARCHAR(30);
+ALTER TABLE chat_messages ADD COLUMN model VARCHAR(100);
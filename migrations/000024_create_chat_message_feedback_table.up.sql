@@ -0,0 +1,14 @@
+CREATE TABLE chat_message_feedback (
+    id SERIAL PRIMARY KEY,
+    chat_message_id INTEGER NOT NULL REFERENCES chat_messages(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    rating VARCHAR(10) NOT NULL,
+    reason TEXT,
+    system_prompt_id INTEGER REFERENCES system_prompts(id) ON DELETE SET NULL,
+    provider VARCHAR(30),
+    model VARCHAR(100),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_chat_message_feedback_message_created ON chat_message_feedback(chat_message_id, created_at DESC);
+CREATE INDEX idx_chat_message_feedback_created ON chat_message_feedback(created_at);
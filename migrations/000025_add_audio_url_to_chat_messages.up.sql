@@ -0,0 +1 @@
+ALTER TABLE chat_messages ADD COLUMN audio_url VARCHAR(500);
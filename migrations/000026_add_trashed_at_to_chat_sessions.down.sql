@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_chat_sessions_trashed_at;
+ALTER TABLE chat_sessions DROP COLUMN trashed_at;
@@ -0,0 +1,6 @@
+ALTER TABLE chat_sessions ADD COLUMN trashed_at TIMESTAMP WITH TIME ZONE;
+
+-- Sessions already in the trash start their retention period now
+UPDATE chat_sessions SET trashed_at = CURRENT_TIMESTAMP WHERE is_trash = true;
+
+CREATE INDEX idx_chat_sessions_trashed_at ON chat_sessions(trashed_at) WHERE is_trash = true;
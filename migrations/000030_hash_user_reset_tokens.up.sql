@@ -0,0 +1,5 @@
+-- Reset tokens are now stored as SHA-256 hashes; outstanding plain text
+-- tokens can never match and are dropped
+UPDATE users SET reset_token = NULL, reset_token_expiry = NULL WHERE reset_token IS NOT NULL;
+
+CREATE INDEX idx_users_reset_token ON users(reset_token);
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS user_recovery_codes;
+ALTER TABLE users DROP COLUMN two_factor_last_step;
+ALTER TABLE users DROP COLUMN two_factor_enabled_at;
+ALTER TABLE users DROP COLUMN two_factor_secret;
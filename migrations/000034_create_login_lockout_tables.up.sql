@@ -0,0 +1,26 @@
+-- Shared failure counters for LOGIN_THROTTLE_STORE=database
+CREATE TABLE login_attempts (
+    key VARCHAR(320) PRIMARY KEY,
+    failures INTEGER NOT NULL DEFAULT 0,
+    last_failure_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    locked_until TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX idx_login_attempts_last_failure ON login_attempts(last_failure_at);
+
+-- Audit trail of lockouts and their manual unlocks
+CREATE TABLE login_lockouts (
+    id SERIAL PRIMARY KEY,
+    scope VARCHAR(20) NOT NULL,
+    user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    email VARCHAR(255) NOT NULL,
+    ip_address VARCHAR(45) NOT NULL,
+    failures INTEGER NOT NULL,
+    locked_until TIMESTAMP WITH TIME ZONE NOT NULL,
+    unlocked_at TIMESTAMP WITH TIME ZONE,
+    unlocked_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_login_lockouts_user ON login_lockouts(user_id);
+CREATE INDEX idx_login_lockouts_created ON login_lockouts(created_at);
@@ -0,0 +1,17 @@
+-- Anonymous accounts can't exist without the pseudonym column, and rolling
+-- back must not delete them with their data. Upgrade or remove them first.
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM users WHERE email = '') THEN
+        RAISE EXCEPTION 'cannot roll back: % anonymous accounts have no email',
+            (SELECT COUNT(*) FROM users WHERE email = '');
+    END IF;
+END $$;
+
+DROP INDEX IF EXISTS idx_users_email;
+CREATE INDEX idx_users_email ON users(email);
+ALTER TABLE users ADD CONSTRAINT users_email_key UNIQUE (email);
+
+DROP INDEX IF EXISTS idx_users_pseudonym;
+ALTER TABLE users DROP COLUMN pseudonym;
+ALTER TABLE users DROP COLUMN is_anonymous;
@@ -0,0 +1,9 @@
+ALTER TABLE users ADD COLUMN is_anonymous BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE users ADD COLUMN pseudonym VARCHAR(50);
+
+CREATE UNIQUE INDEX idx_users_pseudonym ON users(LOWER(pseudonym));
+
+-- Anonymous accounts have no email; addresses stay unique among the rest
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_email_key;
+DROP INDEX IF EXISTS idx_users_email;
+CREATE UNIQUE INDEX idx_users_email ON users(email) WHERE email <> '';
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS user_mood_tags CASCADE;
+
+DROP INDEX IF EXISTS idx_user_moods_user_created;
+ALTER TABLE user_moods DROP COLUMN social_context;
+ALTER TABLE user_moods DROP COLUMN note;
+ALTER TABLE user_moods DROP COLUMN intensity;
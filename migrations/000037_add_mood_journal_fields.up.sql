@@ -0,0 +1,17 @@
+-- Mood check-ins become journal entries: several per day, each with an
+-- intensity, a note, who the user was with and activity/trigger tags
+ALTER TABLE user_moods ADD COLUMN intensity SMALLINT CHECK (intensity BETWEEN 1 AND 10);
+ALTER TABLE user_moods ADD COLUMN note TEXT NOT NULL DEFAULT '';
+ALTER TABLE user_moods ADD COLUMN social_context VARCHAR(20) NOT NULL DEFAULT '';
+
+CREATE INDEX idx_user_moods_user_created ON user_moods(user_id, created_at);
+
+CREATE TABLE user_mood_tags (
+    id SERIAL PRIMARY KEY,
+    user_mood_id INTEGER NOT NULL REFERENCES user_moods(id) ON DELETE CASCADE,
+    kind VARCHAR(20) NOT NULL,
+    name VARCHAR(30) NOT NULL
+);
+
+CREATE UNIQUE INDEX idx_user_mood_tags_entry ON user_mood_tags(user_mood_id, kind, name);
+CREATE INDEX idx_user_mood_tags_name ON user_mood_tags(kind, name);
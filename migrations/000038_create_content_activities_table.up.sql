@@ -0,0 +1,12 @@
+-- Songs played and articles read, so moods can be related to what users do
+-- in the app. content_id has no foreign key: history outlives the content.
+CREATE TABLE content_activities (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    content_type VARCHAR(20) NOT NULL,
+    content_id INTEGER NOT NULL,
+    category_id INTEGER,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_content_activities_user_created ON content_activities(user_id, created_at);
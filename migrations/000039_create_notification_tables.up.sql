@@ -0,0 +1,58 @@
+-- Mood check-in reminders. times are local "HH:MM" values, days a bitmask
+-- of weekdays (bit 0 is Sunday) and channels a comma-separated list.
+CREATE TABLE reminder_preferences (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL UNIQUE REFERENCES users(id) ON DELETE CASCADE,
+    enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    times VARCHAR(50) NOT NULL,
+    days SMALLINT NOT NULL,
+    timezone VARCHAR(64) NOT NULL DEFAULT 'Asia/Jakarta',
+    quiet_start VARCHAR(5) NOT NULL DEFAULT '',
+    quiet_end VARCHAR(5) NOT NULL DEFAULT '',
+    channels VARCHAR(100) NOT NULL,
+    locale VARCHAR(10) NOT NULL DEFAULT 'id',
+    next_reminder_at TIMESTAMP WITH TIME ZONE,
+    last_reminded_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_reminder_preferences_due ON reminder_preferences(next_reminder_at) WHERE enabled;
+
+-- Notification outbox: one row per channel, delivered by a background job.
+-- Delivered in_app rows double as the user's inbox.
+CREATE TABLE notifications (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(30) NOT NULL,
+    channel VARCHAR(20) NOT NULL,
+    locale VARCHAR(10) NOT NULL DEFAULT 'id',
+    title VARCHAR(255) NOT NULL,
+    body TEXT NOT NULL,
+    link VARCHAR(500) NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_error TEXT,
+    sent_at TIMESTAMP WITH TIME ZONE,
+    read_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_notifications_pending ON notifications(status, next_attempt_at);
+CREATE INDEX idx_notifications_user_channel ON notifications(user_id, channel, created_at);
+
+-- Browser push subscriptions (Web Push), one per browser
+CREATE TABLE push_subscriptions (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    endpoint TEXT NOT NULL UNIQUE,
+    p256dh VARCHAR(255) NOT NULL,
+    auth VARCHAR(255) NOT NULL,
+    user_agent VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_push_subscriptions_user_id ON push_subscriptions(user_id);
@@ -0,0 +1,7 @@
+ALTER TABLE reminder_preferences ADD COLUMN timezone VARCHAR(64) NOT NULL DEFAULT 'Asia/Jakarta';
+
+UPDATE reminder_preferences SET timezone = users.timezone
+FROM users
+WHERE users.id = reminder_preferences.user_id;
+
+ALTER TABLE users DROP COLUMN timezone;
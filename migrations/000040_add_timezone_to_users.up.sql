@@ -0,0 +1,9 @@
+-- Users' local days (mood "today", daily EXP limits, reminders) follow their
+-- own timezone. Reminders used a timezone of their own; it moves to the user.
+ALTER TABLE users ADD COLUMN timezone VARCHAR(64) NOT NULL DEFAULT 'Asia/Jakarta';
+
+UPDATE users SET timezone = reminder_preferences.timezone
+FROM reminder_preferences
+WHERE reminder_preferences.user_id = users.id;
+
+ALTER TABLE reminder_preferences DROP COLUMN timezone;
@@ -0,0 +1,24 @@
+DO $$
+DECLARE
+    col RECORD;
+BEGIN
+    FOR col IN
+        SELECT table_name, column_name
+        FROM information_schema.columns
+        WHERE table_schema = current_schema()
+          AND data_type = 'timestamp with time zone'
+          AND (table_name, column_name) IN (
+              ('users', 'reset_token_expiry'),
+              ('forum_categories', 'created_at'),
+              ('forum_categories', 'updated_at'),
+              ('forum_categories', 'deleted_at'),
+              ('forum_likes', 'created_at'),
+              ('forum_likes', 'updated_at')
+          )
+    LOOP
+        EXECUTE format(
+            'ALTER TABLE %I ALTER COLUMN %I TYPE TIMESTAMP USING %I AT TIME ZONE ''Asia/Jakarta''',
+            col.table_name, col.column_name, col.column_name
+        );
+    END LOOP;
+END $$;
@@ -0,0 +1,28 @@
+-- A few early columns were created without a time zone and hold
+-- Asia/Jakarta wall-clock times, the old session zone. Sessions now run in
+-- UTC, so they are converted to absolute times. updated_at on forum_likes
+-- exists only where AutoMigrate added it, hence the catalog lookup.
+DO $$
+DECLARE
+    col RECORD;
+BEGIN
+    FOR col IN
+        SELECT table_name, column_name
+        FROM information_schema.columns
+        WHERE table_schema = current_schema()
+          AND data_type = 'timestamp without time zone'
+          AND (table_name, column_name) IN (
+              ('users', 'reset_token_expiry'),
+              ('forum_categories', 'created_at'),
+              ('forum_categories', 'updated_at'),
+              ('forum_categories', 'deleted_at'),
+              ('forum_likes', 'created_at'),
+              ('forum_likes', 'updated_at')
+          )
+    LOOP
+        EXECUTE format(
+            'ALTER TABLE %I ALTER COLUMN %I TYPE TIMESTAMP WITH TIME ZONE USING %I AT TIME ZONE ''Asia/Jakarta''',
+            col.table_name, col.column_name, col.column_name
+        );
+    END LOOP;
+END $$;
@@ -0,0 +1,65 @@
+// Package clock resolves user timezones and the boundaries of their local
+// days, so "today" means the same thing for moods, EXP limits and reminders.
+package clock
+
+import (
+	"time"
+	_ "time/tzdata" // timezones work on hosts without zoneinfo
+)
+
+// DefaultTimezone is used for users who haven't set a timezone
+const DefaultTimezone = "Asia/Jakarta"
+
+// Valid reports whether name is an IANA timezone such as "Asia/Makassar"
+func Valid(name string) bool {
+	if name == "" || name == "Local" {
+		return false
+	}
+	_, err := time.LoadLocation(name)
+	return err == nil
+}
+
+// Location loads the timezone, falling back to the default for an empty or
+// unknown name
+func Location(name string) *time.Location {
+	if Valid(name) {
+		if loc, err := time.LoadLocation(name); err == nil {
+			return loc
+		}
+	}
+	return Default()
+}
+
+// Default is the app's default timezone, Indonesian western time
+func Default() *time.Location {
+	loc, err := time.LoadLocation(DefaultTimezone)
+	if err != nil {
+		return time.FixedZone("WIB", 7*60*60)
+	}
+	return loc
+}
+
+// StartOfDay returns midnight of t's day in loc
+func StartOfDay(t time.Time, loc *time.Location) time.Time {
+	local := t.In(loc)
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+}
+
+// DayBounds returns the start of t's day in loc and the start of the next
+// day. Days aren't always 24 hours long where clocks change.
+func DayBounds(t time.Time, loc *time.Location) (start, end time.Time) {
+	start = StartOfDay(t, loc)
+	return start, start.AddDate(0, 0, 1)
+}
+
+// Today is the start of the current day in loc
+func Today(loc *time.Location) time.Time {
+	return StartOfDay(time.Now(), loc)
+}
+
+// Date returns t's calendar date in loc as midnight UTC, the form DATE
+// columns store without shifting the day
+func Date(t time.Time, loc *time.Location) time.Time {
+	local := t.In(loc)
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,132 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/generative-ai-go/genai"
+	"google.golang.org/api/iterator"
+	"google.golang.org/api/option"
+)
+
+const DefaultGeminiModel = "gemini-flash-latest"
+
+type GeminiProvider struct {
+	client    *genai.Client
+	modelName string
+}
+
+func NewGeminiProvider(apiKey, modelName string) (*GeminiProvider, error) {
+	if apiKey == "" {
+		return nil, errors.New("llm: GEMINI_API_KEY is required for the gemini provider")
+	}
+	if modelName == "" {
+		modelName = DefaultGeminiModel
+	}
+
+	client, err := genai.NewClient(context.Background(), option.WithAPIKey(apiKey))
+	if err != nil {
+		return nil, fmt.Errorf("llm: failed to create Gemini client: %w", err)
+	}
+
+	return &GeminiProvider{client: client, modelName: modelName}, nil
+}
+
+func (p *GeminiProvider) Name() string {
+	return ProviderGemini
+}
+
+func (p *GeminiProvider) Model() string {
+	return p.modelName
+}
+
+func (p *GeminiProvider) Generate(ctx context.Context, systemPrompt string, history []Message) (string, error) {
+	if len(history) == 0 {
+		return "", errors.New("llm: empty history")
+	}
+
+	cs := p.startChat(systemPrompt, history[:len(history)-1])
+	resp, err := cs.SendMessage(ctx, genai.Text(history[len(history)-1].Content))
+	if err != nil {
+		return "", err
+	}
+
+	text := responseText(resp)
+	if text == "" {
+		return "", errors.New("llm: empty response from Gemini")
+	}
+	return text, nil
+}
+
+func (p *GeminiProvider) Stream(ctx context.Context, systemPrompt string, history []Message, onToken TokenFunc) (string, error) {
+	if len(history) == 0 {
+		return "", errors.New("llm: empty history")
+	}
+
+	cs := p.startChat(systemPrompt, history[:len(history)-1])
+	iter := cs.SendMessageStream(ctx, genai.Text(history[len(history)-1].Content))
+
+	var sb strings.Builder
+	for {
+		resp, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return sb.String(), err
+		}
+
+		chunk := responseText(resp)
+		if chunk == "" {
+			continue
+		}
+		sb.WriteString(chunk)
+		if err := onToken(chunk); err != nil {
+			return sb.String(), err
+		}
+	}
+
+	if sb.Len() == 0 {
+		return "", errors.New("llm: empty response from Gemini")
+	}
+	return sb.String(), nil
+}
+
+// startChat creates a chat on a fresh model handle so the system instruction
+// is never shared between concurrent requests.
+func (p *GeminiProvider) startChat(systemPrompt string, history []Message) *genai.ChatSession {
+	model := p.client.GenerativeModel(p.modelName)
+	if systemPrompt != "" {
+		model.SystemInstruction = genai.NewUserContent(genai.Text(systemPrompt))
+	}
+
+	cs := model.StartChat()
+	cs.History = make([]*genai.Content, 0, len(history))
+	for _, msg := range history {
+		role := "user"
+		if msg.Role == RoleAssistant {
+			role = "model"
+		}
+		cs.History = append(cs.History, &genai.Content{
+			Role:  role,
+			Parts: []genai.Part{genai.Text(msg.Content)},
+		})
+	}
+	return cs
+}
+
+func responseText(resp *genai.GenerateContentResponse) string {
+	if resp == nil || len(resp.Candidates) == 0 || resp.Candidates[0].Content == nil {
+		return ""
+	}
+
+	var sb strings.Builder
+	for _, part := range resp.Candidates[0].Content.Parts {
+		if txt, ok := part.(genai.Text); ok {
+			sb.WriteString(string(txt))
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,109 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"unicode/utf8"
+)
+
+type Role string
+
+const (
+	RoleUser      Role = "user"
+	RoleAssistant Role = "assistant"
+)
+
+const (
+	ProviderGemini = "gemini"
+	ProviderOpenAI = "openai"
+	ProviderStub   = "stub"
+)
+
+// Message is a single conversation turn passed to a provider
+type Message struct {
+	Role    Role
+	Content string
+}
+
+// TokenFunc receives reply chunks as they are produced. Returning an error
+// aborts the stream.
+type TokenFunc func(token string) error
+
+// Provider generates an assistant reply from a system prompt and the
+// conversation so far. The last entry of history is the message to answer.
+type Provider interface {
+	Name() string
+	Model() string
+	Generate(ctx context.Context, systemPrompt string, history []Message) (string, error)
+	// Stream behaves like Generate but calls onToken for every chunk and
+	// returns the text produced so far, even when it fails midway.
+	Stream(ctx context.Context, systemPrompt string, history []Message, onToken TokenFunc) (string, error)
+}
+
+// Options selects and configures a provider
+type Options struct {
+	Provider string // gemini, openai or stub
+	BaseURL  string // OpenAI-compatible servers only
+	APIKey   string
+	Model    string        // empty uses the provider's default
+	Timeout  time.Duration // bounds each request; zero leaves the provider's own limit
+}
+
+// NewProvider builds the provider selected by opts.Provider.
+// When no provider is given, Gemini is used if an API key is present,
+// otherwise the offline stub.
+func NewProvider(opts Options) (Provider, error) {
+	name := opts.Provider
+	if name == "" {
+		name = ProviderStub
+		if opts.APIKey != "" {
+			name = ProviderGemini
+		}
+	}
+
+	var provider Provider
+	var err error
+	switch name {
+	case ProviderGemini:
+		provider, err = NewGeminiProvider(opts.APIKey, opts.Model)
+	case ProviderOpenAI:
+		provider, err = NewOpenAIProvider(opts.BaseURL, opts.APIKey, opts.Model)
+	case ProviderStub:
+		provider = NewStubProvider()
+	default:
+		return nil, fmt.Errorf("llm: unknown provider %q", name)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Timeout > 0 {
+		provider = &timeoutProvider{Provider: provider, timeout: opts.Timeout}
+	}
+	return provider, nil
+}
+
+// timeoutProvider cancels requests that run longer than timeout
+type timeoutProvider struct {
+	Provider
+	timeout time.Duration
+}
+
+func (p *timeoutProvider) Generate(ctx context.Context, systemPrompt string, history []Message) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+	return p.Provider.Generate(ctx, systemPrompt, history)
+}
+
+func (p *timeoutProvider) Stream(ctx context.Context, systemPrompt string, history []Message, onToken TokenFunc) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+	return p.Provider.Stream(ctx, systemPrompt, history, onToken)
+}
+
+// EstimateTokens gives a rough, provider-independent token count for budgeting
+// (about four characters per token)
+func EstimateTokens(text string) int {
+	return utf8.RuneCountInString(text)/4 + 1
+}
@@ -0,0 +1,58 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// blockingProvider answers only once its context is done
+type blockingProvider struct {
+	*StubProvider
+}
+
+func (blockingProvider) Generate(ctx context.Context, _ string, _ []Message) (string, error) {
+	<-ctx.Done()
+	return "", ctx.Err()
+}
+
+func (blockingProvider) Stream(ctx context.Context, _ string, _ []Message, _ TokenFunc) (string, error) {
+	<-ctx.Done()
+	return "", ctx.Err()
+}
+
+func TestTimeoutProviderCancelsSlowRequests(t *testing.T) {
+	provider := &timeoutProvider{Provider: blockingProvider{NewStubProvider()}, timeout: 10 * time.Millisecond}
+	history := []Message{{Role: RoleUser, Content: "halo"}}
+
+	if _, err := provider.Generate(context.Background(), "", history); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Generate error = %v, want context.DeadlineExceeded", err)
+	}
+	if _, err := provider.Stream(context.Background(), "", history, func(string) error { return nil }); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Stream error = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestNewProviderWrapsTimeout(t *testing.T) {
+	tests := []struct {
+		name        string
+		timeout     time.Duration
+		wantTimeout bool
+	}{
+		{name: "with timeout", timeout: time.Minute, wantTimeout: true},
+		{name: "without timeout"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider, err := NewProvider(Options{Provider: ProviderStub, Timeout: tt.timeout})
+			if err != nil {
+				t.Fatalf("NewProvider: %v", err)
+			}
+			if _, ok := provider.(*timeoutProvider); ok != tt.wantTimeout {
+				t.Errorf("provider %T, want timeout wrapper %v", provider, tt.wantTimeout)
+			}
+		})
+	}
+}
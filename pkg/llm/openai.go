@@ -0,0 +1,191 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultOpenAIBaseURL = "https://api.openai.com/v1"
+	DefaultOpenAIModel   = "gpt-4o-mini"
+)
+
+// OpenAIProvider talks to any server implementing the OpenAI
+// /chat/completions API (OpenAI, Azure-compatible proxies, Ollama, vLLM, ...).
+type OpenAIProvider struct {
+	baseURL    string
+	apiKey     string
+	modelName  string
+	httpClient *http.Client
+}
+
+func NewOpenAIProvider(baseURL, apiKey, modelName string) (*OpenAIProvider, error) {
+	if baseURL == "" {
+		baseURL = DefaultOpenAIBaseURL
+	}
+	if modelName == "" {
+		modelName = DefaultOpenAIModel
+	}
+
+	return &OpenAIProvider{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		apiKey:     apiKey,
+		modelName:  modelName,
+		httpClient: &http.Client{Timeout: 120 * time.Second},
+	}, nil
+}
+
+func (p *OpenAIProvider) Name() string {
+	return ProviderOpenAI
+}
+
+func (p *OpenAIProvider) Model() string {
+	return p.modelName
+}
+
+type openAIMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type openAIRequest struct {
+	Model    string          `json:"model"`
+	Messages []openAIMessage `json:"messages"`
+	Stream   bool            `json:"stream,omitempty"`
+}
+
+type openAIResponse struct {
+	Choices []struct {
+		Message openAIMessage `json:"message"`
+	} `json:"choices"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+func (p *OpenAIProvider) Generate(ctx context.Context, systemPrompt string, history []Message) (string, error) {
+	resp, err := p.do(ctx, p.buildRequest(systemPrompt, history, false))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var body openAIResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("llm: failed to decode response: %w", err)
+	}
+	if body.Error != nil {
+		return "", fmt.Errorf("llm: %s", body.Error.Message)
+	}
+	if len(body.Choices) == 0 || body.Choices[0].Message.Content == "" {
+		return "", errors.New("llm: empty response from OpenAI-compatible API")
+	}
+
+	return body.Choices[0].Message.Content, nil
+}
+
+type openAIStreamChunk struct {
+	Choices []struct {
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+	} `json:"choices"`
+}
+
+func (p *OpenAIProvider) Stream(ctx context.Context, systemPrompt string, history []Message, onToken TokenFunc) (string, error) {
+	resp, err := p.do(ctx, p.buildRequest(systemPrompt, history, true))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var sb strings.Builder
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if data == "[DONE]" {
+			break
+		}
+
+		var chunk openAIStreamChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return sb.String(), fmt.Errorf("llm: failed to decode stream chunk: %w", err)
+		}
+		if len(chunk.Choices) == 0 || chunk.Choices[0].Delta.Content == "" {
+			continue
+		}
+
+		token := chunk.Choices[0].Delta.Content
+		sb.WriteString(token)
+		if err := onToken(token); err != nil {
+			return sb.String(), err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return sb.String(), err
+	}
+
+	if sb.Len() == 0 {
+		return "", errors.New("llm: empty response from OpenAI-compatible API")
+	}
+	return sb.String(), nil
+}
+
+func (p *OpenAIProvider) buildRequest(systemPrompt string, history []Message, stream bool) openAIRequest {
+	messages := make([]openAIMessage, 0, len(history)+1)
+	if systemPrompt != "" {
+		messages = append(messages, openAIMessage{Role: "system", Content: systemPrompt})
+	}
+	for _, msg := range history {
+		messages = append(messages, openAIMessage{Role: string(msg.Role), Content: msg.Content})
+	}
+
+	return openAIRequest{
+		Model:    p.modelName,
+		Messages: messages,
+		Stream:   stream,
+	}
+}
+
+func (p *OpenAIProvider) do(ctx context.Context, payload openAIRequest) (*http.Response, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/chat/completions", bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("llm: OpenAI-compatible API returned %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,70 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"strings"
+)
+
+var stubResponses = []string{
+	"Terima kasih sudah mempercayai saya. Mari kita bicarakan apa yang sedang kamu rasakan.",
+	"Saya di sini untukmu. Tidak apa-apa untuk merasa seperti ini. Apa yang ingin kamu ceritakan?",
+	"Perasaanmu sangat berarti. Saya harap kamu tahu bahwa selalu ada harapan dan bantuan tersedia.",
+	"Kamu sangat berani untuk berbagi ini. Bagaimana jika kita coba teknik pernapasan sederhana bersama?",
+}
+
+// StubProvider is an offline provider that returns a deterministic reply
+// chosen from the last user message. Useful for tests and local development.
+type StubProvider struct{}
+
+func NewStubProvider() *StubProvider {
+	return &StubProvider{}
+}
+
+func (p *StubProvider) Name() string {
+	return ProviderStub
+}
+
+func (p *StubProvider) Model() string {
+	return "stub"
+}
+
+func (p *StubProvider) Generate(ctx context.Context, systemPrompt string, history []Message) (string, error) {
+	if len(history) == 0 {
+		return "", errors.New("llm: empty history")
+	}
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(history[len(history)-1].Content))
+	return fmt.Sprintf("%s 💚", stubResponses[h.Sum32()%uint32(len(stubResponses))]), nil
+}
+
+// Stream emits the Generate reply word by word
+func (p *StubProvider) Stream(ctx context.Context, systemPrompt string, history []Message, onToken TokenFunc) (string, error) {
+	reply, err := p.Generate(ctx, systemPrompt, history)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	for i, word := range strings.Fields(reply) {
+		if err := ctx.Err(); err != nil {
+			return sb.String(), err
+		}
+
+		token := word
+		if i > 0 {
+			token = " " + word
+		}
+		sb.WriteString(token)
+		if err := onToken(token); err != nil {
+			return sb.String(), err
+		}
+	}
+	return sb.String(), nil
+}
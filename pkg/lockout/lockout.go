@@ -0,0 +1,129 @@
+// Package lockout tracks failed attempts per key, slowing repeated failures
+// down and locking the key out once they pass a limit. State lives in a
+// Store, so several instances can share it.
+package lockout
+
+import (
+	"time"
+)
+
+// Record is the failure state of one key
+type Record struct {
+	Failures      int
+	LastFailureAt time.Time
+	LockedUntil   time.Time
+}
+
+// Store keeps failure records. Implementations must be safe for concurrent
+// use; MemoryStore is per instance, shared stores make limits global.
+type Store interface {
+	// Get returns the record for key, or a zero Record
+	Get(key string) (Record, error)
+	// AddFailure counts a failure at now. The count restarts when the
+	// previous failure is older than window.
+	AddFailure(key string, now time.Time, window time.Duration) (Record, error)
+	// Lock locks key until the given time and clears its failure count
+	Lock(key string, until time.Time) error
+	// Reset forgets key
+	Reset(key string) error
+	// Prune deletes unlocked records under prefix whose last failure is
+	// before the given time
+	Prune(prefix string, before time.Time) (int64, error)
+}
+
+// Policy decides how failures are slowed down and when a key locks
+type Policy struct {
+	MaxFailures  int           // failures within Window that lock the key; 0 never locks
+	Window       time.Duration // failures older than this are forgotten
+	LockDuration time.Duration
+
+	DelayAfter int           // failures before delays start; 0 disables delays
+	BaseDelay  time.Duration // wait after the DelayAfter-th failure, doubled for each one after
+	MaxDelay   time.Duration
+}
+
+// delay is how long to wait after the given number of failures
+func (p Policy) delay(failures int) time.Duration {
+	if p.DelayAfter <= 0 || failures < p.DelayAfter || p.BaseDelay <= 0 {
+		return 0
+	}
+
+	delay := p.BaseDelay
+	for i := p.DelayAfter; i < failures && (p.MaxDelay <= 0 || delay < p.MaxDelay); i++ {
+		delay *= 2
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	return delay
+}
+
+// Guard applies a Policy to keys of one kind, such as accounts or client IPs
+type Guard struct {
+	store  Store
+	prefix string
+	policy Policy
+	now    func() time.Time
+}
+
+// New returns a guard whose keys are stored as prefix + ":" + id
+func New(store Store, prefix string, policy Policy) *Guard {
+	return &Guard{
+		store:  store,
+		prefix: prefix + ":",
+		policy: policy,
+		now:    time.Now,
+	}
+}
+
+// Check reports whether id may try now. When it may not, retryAfter is the
+// remaining lock or delay and locked tells which one it is.
+func (g *Guard) Check(id string) (retryAfter time.Duration, locked bool, err error) {
+	record, err := g.store.Get(g.prefix + id)
+	if err != nil {
+		return 0, false, err
+	}
+
+	now := g.now()
+	if record.LockedUntil.After(now) {
+		return record.LockedUntil.Sub(now), true, nil
+	}
+	if record.Failures == 0 || now.Sub(record.LastFailureAt) >= g.policy.Window {
+		return 0, false, nil
+	}
+
+	if next := record.LastFailureAt.Add(g.policy.delay(record.Failures)); next.After(now) {
+		return next.Sub(now), false, nil
+	}
+	return 0, false, nil
+}
+
+// Fail records a failed attempt. When it locks id, lockedUntil is when the
+// lock ends and failures is the count that triggered it.
+func (g *Guard) Fail(id string) (lockedUntil time.Time, failures int, err error) {
+	now := g.now()
+	record, err := g.store.AddFailure(g.prefix+id, now, g.policy.Window)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+
+	if g.policy.MaxFailures <= 0 || record.Failures < g.policy.MaxFailures {
+		return time.Time{}, record.Failures, nil
+	}
+
+	lockedUntil = now.Add(g.policy.LockDuration)
+	if err := g.store.Lock(g.prefix+id, lockedUntil); err != nil {
+		return time.Time{}, record.Failures, err
+	}
+	return lockedUntil, record.Failures, nil
+}
+
+// Reset clears failures and any lock for id
+func (g *Guard) Reset(id string) error {
+	return g.store.Reset(g.prefix + id)
+}
+
+// Prune deletes records of this guard that no longer affect anything
+func (g *Guard) Prune() (int64, error) {
+	return g.store.Prune(g.prefix, g.now().Add(-g.policy.Window))
+}
@@ -0,0 +1,274 @@
+package lockout
+
+import (
+	"testing"
+	"time"
+)
+
+// fakeClock is a settable time source for guards
+type fakeClock struct {
+	t time.Time
+}
+
+func (c *fakeClock) now() time.Time { return c.t }
+
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+// newTestGuard returns a guard on a fresh memory store driven by a fake clock
+func newTestGuard(policy Policy) (*Guard, *fakeClock) {
+	clock := &fakeClock{t: time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)}
+	g := New(NewMemoryStore(), "account", policy)
+	g.now = clock.now
+	return g, clock
+}
+
+func TestPolicyDelay(t *testing.T) {
+	progressive := Policy{DelayAfter: 3, BaseDelay: time.Second, MaxDelay: 8 * time.Second}
+
+	tests := []struct {
+		name     string
+		policy   Policy
+		failures int
+		want     time.Duration
+	}{
+		{name: "no failures", policy: progressive, failures: 0},
+		{name: "before delays start", policy: progressive, failures: 2},
+		{name: "first delay", policy: progressive, failures: 3, want: time.Second},
+		{name: "doubles", policy: progressive, failures: 4, want: 2 * time.Second},
+		{name: "doubles again", policy: progressive, failures: 5, want: 4 * time.Second},
+		{name: "reaches the cap", policy: progressive, failures: 6, want: 8 * time.Second},
+		{name: "stays at the cap", policy: progressive, failures: 50, want: 8 * time.Second},
+		{name: "cap below the base", policy: Policy{DelayAfter: 1, BaseDelay: 5 * time.Second, MaxDelay: 2 * time.Second}, failures: 1, want: 2 * time.Second},
+		{name: "uncapped", policy: Policy{DelayAfter: 1, BaseDelay: time.Second}, failures: 5, want: 16 * time.Second},
+		{name: "delays disabled", policy: Policy{BaseDelay: time.Second}, failures: 10},
+		{name: "no base delay", policy: Policy{DelayAfter: 1}, failures: 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.delay(tt.failures); got != tt.want {
+				t.Errorf("delay(%d) = %v, want %v", tt.failures, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGuardDelaysRepeatedFailures(t *testing.T) {
+	g, clock := newTestGuard(Policy{Window: 15 * time.Minute, DelayAfter: 2, BaseDelay: time.Second, MaxDelay: time.Minute})
+
+	fail := func() {
+		t.Helper()
+		if _, _, err := g.Fail("dewi"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	check := func(wantRetry time.Duration) {
+		t.Helper()
+		retryAfter, locked, err := g.Check("dewi")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if locked || retryAfter != wantRetry {
+			t.Errorf("Check = %v, locked %v; want %v, not locked", retryAfter, locked, wantRetry)
+		}
+	}
+
+	fail()
+	check(0)
+	fail()
+	check(time.Second)
+
+	clock.advance(400 * time.Millisecond)
+	check(600 * time.Millisecond)
+	clock.advance(600 * time.Millisecond)
+	check(0)
+
+	fail()
+	check(2 * time.Second)
+
+	// Failures outside the window no longer slow the key down
+	clock.advance(15 * time.Minute)
+	check(0)
+}
+
+func TestGuardLocksAtThreshold(t *testing.T) {
+	g, clock := newTestGuard(Policy{MaxFailures: 3, Window: 15 * time.Minute, LockDuration: 30 * time.Minute})
+	start := clock.now()
+
+	for i := 1; i <= 2; i++ {
+		lockedUntil, failures, err := g.Fail("dewi")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !lockedUntil.IsZero() || failures != i {
+			t.Fatalf("failure %d: lockedUntil %v, failures %d; want no lock", i, lockedUntil, failures)
+		}
+		clock.advance(time.Minute)
+	}
+
+	lockedUntil, failures, err := g.Fail("dewi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := start.Add(2*time.Minute + 30*time.Minute); !lockedUntil.Equal(want) || failures != 3 {
+		t.Fatalf("third failure: lockedUntil %v, failures %d; want %v, 3", lockedUntil, failures, want)
+	}
+
+	// Another guard key is unaffected
+	if retryAfter, locked, _ := g.Check("budi"); locked || retryAfter != 0 {
+		t.Errorf("other key: retry %v, locked %v", retryAfter, locked)
+	}
+
+	clock.advance(10 * time.Minute)
+	retryAfter, locked, err := g.Check("dewi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !locked || retryAfter != 20*time.Minute {
+		t.Errorf("during lock: retry %v, locked %v; want 20m, locked", retryAfter, locked)
+	}
+
+	// The lock expires and the count starts over
+	clock.advance(20 * time.Minute)
+	if retryAfter, locked, _ := g.Check("dewi"); locked || retryAfter != 0 {
+		t.Errorf("after lock: retry %v, locked %v; want free", retryAfter, locked)
+	}
+	if lockedUntil, failures, _ := g.Fail("dewi"); !lockedUntil.IsZero() || failures != 1 {
+		t.Errorf("first failure after lock: lockedUntil %v, failures %d; want no lock, 1", lockedUntil, failures)
+	}
+}
+
+func TestGuardForgetsFailuresOutsideWindow(t *testing.T) {
+	g, clock := newTestGuard(Policy{MaxFailures: 3, Window: 10 * time.Minute, LockDuration: time.Hour})
+
+	for i := 0; i < 2; i++ {
+		if _, _, err := g.Fail("dewi"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	clock.advance(10 * time.Minute)
+
+	lockedUntil, failures, err := g.Fail("dewi")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !lockedUntil.IsZero() || failures != 1 {
+		t.Errorf("lockedUntil %v, failures %d; want no lock, count restarted at 1", lockedUntil, failures)
+	}
+}
+
+func TestGuardNeverLocksWithoutLimit(t *testing.T) {
+	g, _ := newTestGuard(Policy{Window: time.Hour, LockDuration: time.Hour})
+
+	for i := 0; i < 100; i++ {
+		if lockedUntil, _, _ := g.Fail("dewi"); !lockedUntil.IsZero() {
+			t.Fatalf("locked after %d failures with MaxFailures 0", i+1)
+		}
+	}
+}
+
+func TestGuardReset(t *testing.T) {
+	g, _ := newTestGuard(Policy{MaxFailures: 1, Window: time.Hour, LockDuration: time.Hour})
+
+	if lockedUntil, _, _ := g.Fail("dewi"); lockedUntil.IsZero() {
+		t.Fatal("not locked")
+	}
+	if err := g.Reset("dewi"); err != nil {
+		t.Fatal(err)
+	}
+	if retryAfter, locked, _ := g.Check("dewi"); locked || retryAfter != 0 {
+		t.Errorf("after reset: retry %v, locked %v; want free", retryAfter, locked)
+	}
+}
+
+func TestGuardsShareStoreByPrefix(t *testing.T) {
+	store := NewMemoryStore()
+	policy := Policy{MaxFailures: 2, Window: time.Hour, LockDuration: time.Hour}
+	byAccount := New(store, "account", policy)
+	byIP := New(store, "ip", policy)
+
+	// The same id under different prefixes counts separately
+	for i := 0; i < 2; i++ {
+		if _, _, err := byAccount.Fail("10.0.0.1"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, locked, _ := byAccount.Check("10.0.0.1"); !locked {
+		t.Error("account key not locked")
+	}
+	if _, locked, _ := byIP.Check("10.0.0.1"); locked {
+		t.Error("IP key locked by account failures")
+	}
+}
+
+func TestMemoryStoreAddFailure(t *testing.T) {
+	store := NewMemoryStore()
+	now := time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		after time.Duration // since the previous failure
+		want  int
+	}{
+		{after: 0, want: 1},
+		{after: time.Minute, want: 2},
+		{after: 9 * time.Minute, want: 3},
+		{after: 10 * time.Minute, want: 1}, // a full window since the last failure
+		{after: time.Second, want: 2},
+	}
+
+	for _, tt := range tests {
+		now = now.Add(tt.after)
+		record, err := store.AddFailure("account:dewi", now, 10*time.Minute)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if record.Failures != tt.want || !record.LastFailureAt.Equal(now) {
+			t.Errorf("after %v: %+v, want %d failures at %v", tt.after, record, tt.want, now)
+		}
+	}
+}
+
+func TestMemoryStorePrune(t *testing.T) {
+	store := NewMemoryStore()
+	now := time.Now()
+	old := now.Add(-time.Hour)
+
+	mustAdd := func(key string, at time.Time) {
+		t.Helper()
+		if _, err := store.AddFailure(key, at, time.Hour); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mustAdd("account:stale", old)
+	mustAdd("account:recent", now)
+	mustAdd("account:locked", old)
+	if err := store.Lock("account:locked", now.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	mustAdd("account:lock-expired", old)
+	if err := store.Lock("account:lock-expired", now.Add(-time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	mustAdd("ip:stale", old)
+
+	deleted, err := store.Prune("account:", now.Add(-30*time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted != 2 {
+		t.Errorf("pruned %d records, want 2", deleted)
+	}
+
+	for key, wantKept := range map[string]bool{
+		"account:stale":        false,
+		"account:lock-expired": false,
+		"account:recent":       true,
+		"account:locked":       true,
+		"ip:stale":             true,
+	} {
+		record, _ := store.Get(key)
+		if kept := record != (Record{}); kept != wantKept {
+			t.Errorf("%s kept = %v, want %v", key, kept, wantKept)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package lockout
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps records in process memory. Limits are per instance.
+type MemoryStore struct {
+	mu      sync.Mutex
+	records map[string]Record
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{records: make(map[string]Record)}
+}
+
+func (s *MemoryStore) Get(key string) (Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.records[key], nil
+}
+
+func (s *MemoryStore) AddFailure(key string, now time.Time, window time.Duration) (Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record := s.records[key]
+	if now.Sub(record.LastFailureAt) >= window {
+		record.Failures = 0
+	}
+	record.Failures++
+	record.LastFailureAt = now
+	s.records[key] = record
+	return record, nil
+}
+
+func (s *MemoryStore) Lock(key string, until time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record := s.records[key]
+	record.Failures = 0
+	record.LockedUntil = until
+	s.records[key] = record
+	return nil
+}
+
+func (s *MemoryStore) Reset(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.records, key)
+	return nil
+}
+
+func (s *MemoryStore) Prune(prefix string, before time.Time) (int64, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var deleted int64
+	for key, record := range s.records {
+		if strings.HasPrefix(key, prefix) && record.LastFailureAt.Before(before) && !record.LockedUntil.After(now) {
+			delete(s.records, key)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
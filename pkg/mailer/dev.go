@@ -0,0 +1,77 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Alfian57/ruang-tenang-api/pkg/logger"
+	"go.uber.org/zap"
+)
+
+const DefaultFileDir = "storage/mail"
+
+// FileMailer writes every message as an .eml file, which most mail clients
+// can open. Meant for local development.
+type FileMailer struct {
+	dir  string
+	from Address
+}
+
+func NewFileMailer(dir string, from Address) (*FileMailer, error) {
+	if dir == "" {
+		dir = DefaultFileDir
+	}
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("mailer: create %s: %w", dir, err)
+	}
+	return &FileMailer{dir: dir, from: from}, nil
+}
+
+func (m *FileMailer) Name() string {
+	return DriverFile
+}
+
+func (m *FileMailer) Send(ctx context.Context, msg Message) error {
+	data, err := build(m.from, msg)
+	if err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s_%s.eml", time.Now().Format("20060102-150405.000"), sanitize(msg.To))
+	return os.WriteFile(filepath.Join(m.dir, name), data, 0o640)
+}
+
+// ConsoleMailer logs the text body of every message instead of sending it
+type ConsoleMailer struct {
+	from Address
+}
+
+func NewConsoleMailer(from Address) *ConsoleMailer {
+	return &ConsoleMailer{from: from}
+}
+
+func (m *ConsoleMailer) Name() string {
+	return DriverConsole
+}
+
+func (m *ConsoleMailer) Send(ctx context.Context, msg Message) error {
+	logger.Info("Email (console driver)",
+		zap.String("to", msg.To),
+		zap.String("subject", msg.Subject),
+		zap.String("body", msg.Text),
+	)
+	return nil
+}
+
+func sanitize(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '.' || r == '-' {
+			return r
+		}
+		return '_'
+	}, s)
+}
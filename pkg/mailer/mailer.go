@@ -0,0 +1,53 @@
+// Package mailer delivers email through SMTP or, for development, by writing
+// messages to files or the log.
+package mailer
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Alfian57/ruang-tenang-api/internal/config"
+)
+
+const (
+	DriverSMTP    = "smtp"
+	DriverFile    = "file"
+	DriverConsole = "console"
+)
+
+// Message is a single email with a plain text and an optional HTML body
+type Message struct {
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Mailer sends a message or returns an error that the caller may retry
+type Mailer interface {
+	Name() string
+	Send(ctx context.Context, msg Message) error
+}
+
+// NewMailer builds the mailer selected by cfg.MailDriver, defaulting to the
+// console driver
+func NewMailer(cfg *config.Config) (Mailer, error) {
+	from := Address{Name: cfg.MailFromName, Email: cfg.MailFrom}
+
+	switch cfg.MailDriver {
+	case DriverSMTP:
+		return NewSMTPMailer(SMTPConfig{
+			Host:       cfg.SMTPHost,
+			Port:       cfg.SMTPPort,
+			Username:   cfg.SMTPUsername,
+			Password:   cfg.SMTPPassword,
+			Encryption: cfg.SMTPEncryption,
+		}, from)
+	case DriverFile:
+		return NewFileMailer(cfg.MailFileDir, from)
+	case DriverConsole, "":
+		return NewConsoleMailer(from), nil
+	default:
+		return nil, fmt.Errorf("mailer: unknown driver %q", cfg.MailDriver)
+	}
+}
@@ -0,0 +1,115 @@
+package mailer
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"time"
+)
+
+// SMTP encryption modes
+const (
+	EncryptionSTARTTLS = "starttls"
+	EncryptionTLS      = "tls" // implicit TLS, usually port 465
+	EncryptionNone     = "none"
+)
+
+type SMTPConfig struct {
+	Host       string
+	Port       int
+	Username   string
+	Password   string
+	Encryption string
+}
+
+type SMTPMailer struct {
+	cfg  SMTPConfig
+	from Address
+}
+
+func NewSMTPMailer(cfg SMTPConfig, from Address) (*SMTPMailer, error) {
+	if cfg.Host == "" {
+		return nil, errors.New("mailer: SMTP_HOST is required for the smtp driver")
+	}
+	if from.Email == "" {
+		return nil, errors.New("mailer: MAIL_FROM is required for the smtp driver")
+	}
+	if cfg.Port == 0 {
+		cfg.Port = 587
+	}
+	if cfg.Encryption == "" {
+		cfg.Encryption = EncryptionSTARTTLS
+	}
+
+	return &SMTPMailer{cfg: cfg, from: from}, nil
+}
+
+func (m *SMTPMailer) Name() string {
+	return DriverSMTP
+}
+
+func (m *SMTPMailer) Send(ctx context.Context, msg Message) error {
+	data, err := build(m.from, msg)
+	if err != nil {
+		return err
+	}
+
+	addr := net.JoinHostPort(m.cfg.Host, strconv.Itoa(m.cfg.Port))
+	dialer := &net.Dialer{Timeout: 30 * time.Second}
+
+	var conn net.Conn
+	if m.cfg.Encryption == EncryptionTLS {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: m.cfg.Host}}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("mailer: connect %s: %w", addr, err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, m.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("mailer: %w", err)
+	}
+	defer client.Close()
+
+	if m.cfg.Encryption == EncryptionSTARTTLS {
+		if err := client.StartTLS(&tls.Config{ServerName: m.cfg.Host}); err != nil {
+			return fmt.Errorf("mailer: starttls: %w", err)
+		}
+	}
+
+	if m.cfg.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", m.cfg.Username, m.cfg.Password, m.cfg.Host)); err != nil {
+			return fmt.Errorf("mailer: auth: %w", err)
+		}
+	}
+
+	if err := client.Mail(m.from.Email); err != nil {
+		return fmt.Errorf("mailer: MAIL FROM: %w", err)
+	}
+	if err := client.Rcpt(msg.To); err != nil {
+		return fmt.Errorf("mailer: RCPT TO: %w", err)
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("mailer: DATA: %w", err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("mailer: write: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("mailer: %w", err)
+	}
+
+	return client.Quit()
+}
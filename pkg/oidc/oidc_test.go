@@ -0,0 +1,276 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	testClientID = "ruang-tenang"
+	testKeyID    = "test-key"
+)
+
+// mockIssuer is a minimal OpenID provider: discovery, signing keys and a
+// token endpoint that answers with the ID token the test asks for
+type mockIssuer struct {
+	*httptest.Server
+	key *rsa.PrivateKey
+
+	idToken  func(issuer string) string // the ID token returned for the next code
+	verifier string                     // the PKCE verifier of the last exchange
+	claimed  string                     // issuer named in discovery, defaults to the server URL
+}
+
+func newMockIssuer(t *testing.T) *mockIssuer {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := &mockIssuer{key: key}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		claimed := m.claimed
+		if claimed == "" {
+			claimed = m.URL
+		}
+		writeJSON(w, discoveryDocument{
+			Issuer:                claimed,
+			AuthorizationEndpoint: m.URL + "/authorize",
+			TokenEndpoint:         m.URL + "/token",
+			JWKSURI:               m.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]interface{}{"keys": []jsonWebKey{{
+			Kid: testKeyID,
+			Kty: "RSA",
+			Use: "sig",
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		m.verifier = r.PostForm.Get("code_verifier")
+		writeJSON(w, map[string]interface{}{
+			"access_token": "access",
+			"token_type":   "Bearer",
+			"id_token":     m.idToken(m.URL),
+		})
+	})
+
+	m.Server = httptest.NewServer(mux)
+	t.Cleanup(m.Close)
+	return m
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// sign creates an RS256 ID token with the given key ID
+func sign(t *testing.T, key *rsa.PrivateKey, kid string, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signed
+}
+
+func TestExchange(t *testing.T) {
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	validClaims := func(issuer string) jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss":            issuer,
+			"aud":            testClientID,
+			"sub":            "user-123",
+			"iat":            now.Unix(),
+			"exp":            now.Add(time.Hour).Unix(),
+			"nonce":          "nonce-1",
+			"email":          " Dewi@Example.com ",
+			"email_verified": true,
+			"name":           "Dewi",
+		}
+	}
+
+	tests := []struct {
+		name    string
+		claims  func(issuer string) jwt.MapClaims
+		key     *rsa.PrivateKey
+		kid     string
+		want    *Claims
+		wantErr bool
+	}{
+		{
+			name:   "valid",
+			claims: validClaims,
+			want:   &Claims{Subject: "user-123", Email: "dewi@example.com", EmailVerified: true, Name: "Dewi"},
+		},
+		{
+			name: "email_verified as a string",
+			claims: func(issuer string) jwt.MapClaims {
+				c := validClaims(issuer)
+				c["email_verified"] = "true"
+				return c
+			},
+			want: &Claims{Subject: "user-123", Email: "dewi@example.com", EmailVerified: true, Name: "Dewi"},
+		},
+		{
+			name: "wrong nonce",
+			claims: func(issuer string) jwt.MapClaims {
+				c := validClaims(issuer)
+				c["nonce"] = "replayed"
+				return c
+			},
+			wantErr: true,
+		},
+		{
+			name: "other audience",
+			claims: func(issuer string) jwt.MapClaims {
+				c := validClaims(issuer)
+				c["aud"] = "someone-else"
+				return c
+			},
+			wantErr: true,
+		},
+		{
+			name: "other issuer",
+			claims: func(issuer string) jwt.MapClaims {
+				c := validClaims(issuer)
+				c["iss"] = "https://evil.example.com"
+				return c
+			},
+			wantErr: true,
+		},
+		{
+			name: "expired",
+			claims: func(issuer string) jwt.MapClaims {
+				c := validClaims(issuer)
+				c["exp"] = now.Add(-time.Hour).Unix()
+				return c
+			},
+			wantErr: true,
+		},
+		{
+			name: "missing subject",
+			claims: func(issuer string) jwt.MapClaims {
+				c := validClaims(issuer)
+				delete(c, "sub")
+				return c
+			},
+			wantErr: true,
+		},
+		{
+			name:    "signed with an unknown key",
+			claims:  validClaims,
+			key:     otherKey,
+			kid:     "unknown",
+			wantErr: true,
+		},
+		{
+			name:    "signed with another key under a known key ID",
+			claims:  validClaims,
+			key:     otherKey,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issuer := newMockIssuer(t)
+			key, kid := issuer.key, testKeyID
+			if tt.key != nil {
+				key = tt.key
+			}
+			if tt.kid != "" {
+				kid = tt.kid
+			}
+			issuer.idToken = func(iss string) string { return sign(t, key, kid, tt.claims(iss)) }
+
+			p := NewProvider("mock", Config{Issuer: issuer.URL, ClientID: testClientID, RedirectURL: "http://localhost/callback"})
+			got, err := p.Exchange(context.Background(), "code", "verifier-1", "nonce-1")
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidIDToken) {
+					t.Fatalf("Exchange error = %v, want ErrInvalidIDToken", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Exchange: %v", err)
+			}
+			if *got != *tt.want {
+				t.Errorf("Exchange = %+v, want %+v", got, tt.want)
+			}
+			if issuer.verifier != "verifier-1" {
+				t.Errorf("token endpoint got verifier %q, want the PKCE verifier", issuer.verifier)
+			}
+		})
+	}
+}
+
+func TestAuthCodeURL(t *testing.T) {
+	issuer := newMockIssuer(t)
+	p := NewProvider("mock", Config{Issuer: issuer.URL, ClientID: testClientID, RedirectURL: "http://localhost/callback"})
+
+	raw, err := p.AuthCodeURL(context.Background(), "state-1", "nonce-1", "verifier-1")
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sum := sha256.Sum256([]byte("verifier-1"))
+	want := map[string]string{
+		"client_id":             testClientID,
+		"state":                 "state-1",
+		"nonce":                 "nonce-1",
+		"scope":                 "openid email profile",
+		"code_challenge":        base64.RawURLEncoding.EncodeToString(sum[:]),
+		"code_challenge_method": "S256",
+	}
+	if got := u.Scheme + "://" + u.Host + u.Path; got != issuer.URL+"/authorize" {
+		t.Errorf("authorization endpoint = %s", got)
+	}
+	for param, value := range want {
+		if got := u.Query().Get(param); got != value {
+			t.Errorf("%s = %q, want %q", param, got, value)
+		}
+	}
+}
+
+func TestDiscoveryRejectsMismatchedIssuer(t *testing.T) {
+	issuer := newMockIssuer(t)
+	issuer.claimed = "https://accounts.example.com"
+	p := NewProvider("mock", Config{Issuer: issuer.URL, ClientID: testClientID})
+
+	if _, err := p.AuthCodeURL(context.Background(), "state", "nonce", "verifier"); err == nil {
+		t.Error("AuthCodeURL succeeded for an issuer that doesn't match its discovery document")
+	}
+}
@@ -0,0 +1,58 @@
+package oidc
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/Alfian57/ruang-tenang-api/internal/config"
+)
+
+const ProviderGoogle = "google"
+
+// Registry holds the configured providers by name
+type Registry struct {
+	providers map[string]*Provider
+}
+
+// NewRegistry builds the providers that have a client ID configured
+func NewRegistry(cfg *config.Config) *Registry {
+	r := &Registry{providers: make(map[string]*Provider)}
+
+	if cfg.OIDCGoogleClientID != "" {
+		issuer := cfg.OIDCGoogleIssuer
+		if issuer == "" {
+			issuer = GoogleIssuer
+		}
+		redirectURL := cfg.OIDCRedirectURL
+		if redirectURL == "" {
+			redirectURL = strings.TrimRight(cfg.ClientOrigin, "/") + "/auth/callback"
+		}
+
+		r.providers[ProviderGoogle] = NewProvider(ProviderGoogle, Config{
+			Issuer:       issuer,
+			ClientID:     cfg.OIDCGoogleClientID,
+			ClientSecret: cfg.OIDCGoogleClientSecret,
+			RedirectURL:  redirectURL,
+		})
+	}
+
+	return r
+}
+
+func (r *Registry) Get(name string) (*Provider, error) {
+	provider, ok := r.providers[name]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+	return provider, nil
+}
+
+// Names lists the configured providers
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.providers))
+	for name := range r.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,61 @@
+package pdf
+
+import "strings"
+
+// winAnsiExtras maps the characters WinAnsiEncoding places in 0x80-0x9F
+var winAnsiExtras = map[rune]byte{
+	'€': 0x80, '‚': 0x82, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87,
+	'‰': 0x89, '‹': 0x8B, '‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94,
+	'•': 0x95, '–': 0x96, '—': 0x97, '™': 0x99, '›': 0x9B,
+}
+
+// encode converts text to single-byte WinAnsi, turning tabs into spaces and
+// dropping characters the standard fonts can't show
+func encode(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '\t':
+			b.WriteString("    ")
+		case r >= 0x20 && r < 0x7F:
+			b.WriteByte(byte(r))
+		case r >= 0xA0 && r <= 0xFF:
+			b.WriteByte(byte(r))
+		default:
+			if c, ok := winAnsiExtras[r]; ok {
+				b.WriteByte(c)
+			}
+		}
+	}
+	return b.String()
+}
+
+// helveticaWidths are the glyph widths of Helvetica for ASCII 32-126, in
+// thousandths of the font size
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+// textWidth measures encoded text in points. Bold glyphs are treated as
+// slightly wider than regular ones, which is close enough for wrapping.
+func textWidth(s string, size float64, bold bool) float64 {
+	total := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c >= 32 && c <= 126 {
+			total += helveticaWidths[c-32]
+		} else {
+			total += 556
+		}
+	}
+	width := float64(total) * size / 1000
+	if bold {
+		width *= 1.06
+	}
+	return width
+}
@@ -0,0 +1,195 @@
+// Package pdf writes simple text documents as PDF using the standard
+// Helvetica fonts, so no font files or external libraries are needed.
+// Text is limited to the WinAnsi (Latin-1) character set; other characters
+// such as emoji are dropped.
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"strings"
+)
+
+// A4 page size and margins in points
+const (
+	pageWidth    = 595.0
+	pageHeight   = 842.0
+	marginX      = 56.0
+	marginTop    = 64.0
+	marginBottom = 64.0
+	lineSpacing  = 1.35
+)
+
+// Style controls how a paragraph is drawn
+type Style struct {
+	Size float64
+	Bold bool
+	// Gray is the text colour from 0 (black) to 1 (white)
+	Gray float64
+	// Indent shifts the paragraph right, in points
+	Indent float64
+}
+
+var (
+	Title   = Style{Size: 18, Bold: true}
+	Heading = Style{Size: 11, Bold: true}
+	Body    = Style{Size: 10.5}
+	Small   = Style{Size: 8.5, Gray: 0.4}
+)
+
+// Document collects pages of text. The zero value is not usable; call New.
+type Document struct {
+	pages []*bytes.Buffer
+	y     float64
+}
+
+func New() *Document {
+	d := &Document{}
+	d.addPage()
+	return d
+}
+
+func (d *Document) addPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+	d.y = pageHeight - marginTop
+}
+
+func (d *Document) current() *bytes.Buffer {
+	return d.pages[len(d.pages)-1]
+}
+
+// Space adds vertical space, starting a new page when it runs out
+func (d *Document) Space(height float64) {
+	d.y -= height
+	if d.y < marginBottom {
+		d.addPage()
+	}
+}
+
+// Rule draws a thin horizontal line across the text area
+func (d *Document) Rule() {
+	d.Space(6)
+	fmt.Fprintf(d.current(), "0.8 G 0.5 w %.2f %.2f m %.2f %.2f l S\n", marginX, d.y, pageWidth-marginX, d.y)
+	d.Space(10)
+}
+
+// Paragraph draws text wrapped to the page width. Newlines in text start a
+// new line.
+func (d *Document) Paragraph(text string, style Style) {
+	size := style.Size
+	if size <= 0 {
+		size = Body.Size
+	}
+	font := "F1"
+	if style.Bold {
+		font = "F2"
+	}
+	leading := size * lineSpacing
+	maxWidth := pageWidth - 2*marginX - style.Indent
+
+	for _, raw := range strings.Split(text, "\n") {
+		lines := wrap(encode(raw), size, style.Bold, maxWidth)
+		for _, line := range lines {
+			if d.y-leading < marginBottom {
+				d.addPage()
+			}
+			d.y -= leading
+			fmt.Fprintf(d.current(), "BT /%s %.2f Tf %.2f g %.2f %.2f Td (%s) Tj ET\n",
+				font, size, style.Gray, marginX+style.Indent, d.y, escape(line))
+		}
+	}
+}
+
+// Bytes renders the document. Every page gets a centred page number.
+func (d *Document) Bytes() ([]byte, error) {
+	var out bytes.Buffer
+	var offsets []int
+
+	// Object numbers: 1 catalog, 2 page tree, 3-4 fonts, then a page and a
+	// content stream per page
+	object := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	out.WriteString("%PDF-1.4\n%\xE2\xE3\xCF\xD3\n")
+
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+i*2)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+
+	for i, page := range d.pages {
+		content := page.Bytes()
+		number := fmt.Sprintf("%d / %d", i+1, len(d.pages))
+		content = append(content, fmt.Sprintf("BT /F1 8 Tf 0.5 g %.2f %.2f Td (%s) Tj ET\n",
+			(pageWidth-textWidth(number, 8, false))/2, marginBottom/2, number)...)
+
+		var compressed bytes.Buffer
+		zw := zlib.NewWriter(&compressed)
+		if _, err := zw.Write(content); err != nil {
+			return nil, err
+		}
+		if err := zw.Close(); err != nil {
+			return nil, err
+		}
+
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			pageWidth, pageHeight, 6+i*2))
+		object(fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", compressed.Len(), compressed.Bytes()))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return out.Bytes(), nil
+}
+
+// wrap splits WinAnsi-encoded text into lines no wider than maxWidth,
+// breaking long words when they don't fit on a line of their own
+func wrap(text string, size float64, bold bool, maxWidth float64) []string {
+	words := strings.Fields(text)
+	if len(words) == 0 {
+		return []string{""}
+	}
+
+	var lines []string
+	line := ""
+	for _, word := range words {
+		candidate := word
+		if line != "" {
+			candidate = line + " " + word
+		}
+		if textWidth(candidate, size, bold) <= maxWidth {
+			line = candidate
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+		for textWidth(word, size, bold) > maxWidth {
+			cut := len(word) - 1
+			for cut > 1 && textWidth(word[:cut], size, bold) > maxWidth {
+				cut--
+			}
+			lines = append(lines, word[:cut])
+			word = word[cut:]
+		}
+		line = word
+	}
+	return append(lines, line)
+}
+
+func escape(s string) string {
+	r := strings.NewReplacer(`\`, `\\`, "(", `\(`, ")", `\)`)
+	return r.Replace(s)
+}
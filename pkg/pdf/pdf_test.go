@@ -0,0 +1,244 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// parsed is a rendered document read back through its cross-reference table
+type parsed struct {
+	objects map[int]string // object number to its body
+	size    int            // /Size from the trailer
+}
+
+// parse checks the xref table against the file and returns the objects it
+// points at
+func parse(t *testing.T, data []byte) parsed {
+	t.Helper()
+
+	if !bytes.HasPrefix(data, []byte("%PDF-1.4\n")) {
+		t.Fatalf("missing PDF header")
+	}
+	m := regexp.MustCompile(`trailer\n<< /Size (\d+) /Root 1 0 R >>\nstartxref\n(\d+)\n%%EOF\n$`).FindSubmatch(data)
+	if m == nil {
+		t.Fatalf("missing trailer")
+	}
+	size, _ := strconv.Atoi(string(m[1]))
+	xref, _ := strconv.Atoi(string(m[2]))
+
+	header := fmt.Sprintf("xref\n0 %d\n", size)
+	if !bytes.HasPrefix(data[xref:], []byte(header)) {
+		t.Fatalf("startxref %d doesn't point at the xref table", xref)
+	}
+	table := data[xref+len(header):]
+	if !bytes.HasPrefix(table, []byte("0000000000 65535 f \n")) {
+		t.Fatalf("xref doesn't start with the free entry")
+	}
+
+	objects := make(map[int]string)
+	for n := 1; n < size; n++ {
+		entry := string(table[n*20 : n*20+20])
+		if !strings.HasSuffix(entry, " 00000 n \n") {
+			t.Fatalf("xref entry %d = %q", n, entry)
+		}
+		offset, err := strconv.Atoi(entry[:10])
+		if err != nil {
+			t.Fatalf("xref entry %d: %v", n, err)
+		}
+		prefix := fmt.Sprintf("%d 0 obj\n", n)
+		if !bytes.HasPrefix(data[offset:], []byte(prefix)) {
+			t.Fatalf("xref offset %d for object %d points at %q", offset, n, data[offset:min(offset+20, len(data))])
+		}
+		body := data[offset+len(prefix):]
+		end := bytes.Index(body, []byte("\nendobj\n"))
+		if end < 0 {
+			t.Fatalf("object %d isn't closed", n)
+		}
+		objects[n] = string(body[:end])
+	}
+	return parsed{objects: objects, size: size}
+}
+
+// pageContent inflates the content stream of the given page, counting from 0
+func (p parsed) pageContent(t *testing.T, page int) string {
+	t.Helper()
+
+	obj := p.objects[6+page*2]
+	m := regexp.MustCompile(`(?s)^<< /Length (\d+) /Filter /FlateDecode >>\nstream\n(.*)\nendstream$`).FindStringSubmatch(obj)
+	if m == nil {
+		t.Fatalf("object %d isn't a content stream", 6+page*2)
+	}
+	if length, _ := strconv.Atoi(m[1]); length != len(m[2]) {
+		t.Fatalf("stream /Length %d, want %d", length, len(m[2]))
+	}
+	zr, err := zlib.NewReader(strings.NewReader(m[2]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := io.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(content)
+}
+
+func TestBytesCrossReference(t *testing.T) {
+	tests := []struct {
+		name       string
+		paragraphs int
+		wantPages  int
+	}{
+		{name: "empty", paragraphs: 0, wantPages: 1},
+		{name: "one page", paragraphs: 5, wantPages: 1},
+		{name: "several pages", paragraphs: 120, wantPages: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := New()
+			for i := 0; i < tt.paragraphs; i++ {
+				doc.Paragraph(fmt.Sprintf("Paragraf %d (dengan kurung)", i), Body)
+				doc.Space(6)
+			}
+			data, err := doc.Bytes()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			p := parse(t, data)
+			if want := 5 + tt.wantPages*2; p.size != want {
+				t.Fatalf("/Size = %d, want %d for %d pages", p.size, want, tt.wantPages)
+			}
+			if !strings.Contains(p.objects[2], fmt.Sprintf("/Count %d", tt.wantPages)) {
+				t.Errorf("page tree = %s, want %d pages", p.objects[2], tt.wantPages)
+			}
+
+			next := 0
+			for page := 0; page < tt.wantPages; page++ {
+				if !strings.Contains(p.objects[5+page*2], fmt.Sprintf("/Contents %d 0 R", 6+page*2)) {
+					t.Errorf("page %d = %s", page+1, p.objects[5+page*2])
+				}
+				content := p.pageContent(t, page)
+				if !strings.Contains(content, fmt.Sprintf("(%d / %d) Tj", page+1, tt.wantPages)) {
+					t.Errorf("page %d lacks its page number", page+1)
+				}
+				// Paragraphs continue in order from one page to the next
+				for next < tt.paragraphs && strings.Contains(content, fmt.Sprintf(`(Paragraf %d \(dengan kurung\)) Tj`, next)) {
+					next++
+				}
+			}
+			if next != tt.paragraphs {
+				t.Errorf("found paragraphs up to %d in order, want %d", next, tt.paragraphs)
+			}
+		})
+	}
+}
+
+func TestParagraphStaysInMargins(t *testing.T) {
+	doc := New()
+	for i := 0; i < 40; i++ {
+		doc.Paragraph(strings.Repeat("kata panjang ", 60), Body)
+	}
+	data, err := doc.Bytes()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := parse(t, data)
+	pages := (p.size - 5) / 2
+	if pages < 2 {
+		t.Fatalf("got %d pages, want the text to overflow", pages)
+	}
+
+	position := regexp.MustCompile(`/F1 10\.50 Tf 0\.00 g ([\d.]+) ([\d.]+) Td`)
+	for page := 0; page < pages; page++ {
+		for _, m := range position.FindAllStringSubmatch(p.pageContent(t, page), -1) {
+			y, _ := strconv.ParseFloat(m[2], 64)
+			if y < marginBottom || y > pageHeight-marginTop {
+				t.Errorf("page %d has a line at y=%s, outside the margins", page+1, m[2])
+			}
+		}
+	}
+}
+
+func TestEncode(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "ASCII", in: "Halo, apa kabar?", want: "Halo, apa kabar?"},
+		{name: "Latin-1", in: "café déjà", want: "caf\xe9 d\xe9j\xe0"},
+		{name: "WinAnsi extras", in: "“kutip” – 5€…", want: "\x93kutip\x94 \x96 5\x80\x85"},
+		{name: "tab", in: "a\tb", want: "a    b"},
+		{name: "emoji dropped", in: "senang 😊", want: "senang "},
+		{name: "non-Latin dropped", in: "привет 你好 ok", want: "  ok"},
+		{name: "control characters dropped", in: "a\x00b\rc", want: "abc"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := encode(tt.in); got != tt.want {
+				t.Errorf("encode(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEscape(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "plain", want: "plain"},
+		{in: "(kurung)", want: `\(kurung\)`},
+		{in: `C:\path`, want: `C:\\path`},
+		{in: `\)`, want: `\\\)`},
+	}
+
+	for _, tt := range tests {
+		if got := escape(tt.in); got != tt.want {
+			t.Errorf("escape(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestWrap(t *testing.T) {
+	const size, maxWidth = 10.0, 100.0
+
+	tests := []struct {
+		name      string
+		text      string
+		wantLines int
+	}{
+		{name: "empty", text: "", wantLines: 1},
+		{name: "fits", text: "satu dua", wantLines: 1},
+		{name: "wraps at words", text: "satu dua tiga empat lima enam tujuh delapan", wantLines: 3},
+		{name: "breaks a long word", text: strings.Repeat("w", 40), wantLines: 4},
+		{name: "long word after short", text: "a " + strings.Repeat("w", 20), wantLines: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := wrap(tt.text, size, false, maxWidth)
+			if len(lines) != tt.wantLines {
+				t.Errorf("wrap gave %d lines %q, want %d", len(lines), lines, tt.wantLines)
+			}
+			for _, line := range lines {
+				if w := textWidth(line, size, false); w > maxWidth {
+					t.Errorf("line %q is %.1fpt wide, over %.0fpt", line, w, maxWidth)
+				}
+			}
+			// Wrapping only moves line breaks
+			if got := strings.Join(lines, ""); strings.ReplaceAll(got, " ", "") != strings.ReplaceAll(tt.text, " ", "") {
+				t.Errorf("wrap lost text: %q", lines)
+			}
+		})
+	}
+}
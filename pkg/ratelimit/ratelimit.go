@@ -0,0 +1,83 @@
+// Package ratelimit counts events per key in a sliding window, in memory.
+// Limits are per server instance.
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// Limiter allows at most Limit events per key within Window
+type Limiter struct {
+	limit  int
+	window time.Duration
+
+	mu     sync.Mutex
+	events map[string][]time.Time
+}
+
+// New returns a limiter. A non-positive limit allows everything.
+func New(limit int, window time.Duration) *Limiter {
+	return &Limiter{
+		limit:  limit,
+		window: window,
+		events: make(map[string][]time.Time),
+	}
+}
+
+// Allow records an event for key and reports whether it is within the limit.
+// When it isn't, retryAfter is how long until the oldest event leaves the
+// window. Rejected events are not counted.
+func (l *Limiter) Allow(key string) (allowed bool, retryAfter time.Duration) {
+	if l.limit <= 0 {
+		return true, 0
+	}
+
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	events := l.recent(key, now)
+	if len(events) >= l.limit {
+		l.events[key] = events
+		return false, events[0].Add(l.window).Sub(now)
+	}
+
+	l.events[key] = append(events, now)
+	return true, 0
+}
+
+// Reset forgets all events for key
+func (l *Limiter) Reset(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.events, key)
+}
+
+// Prune drops keys with no events inside the window, to bound memory
+func (l *Limiter) Prune() {
+	now := time.Now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for key := range l.events {
+		if events := l.recent(key, now); len(events) == 0 {
+			delete(l.events, key)
+		} else {
+			l.events[key] = events
+		}
+	}
+}
+
+func (l *Limiter) recent(key string, now time.Time) []time.Time {
+	events := l.events[key]
+	cutoff := now.Add(-l.window)
+
+	i := 0
+	for i < len(events) && !events[i].After(cutoff) {
+		i++
+	}
+	return events[i:]
+}
@@ -0,0 +1,128 @@
+package safety
+
+import (
+	"context"
+	"strings"
+	"unicode"
+)
+
+// Phrases are matched against lower-cased text with punctuation collapsed to
+// single spaces, so they should be written the same way.
+var lexicon = map[RiskLevel][]string{
+	RiskHigh: {
+		// Indonesian
+		"bunuh diri",
+		"ingin mati",
+		"pengen mati",
+		"pingin mati",
+		"mau mati aja",
+		"mengakhiri hidup",
+		"akhiri hidup",
+		"akhiri hidupku",
+		"tidak ingin hidup lagi",
+		"gak mau hidup lagi",
+		"nggak mau hidup lagi",
+		"ga mau hidup lagi",
+		"menyakiti diri",
+		"melukai diri",
+		"sayat tangan",
+		"menyayat tangan",
+		"gantung diri",
+		"loncat dari",
+		"minum racun",
+		// English
+		"kill myself",
+		"killing myself",
+		"suicide",
+		"suicidal",
+		"end my life",
+		"want to die",
+		"wanna die",
+		"self harm",
+		"hurt myself",
+		"cut myself",
+		"cutting myself",
+		"no reason to live",
+	},
+	RiskMedium: {
+		// Indonesian
+		"putus asa",
+		"tidak ada harapan",
+		"gak ada harapan",
+		"ingin menghilang",
+		"pengen menghilang",
+		"lebih baik aku tidak ada",
+		"lebih baik saya tidak ada",
+		"capek hidup",
+		"lelah hidup",
+		"tidak berguna",
+		"tidak berharga",
+		"beban bagi semua",
+		// English
+		"hopeless",
+		"worthless",
+		"can t go on",
+		"cannot go on",
+		"better off without me",
+		"want to disappear",
+		"tired of living",
+	},
+	RiskLow: {
+		// Indonesian
+		"depresi",
+		"serangan panik",
+		"kesepian",
+		"tidak bisa tidur",
+		"sangat sedih",
+		"menangis terus",
+		// English
+		"depressed",
+		"panic attack",
+		"lonely",
+		"can t sleep",
+	},
+}
+
+// LexiconClassifier matches known Indonesian and English crisis phrases
+type LexiconClassifier struct{}
+
+func NewLexiconClassifier() *LexiconClassifier {
+	return &LexiconClassifier{}
+}
+
+func (c *LexiconClassifier) Classify(ctx context.Context, text string) (Assessment, error) {
+	normalized := " " + normalize(text) + " "
+	result := Assessment{Level: RiskNone, Source: "lexicon"}
+
+	for _, level := range []RiskLevel{RiskHigh, RiskMedium, RiskLow} {
+		for _, phrase := range lexicon[level] {
+			if strings.Contains(normalized, " "+phrase+" ") {
+				result.Matches = append(result.Matches, phrase)
+				if riskRank[level] > riskRank[result.Level] {
+					result.Level = level
+				}
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// normalize lower-cases text and replaces every run of non-alphanumeric
+// characters with a single space
+func normalize(text string) string {
+	var sb strings.Builder
+	space := false
+	for _, r := range strings.ToLower(text) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			sb.WriteRune(r)
+			space = false
+			continue
+		}
+		if !space {
+			sb.WriteRune(' ')
+			space = true
+		}
+	}
+	return strings.TrimSpace(sb.String())
+}
@@ -0,0 +1,47 @@
+package safety
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Alfian57/ruang-tenang-api/pkg/llm"
+)
+
+const classifierPrompt = `You are a safety classifier for a mental-health support chat.
+Classify the user's message for risk of suicide or self-harm.
+Reply with exactly one word: NONE, LOW, MEDIUM or HIGH.
+HIGH: explicit intent, plan or desire to die or hurt oneself.
+MEDIUM: hopelessness, feeling like a burden, wanting to disappear.
+LOW: general distress, sadness, anxiety or loneliness.
+NONE: no signs of distress.`
+
+// ModelClassifier asks an LLM provider to rate the risk of a message
+type ModelClassifier struct {
+	provider llm.Provider
+}
+
+func NewModelClassifier(provider llm.Provider) *ModelClassifier {
+	return &ModelClassifier{provider: provider}
+}
+
+func (c *ModelClassifier) Classify(ctx context.Context, text string) (Assessment, error) {
+	reply, err := c.provider.Generate(ctx, classifierPrompt, []llm.Message{
+		{Role: llm.RoleUser, Content: text},
+	})
+	if err != nil {
+		return Assessment{}, fmt.Errorf("safety: model classifier failed: %w", err)
+	}
+
+	fields := strings.Fields(strings.ToLower(reply))
+	if len(fields) == 0 {
+		return Assessment{}, fmt.Errorf("safety: empty classifier reply")
+	}
+
+	level := RiskLevel(strings.Trim(fields[0], ".,!"))
+	if _, ok := riskRank[level]; !ok {
+		return Assessment{}, fmt.Errorf("safety: unexpected classifier reply %q", reply)
+	}
+
+	return Assessment{Level: level, Source: "model:" + c.provider.Model()}, nil
+}
@@ -0,0 +1,82 @@
+package safety
+
+import (
+	"context"
+	"strings"
+)
+
+type RiskLevel string
+
+const (
+	RiskNone   RiskLevel = "none"
+	RiskLow    RiskLevel = "low"
+	RiskMedium RiskLevel = "medium"
+	RiskHigh   RiskLevel = "high"
+)
+
+var riskRank = map[RiskLevel]int{
+	RiskNone:   0,
+	RiskLow:    1,
+	RiskMedium: 2,
+	RiskHigh:   3,
+}
+
+// AtLeast reports whether l is as severe as other
+func (l RiskLevel) AtLeast(other RiskLevel) bool {
+	return riskRank[l] >= riskRank[other]
+}
+
+// Assessment is the outcome of screening a piece of text
+type Assessment struct {
+	Level   RiskLevel
+	Matches []string
+	Source  string
+}
+
+// Classifier screens user text for crisis or self-harm risk
+type Classifier interface {
+	Classify(ctx context.Context, text string) (Assessment, error)
+}
+
+// CrisisResources is appended to AI replies for high-risk messages
+const CrisisResources = `Kamu tidak sendirian, dan keselamatanmu sangat penting. Jika kamu berpikir untuk menyakiti diri sendiri atau sedang dalam bahaya, segera hubungi bantuan:
+- Layanan darurat: 112
+- Layanan konseling kesehatan jiwa Kemenkes (SEJIWA): 119 ext. 8
+- Datang ke IGD rumah sakit terdekat atau hubungi orang yang kamu percaya
+
+If you are outside Indonesia, please contact your local emergency number or a crisis line in your country.`
+
+// WithCrisisResources appends the crisis hotline block to a reply
+func WithCrisisResources(reply string) string {
+	return strings.TrimRight(reply, "\n") + "\n\n" + CrisisResources
+}
+
+// MultiClassifier runs several classifiers and keeps the most severe result.
+// A failing classifier is skipped so the lexicon always acts as a safety net.
+type MultiClassifier struct {
+	classifiers []Classifier
+	onError     func(error)
+}
+
+func NewMultiClassifier(onError func(error), classifiers ...Classifier) *MultiClassifier {
+	return &MultiClassifier{classifiers: classifiers, onError: onError}
+}
+
+func (m *MultiClassifier) Classify(ctx context.Context, text string) (Assessment, error) {
+	result := Assessment{Level: RiskNone}
+	for _, c := range m.classifiers {
+		a, err := c.Classify(ctx, text)
+		if err != nil {
+			if m.onError != nil {
+				m.onError(err)
+			}
+			continue
+		}
+		if riskRank[a.Level] > riskRank[result.Level] {
+			result.Level = a.Level
+			result.Source = a.Source
+		}
+		result.Matches = append(result.Matches, a.Matches...)
+	}
+	return result, nil
+}
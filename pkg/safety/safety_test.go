@@ -0,0 +1,134 @@
+package safety
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Alfian57/ruang-tenang-api/pkg/llm"
+)
+
+func TestLexiconClassifier(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want RiskLevel
+	}{
+		{name: "neutral", text: "Hari ini aku jalan-jalan ke taman", want: RiskNone},
+		{name: "low", text: "Aku merasa kesepian belakangan ini", want: RiskLow},
+		{name: "medium", text: "Rasanya sudah putus asa", want: RiskMedium},
+		{name: "high indonesian", text: "Aku ingin bunuh diri", want: RiskHigh},
+		{name: "high english", text: "I want to die", want: RiskHigh},
+		{name: "case and punctuation", text: "AKU... INGIN MATI!!!", want: RiskHigh},
+		{name: "apostrophe", text: "I can't sleep anymore", want: RiskLow},
+		{name: "most severe wins", text: "Aku depresi dan mau mati aja", want: RiskHigh},
+		{name: "whole words only", text: "Kita makan bakso bunuhan", want: RiskNone},
+		{name: "phrase split across words", text: "bunuh nyamuk diri sendiri", want: RiskNone},
+		{name: "empty", text: "", want: RiskNone},
+	}
+
+	c := NewLexiconClassifier()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.Classify(context.Background(), tt.text)
+			if err != nil {
+				t.Fatalf("Classify: %v", err)
+			}
+			if got.Level != tt.want {
+				t.Errorf("Classify(%q) = %s (matches %v), want %s", tt.text, got.Level, got.Matches, tt.want)
+			}
+			if got.Level != RiskNone && len(got.Matches) == 0 {
+				t.Errorf("Classify(%q) has no matches", tt.text)
+			}
+		})
+	}
+}
+
+// replyProvider answers every prompt with a fixed reply or error
+type replyProvider struct {
+	*llm.StubProvider
+	reply string
+	err   error
+}
+
+func (p *replyProvider) Generate(ctx context.Context, systemPrompt string, history []llm.Message) (string, error) {
+	return p.reply, p.err
+}
+
+func TestModelClassifier(t *testing.T) {
+	tests := []struct {
+		name    string
+		reply   string
+		err     error
+		want    RiskLevel
+		wantErr bool
+	}{
+		{name: "high", reply: "HIGH", want: RiskHigh},
+		{name: "trailing punctuation", reply: "Medium.", want: RiskMedium},
+		{name: "explanation after the label", reply: "low - sounds tired", want: RiskLow},
+		{name: "unknown label", reply: "maybe", wantErr: true},
+		{name: "empty", reply: "", wantErr: true},
+		{name: "provider error", err: errors.New("timeout"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewModelClassifier(&replyProvider{StubProvider: llm.NewStubProvider(), reply: tt.reply, err: tt.err})
+			got, err := c.Classify(context.Background(), "any text")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Classify error = %v, want error %v", err, tt.wantErr)
+			}
+			if err == nil && got.Level != tt.want {
+				t.Errorf("Classify = %s, want %s", got.Level, tt.want)
+			}
+		})
+	}
+}
+
+func TestMultiClassifierKeepsMostSevere(t *testing.T) {
+	failing := NewModelClassifier(&replyProvider{StubProvider: llm.NewStubProvider(), err: errors.New("down")})
+	tests := []struct {
+		name        string
+		classifiers []Classifier
+		text        string
+		want        RiskLevel
+		wantErrors  int
+	}{
+		{
+			name:        "model raises the lexicon level",
+			classifiers: []Classifier{NewLexiconClassifier(), NewModelClassifier(&replyProvider{StubProvider: llm.NewStubProvider(), reply: "HIGH"})},
+			text:        "Aku merasa kesepian",
+			want:        RiskHigh,
+		},
+		{
+			name:        "lexicon still flags when the model fails",
+			classifiers: []Classifier{NewLexiconClassifier(), failing},
+			text:        "I want to die",
+			want:        RiskHigh,
+			wantErrors:  1,
+		},
+		{
+			name:        "lower model level doesn't lower the lexicon's",
+			classifiers: []Classifier{NewLexiconClassifier(), NewModelClassifier(&replyProvider{StubProvider: llm.NewStubProvider(), reply: "NONE"})},
+			text:        "Rasanya sudah putus asa",
+			want:        RiskMedium,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := 0
+			m := NewMultiClassifier(func(error) { errs++ }, tt.classifiers...)
+			got, err := m.Classify(context.Background(), tt.text)
+			if err != nil {
+				t.Fatalf("Classify: %v", err)
+			}
+			if got.Level != tt.want {
+				t.Errorf("Classify = %s, want %s", got.Level, tt.want)
+			}
+			if errs != tt.wantErrors {
+				t.Errorf("reported %d errors, want %d", errs, tt.wantErrors)
+			}
+		})
+	}
+}
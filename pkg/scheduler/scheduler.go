@@ -0,0 +1,96 @@
+// Package scheduler runs periodic background jobs inside the API process.
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Alfian57/ruang-tenang-api/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Job is a task that runs every Interval. Runs of the same job never overlap.
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+type Scheduler struct {
+	jobs   []Job
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func New() *Scheduler {
+	return &Scheduler{}
+}
+
+// Register adds a job. Jobs with a non-positive interval are disabled.
+func (s *Scheduler) Register(job Job) {
+	if job.Interval <= 0 {
+		logger.Info(fmt.Sprintf("Job %s disabled", job.Name))
+		return
+	}
+	s.jobs = append(s.jobs, job)
+}
+
+// Start runs every registered job once and then on its interval until ctx is
+// cancelled or Stop is called
+func (s *Scheduler) Start(ctx context.Context) {
+	ctx, s.cancel = context.WithCancel(ctx)
+
+	for _, job := range s.jobs {
+		s.wg.Add(1)
+		go func(job Job) {
+			defer s.wg.Done()
+			s.loop(ctx, job)
+		}(job)
+	}
+}
+
+// Stop cancels running jobs and waits for them to return
+func (s *Scheduler) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.wg.Wait()
+}
+
+func (s *Scheduler) loop(ctx context.Context, job Job) {
+	logger.Info(fmt.Sprintf("Job %s scheduled every %s", job.Name, job.Interval))
+
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		s.runOnce(ctx, job)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runOnce executes a job, turning panics into logged errors so one bad run
+// doesn't stop the schedule
+func (s *Scheduler) runOnce(ctx context.Context, job Job) {
+	start := time.Now()
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("Job panicked", zap.String("job", job.Name), zap.Any("panic", r))
+		}
+	}()
+
+	if err := job.Run(ctx); err != nil {
+		logger.Error("Job failed",
+			zap.String("job", job.Name),
+			zap.Duration("duration", time.Since(start)),
+			zap.Error(err),
+		)
+	}
+}
@@ -0,0 +1,74 @@
+package stt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/generative-ai-go/genai"
+	"google.golang.org/api/option"
+)
+
+const (
+	DefaultGeminiModel = "gemini-flash-latest"
+
+	geminiInstruction = "Transkripsikan rekaman suara ini kata demi kata dalam bahasa yang diucapkan. Balas hanya dengan transkripnya, tanpa komentar, label pembicara, atau tanda waktu."
+)
+
+// GeminiTranscriber sends the audio inline to a multimodal Gemini model
+type GeminiTranscriber struct {
+	client    *genai.Client
+	modelName string
+}
+
+func NewGeminiTranscriber(apiKey, modelName string) (*GeminiTranscriber, error) {
+	if apiKey == "" {
+		return nil, errors.New("stt: GEMINI_API_KEY is required for the gemini provider")
+	}
+	if modelName == "" {
+		modelName = DefaultGeminiModel
+	}
+
+	client, err := genai.NewClient(context.Background(), option.WithAPIKey(apiKey))
+	if err != nil {
+		return nil, fmt.Errorf("stt: failed to create Gemini client: %w", err)
+	}
+
+	return &GeminiTranscriber{client: client, modelName: modelName}, nil
+}
+
+func (t *GeminiTranscriber) Name() string {
+	return ProviderGemini
+}
+
+func (t *GeminiTranscriber) Transcribe(ctx context.Context, audio []byte, mimeType string) (string, error) {
+	if len(audio) == 0 {
+		return "", errors.New("stt: empty audio")
+	}
+
+	model := t.client.GenerativeModel(t.modelName)
+	resp, err := model.GenerateContent(ctx, genai.Blob{MIMEType: mimeType, Data: audio}, genai.Text(geminiInstruction))
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	for _, cand := range resp.Candidates {
+		if cand.Content == nil {
+			continue
+		}
+		for _, part := range cand.Content.Parts {
+			if text, ok := part.(genai.Text); ok {
+				sb.WriteString(string(text))
+			}
+		}
+		break
+	}
+
+	transcript := strings.TrimSpace(sb.String())
+	if transcript == "" {
+		return "", errors.New("stt: empty transcript from Gemini")
+	}
+	return transcript, nil
+}
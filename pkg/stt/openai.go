@@ -0,0 +1,122 @@
+package stt
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultOpenAIBaseURL = "https://api.openai.com/v1"
+	DefaultOpenAIModel   = "whisper-1"
+)
+
+// OpenAITranscriber talks to any server implementing the OpenAI
+// /audio/transcriptions API (OpenAI Whisper, faster-whisper servers, ...)
+type OpenAITranscriber struct {
+	baseURL    string
+	apiKey     string
+	modelName  string
+	httpClient *http.Client
+}
+
+func NewOpenAITranscriber(baseURL, apiKey, modelName string) (*OpenAITranscriber, error) {
+	if baseURL == "" {
+		baseURL = DefaultOpenAIBaseURL
+	}
+	if modelName == "" {
+		modelName = DefaultOpenAIModel
+	}
+
+	return &OpenAITranscriber{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		apiKey:     apiKey,
+		modelName:  modelName,
+		httpClient: &http.Client{Timeout: 120 * time.Second},
+	}, nil
+}
+
+func (t *OpenAITranscriber) Name() string {
+	return ProviderOpenAI
+}
+
+type openAITranscription struct {
+	Text  string `json:"text"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+func (t *OpenAITranscriber) Transcribe(ctx context.Context, audio []byte, mimeType string) (string, error) {
+	if len(audio) == 0 {
+		return "", errors.New("stt: empty audio")
+	}
+
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	if err := form.WriteField("model", t.modelName); err != nil {
+		return "", err
+	}
+	part, err := form.CreateFormFile("file", "audio"+extensionFor(mimeType))
+	if err != nil {
+		return "", err
+	}
+	if _, err := part.Write(audio); err != nil {
+		return "", err
+	}
+	if err := form.Close(); err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.baseURL+"/audio/transcriptions", &body)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	if t.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+t.apiKey)
+	}
+
+	resp, err := t.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var out openAITranscription
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return "", fmt.Errorf("stt: invalid response (status %d): %w", resp.StatusCode, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		if out.Error != nil {
+			return "", fmt.Errorf("stt: %s", out.Error.Message)
+		}
+		return "", fmt.Errorf("stt: unexpected status %d", resp.StatusCode)
+	}
+
+	transcript := strings.TrimSpace(out.Text)
+	if transcript == "" {
+		return "", errors.New("stt: empty transcript")
+	}
+	return transcript, nil
+}
+
+// extensionFor gives the upload a file name the server can sniff the format from
+func extensionFor(mimeType string) string {
+	switch mimeType {
+	case "audio/wav":
+		return ".wav"
+	case "audio/ogg":
+		return ".ogg"
+	case "audio/webm":
+		return ".webm"
+	default:
+		return ".mp3"
+	}
+}
@@ -0,0 +1,74 @@
+package stt
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+const (
+	ProviderGemini = "gemini"
+	ProviderOpenAI = "openai"
+	ProviderStub   = "stub"
+)
+
+// Transcriber turns recorded speech into text
+type Transcriber interface {
+	Name() string
+	Transcribe(ctx context.Context, audio []byte, mimeType string) (string, error)
+}
+
+// Options selects and configures a transcriber
+type Options struct {
+	Provider string // gemini, openai or stub
+	BaseURL  string // OpenAI-compatible servers only
+	APIKey   string
+	Model    string        // empty uses the provider's default
+	Timeout  time.Duration // bounds each request; zero leaves the provider's own limit
+}
+
+// NewTranscriber builds the transcriber selected by opts.Provider.
+// When no provider is given, Gemini is used if an API key is present,
+// otherwise the offline stub.
+func NewTranscriber(opts Options) (Transcriber, error) {
+	name := opts.Provider
+	if name == "" {
+		name = ProviderStub
+		if opts.APIKey != "" {
+			name = ProviderGemini
+		}
+	}
+
+	var transcriber Transcriber
+	var err error
+	switch name {
+	case ProviderGemini:
+		transcriber, err = NewGeminiTranscriber(opts.APIKey, opts.Model)
+	case ProviderOpenAI:
+		transcriber, err = NewOpenAITranscriber(opts.BaseURL, opts.APIKey, opts.Model)
+	case ProviderStub:
+		transcriber = NewStubTranscriber()
+	default:
+		return nil, fmt.Errorf("stt: unknown provider %q", name)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.Timeout > 0 {
+		transcriber = &timeoutTranscriber{Transcriber: transcriber, timeout: opts.Timeout}
+	}
+	return transcriber, nil
+}
+
+// timeoutTranscriber cancels requests that run longer than timeout
+type timeoutTranscriber struct {
+	Transcriber
+	timeout time.Duration
+}
+
+func (t *timeoutTranscriber) Transcribe(ctx context.Context, audio []byte, mimeType string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, t.timeout)
+	defer cancel()
+	return t.Transcriber.Transcribe(ctx, audio, mimeType)
+}
@@ -0,0 +1,49 @@
+package stt
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// blockingTranscriber answers only once its context is done
+type blockingTranscriber struct{}
+
+func (blockingTranscriber) Name() string { return "blocking" }
+
+func (blockingTranscriber) Transcribe(ctx context.Context, _ []byte, _ string) (string, error) {
+	<-ctx.Done()
+	return "", ctx.Err()
+}
+
+func TestTimeoutTranscriberCancelsSlowRequests(t *testing.T) {
+	transcriber := &timeoutTranscriber{Transcriber: blockingTranscriber{}, timeout: 10 * time.Millisecond}
+
+	if _, err := transcriber.Transcribe(context.Background(), []byte("audio"), "audio/webm"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Transcribe error = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestNewTranscriberWrapsTimeout(t *testing.T) {
+	tests := []struct {
+		name        string
+		timeout     time.Duration
+		wantTimeout bool
+	}{
+		{name: "with timeout", timeout: time.Minute, wantTimeout: true},
+		{name: "without timeout"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transcriber, err := NewTranscriber(Options{Provider: ProviderStub, Timeout: tt.timeout})
+			if err != nil {
+				t.Fatalf("NewTranscriber: %v", err)
+			}
+			if _, ok := transcriber.(*timeoutTranscriber); ok != tt.wantTimeout {
+				t.Errorf("transcriber %T, want timeout wrapper %v", transcriber, tt.wantTimeout)
+			}
+		})
+	}
+}
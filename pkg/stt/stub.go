@@ -0,0 +1,36 @@
+package stt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// StubTranscriber returns a fixed transcript without calling any service.
+// It is meant for local development and tests.
+type StubTranscriber struct {
+	transcript string
+}
+
+func NewStubTranscriber() *StubTranscriber {
+	return &StubTranscriber{}
+}
+
+// NewStubTranscriberWithText always returns the given transcript
+func NewStubTranscriberWithText(transcript string) *StubTranscriber {
+	return &StubTranscriber{transcript: transcript}
+}
+
+func (t *StubTranscriber) Name() string {
+	return ProviderStub
+}
+
+func (t *StubTranscriber) Transcribe(ctx context.Context, audio []byte, mimeType string) (string, error) {
+	if len(audio) == 0 {
+		return "", errors.New("stt: empty audio")
+	}
+	if t.transcript != "" {
+		return t.transcript, nil
+	}
+	return fmt.Sprintf("Pesan suara (%d KB)", (len(audio)+1023)/1024), nil
+}
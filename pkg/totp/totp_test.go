@@ -0,0 +1,115 @@
+package totp
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret is the RFC 6238 SHA-1 test key "12345678901234567890"
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestCodeMatchesRFC6238(t *testing.T) {
+	// The RFC lists 8-digit codes; 6-digit codes are their last six digits
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{unix: 59, want: "287082"},
+		{unix: 1111111109, want: "081804"},
+		{unix: 1111111111, want: "050471"},
+		{unix: 1234567890, want: "005924"},
+		{unix: 2000000000, want: "279037"},
+	}
+
+	for _, tt := range tests {
+		got, err := Code(rfcSecret, Step(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatalf("Code: %v", err)
+		}
+		if got != tt.want {
+			t.Errorf("Code at %d = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestValidateDrift(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	current := Step(now)
+
+	tests := []struct {
+		name     string
+		codeAt   time.Time // when the authenticator generated the code
+		wantOK   bool
+		wantStep int64
+	}{
+		{name: "current step", codeAt: now, wantOK: true, wantStep: current},
+		{name: "one step behind", codeAt: now.Add(-Period), wantOK: true, wantStep: current - 1},
+		{name: "one step ahead", codeAt: now.Add(Period), wantOK: true, wantStep: current + 1},
+		{name: "two steps behind", codeAt: now.Add(-2 * Period)},
+		{name: "two steps ahead", codeAt: now.Add(2 * Period)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, err := Code(rfcSecret, Step(tt.codeAt))
+			if err != nil {
+				t.Fatalf("Code: %v", err)
+			}
+			step, ok := Validate(rfcSecret, code, now)
+			if ok != tt.wantOK {
+				t.Fatalf("Validate ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && step != tt.wantStep {
+				t.Errorf("Validate step = %d, want %d", step, tt.wantStep)
+			}
+		})
+	}
+}
+
+func TestValidateInput(t *testing.T) {
+	now := time.Unix(1111111111, 0) // code "050471"
+
+	tests := []struct {
+		name   string
+		secret string
+		code   string
+		wantOK bool
+	}{
+		{name: "valid", secret: rfcSecret, code: "050471", wantOK: true},
+		{name: "spaces", secret: rfcSecret, code: " 050 471 ", wantOK: true},
+		{name: "lower-case secret", secret: strings.ToLower(rfcSecret), code: "050471", wantOK: true},
+		{name: "wrong code", secret: rfcSecret, code: "050472"},
+		{name: "too short", secret: rfcSecret, code: "50471"},
+		{name: "too long", secret: rfcSecret, code: "0504710"},
+		{name: "empty", secret: rfcSecret, code: ""},
+		{name: "invalid secret", secret: "not base32!", code: "050471"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, ok := Validate(tt.secret, tt.code, now); ok != tt.wantOK {
+				t.Errorf("Validate(%q) = %v, want %v", tt.code, ok, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestGenerateSecretRoundTrip(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatalf("GenerateSecret: %v", err)
+	}
+	if len(secret) != 32 {
+		t.Errorf("secret length = %d, want 32 base32 characters", len(secret))
+	}
+
+	now := time.Now()
+	code, err := Code(secret, Step(now))
+	if err != nil {
+		t.Fatalf("Code: %v", err)
+	}
+	if _, ok := Validate(secret, code, now); !ok {
+		t.Error("a freshly generated code doesn't validate")
+	}
+}
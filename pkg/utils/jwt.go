@@ -12,16 +12,22 @@ type JWTClaims struct {
 	UserID uint   `json:"user_id"`
 	Email  string `json:"email"`
 	Role   string `json:"role"`
+	// SessionID is the auth session (device) the token was issued for
+	SessionID uint `json:"sid"`
+	// TokenVersion must match the user's current version for the token to be valid
+	TokenVersion int `json:"tv"`
 	jwt.RegisteredClaims
 }
 
-func GenerateToken(userID uint, email, role string, duration time.Duration) (string, error) {
+func GenerateToken(userID uint, email, role string, sessionID uint, tokenVersion int, duration time.Duration) (string, error) {
 	cfg := config.AppConfig
 
 	claims := JWTClaims{
-		UserID: userID,
-		Email:  email,
-		Role:   role,
+		UserID:       userID,
+		Email:        email,
+		Role:         role,
+		SessionID:    sessionID,
+		TokenVersion: tokenVersion,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(duration)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -53,3 +59,48 @@ func ValidateToken(tokenString string) (*JWTClaims, error) {
 
 	return nil, errors.New("invalid token")
 }
+
+// twoFactorAudience keeps second-step tokens from being accepted anywhere else
+const twoFactorAudience = "two_factor"
+
+// TwoFactorClaims identify a sign-in whose password was verified and that is
+// waiting for the second factor
+type TwoFactorClaims struct {
+	UserID       uint   `json:"uid"`
+	TokenVersion int    `json:"tv"`
+	RememberMe   bool   `json:"rm"`
+	DeviceName   string `json:"dev,omitempty"`
+	jwt.RegisteredClaims
+}
+
+func GenerateTwoFactorToken(userID uint, tokenVersion int, rememberMe bool, deviceName string, duration time.Duration) (string, error) {
+	cfg := config.AppConfig
+
+	claims := TwoFactorClaims{
+		UserID:       userID,
+		TokenVersion: tokenVersion,
+		RememberMe:   rememberMe,
+		DeviceName:   deviceName,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Audience:  jwt.ClaimStrings{twoFactorAudience},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(duration)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(cfg.JWTSecret))
+}
+
+func ValidateTwoFactorToken(tokenString string) (*TwoFactorClaims, error) {
+	cfg := config.AppConfig
+
+	claims := &TwoFactorClaims{}
+	_, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		return []byte(cfg.JWTSecret), nil
+	}, jwt.WithValidMethods([]string{"HS256"}), jwt.WithAudience(twoFactorAudience), jwt.WithExpirationRequired())
+	if err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
@@ -2,6 +2,7 @@ package utils
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
 )
 
@@ -12,3 +13,10 @@ func GenerateRandomString(n int) (string, error) {
 	}
 	return hex.EncodeToString(bytes), nil
 }
+
+// HashToken returns the hex SHA-256 of an opaque token so only the hash needs
+// to be stored
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,97 @@
+package webpush
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// recordSize is the aes128gcm record size. Payloads are sent as a single
+// record, so they must fit in one.
+const recordSize = 4096
+
+// maxPayload leaves room in the record for the delimiter and the AEAD tag
+const maxPayload = recordSize - 17
+
+// encrypt builds an aes128gcm message body (RFC 8188) for the subscription,
+// deriving the content key as described in RFC 8291
+func encrypt(sub Subscription, payload []byte) ([]byte, error) {
+	if len(payload) > maxPayload {
+		return nil, fmt.Errorf("webpush: payload of %d bytes is too large", len(payload))
+	}
+
+	rawUAPublic, err := decodeBase64(sub.P256dh)
+	if err != nil {
+		return nil, fmt.Errorf("webpush: invalid p256dh key: %w", err)
+	}
+	uaPublic, err := ecdh.P256().NewPublicKey(rawUAPublic)
+	if err != nil {
+		return nil, fmt.Errorf("webpush: invalid p256dh key: %w", err)
+	}
+	authSecret, err := decodeBase64(sub.Auth)
+	if err != nil || len(authSecret) == 0 {
+		return nil, errors.New("webpush: invalid auth secret")
+	}
+
+	// A fresh key pair and salt per message
+	asPrivate, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	asPublic := asPrivate.PublicKey().Bytes()
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	sharedSecret, err := asPrivate.ECDH(uaPublic)
+	if err != nil {
+		return nil, fmt.Errorf("webpush: %w", err)
+	}
+
+	keyInfo := append([]byte("WebPush: info\x00"), rawUAPublic...)
+	keyInfo = append(keyInfo, asPublic...)
+	ikm, err := hkdf.Key(sha256.New, sharedSecret, authSecret, string(keyInfo), 32)
+	if err != nil {
+		return nil, err
+	}
+
+	prk, err := hkdf.Extract(sha256.New, ikm, salt)
+	if err != nil {
+		return nil, err
+	}
+	cek, err := hkdf.Expand(sha256.New, prk, "Content-Encoding: aes128gcm\x00", 16)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := hkdf.Expand(sha256.New, prk, "Content-Encoding: nonce\x00", 12)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	// Header: salt, record size, key ID length and the sender's public key
+	header := make([]byte, 0, 16+4+1+len(asPublic))
+	header = append(header, salt...)
+	header = binary.BigEndian.AppendUint32(header, recordSize)
+	header = append(header, byte(len(asPublic)))
+	header = append(header, asPublic...)
+
+	// 0x02 marks the last (and only) record
+	plaintext := append(append([]byte{}, payload...), 0x02)
+	return gcm.Seal(header, nonce, plaintext, nil), nil
+}
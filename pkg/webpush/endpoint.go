@@ -0,0 +1,79 @@
+package webpush
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// ErrPrivateEndpoint means an endpoint points into a private network. Push
+// services are public, and the server must not be used to reach anything else.
+var ErrPrivateEndpoint = errors.New("webpush: endpoint is not a public address")
+
+// cgnat is the carrier-grade NAT range, private in practice
+var cgnat = netip.MustParsePrefix("100.64.0.0/10")
+
+// checkEndpoint requires an https URL on the default port whose host isn't
+// localhost or a non-public IP. Hostnames are checked again when dialing.
+func checkEndpoint(raw string) (*url.URL, error) {
+	endpoint, err := url.Parse(raw)
+	if err != nil || endpoint.Scheme != "https" || endpoint.Hostname() == "" || endpoint.User != nil {
+		return nil, fmt.Errorf("webpush: invalid endpoint %q", raw)
+	}
+	if port := endpoint.Port(); port != "" && port != "443" {
+		return nil, fmt.Errorf("webpush: invalid endpoint %q: unexpected port", raw)
+	}
+
+	host := strings.ToLower(strings.TrimSuffix(endpoint.Hostname(), "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return nil, ErrPrivateEndpoint
+	}
+	if addr, err := netip.ParseAddr(host); err == nil && !publicAddr(addr) {
+		return nil, ErrPrivateEndpoint
+	}
+	return endpoint, nil
+}
+
+// publicAddr reports whether addr is routable on the internet
+func publicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsGlobalUnicast() &&
+		!addr.IsPrivate() &&
+		!addr.IsLoopback() &&
+		!addr.IsLinkLocalUnicast() &&
+		!cgnat.Contains(addr)
+}
+
+// newHTTPClient builds a client that only connects to public addresses, so a
+// hostname resolving into a private network is refused too. It doesn't follow
+// redirects or use a proxy, which would bypass the check.
+func newHTTPClient() *http.Client {
+	dialer := &net.Dialer{
+		Timeout: 10 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil || !publicAddr(addrPort.Addr()) {
+				return ErrPrivateEndpoint
+			}
+			return nil
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   30 * time.Second,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
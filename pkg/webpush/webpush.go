@@ -0,0 +1,183 @@
+// Package webpush sends Web Push notifications to browser subscriptions,
+// with payloads encrypted per RFC 8291 and VAPID authentication (RFC 8292).
+package webpush
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// vapidTokenTTL is how long a VAPID token is valid; push services reject
+// tokens valid for more than 24 hours
+const vapidTokenTTL = 12 * time.Hour
+
+// ErrSubscriptionGone means the push service no longer knows the
+// subscription, e.g. the user revoked permission; it should be deleted
+var ErrSubscriptionGone = errors.New("webpush: subscription expired or unsubscribed")
+
+// Subscription is what the browser's PushManager.subscribe() returns
+type Subscription struct {
+	Endpoint string
+	P256dh   string // base64url encoded public key of the browser
+	Auth     string // base64url encoded authentication secret
+}
+
+// Validate checks the subscription's endpoint and keys, so a broken
+// subscription is rejected when it is saved rather than on every send.
+// Endpoints must be public https URLs.
+func (s Subscription) Validate() error {
+	if _, err := checkEndpoint(s.Endpoint); err != nil {
+		return err
+	}
+	key, err := decodeBase64(s.P256dh)
+	if err != nil {
+		return fmt.Errorf("webpush: invalid p256dh key: %w", err)
+	}
+	if _, err := ecdh.P256().NewPublicKey(key); err != nil {
+		return fmt.Errorf("webpush: invalid p256dh key: %w", err)
+	}
+	if auth, err := decodeBase64(s.Auth); err != nil || len(auth) != 16 {
+		return errors.New("webpush: invalid auth secret")
+	}
+	return nil
+}
+
+// Client sends push messages signed with the application's VAPID key pair
+type Client struct {
+	publicKey  string
+	privateKey *ecdsa.PrivateKey
+	subject    string
+	http       *http.Client
+}
+
+// New creates a client from base64url encoded VAPID keys, as printed by
+// GenerateVAPIDKeys. The subject is a mailto: or https: contact URL.
+func New(publicKey, privateKey, subject string) (*Client, error) {
+	raw, err := decodeBase64(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("webpush: invalid VAPID private key: %w", err)
+	}
+	key, err := ecdh.P256().NewPrivateKey(raw)
+	if err != nil {
+		return nil, fmt.Errorf("webpush: invalid VAPID private key: %w", err)
+	}
+
+	public := key.PublicKey().Bytes()
+	if encoded := base64.RawURLEncoding.EncodeToString(public); encoded != trimPadding(publicKey) {
+		return nil, errors.New("webpush: VAPID public key does not match the private key")
+	}
+
+	return &Client{
+		publicKey: base64.RawURLEncoding.EncodeToString(public),
+		privateKey: &ecdsa.PrivateKey{
+			PublicKey: ecdsa.PublicKey{
+				Curve: elliptic.P256(),
+				X:     new(big.Int).SetBytes(public[1:33]),
+				Y:     new(big.Int).SetBytes(public[33:]),
+			},
+			D: new(big.Int).SetBytes(raw),
+		},
+		subject: subject,
+		http:    newHTTPClient(),
+	}, nil
+}
+
+// PublicKey is the applicationServerKey browsers subscribe with
+func (c *Client) PublicKey() string {
+	return c.publicKey
+}
+
+// Send encrypts the payload for the subscription and posts it to its push
+// service. The push service keeps undelivered messages for ttl. Endpoints
+// that resolve to a private address are refused with ErrPrivateEndpoint.
+func (c *Client) Send(ctx context.Context, sub Subscription, payload []byte, ttl time.Duration) error {
+	if err := sub.Validate(); err != nil {
+		return err
+	}
+	endpoint, _ := url.Parse(sub.Endpoint)
+
+	body, err := encrypt(sub, payload)
+	if err != nil {
+		return err
+	}
+
+	token, err := c.vapidToken(endpoint.Scheme + "://" + endpoint.Host)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("vapid t=%s, k=%s", token, c.publicKey))
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("TTL", strconv.Itoa(int(ttl.Seconds())))
+	req.Header.Set("Urgency", "normal")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("webpush: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return ErrSubscriptionGone
+	case resp.StatusCode >= 300:
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("webpush: push service returned %d: %s", resp.StatusCode, bytes.TrimSpace(msg))
+	}
+	return nil
+}
+
+func (c *Client) vapidToken(audience string) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"aud": audience,
+		"exp": time.Now().Add(vapidTokenTTL).Unix(),
+		"sub": c.subject,
+	})
+	signed, err := token.SignedString(c.privateKey)
+	if err != nil {
+		return "", fmt.Errorf("webpush: failed to sign VAPID token: %w", err)
+	}
+	return signed, nil
+}
+
+// GenerateVAPIDKeys creates a new application key pair, base64url encoded
+func GenerateVAPIDKeys() (publicKey, privateKey string, err error) {
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return "", "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(key.PublicKey().Bytes()),
+		base64.RawURLEncoding.EncodeToString(key.Bytes()), nil
+}
+
+// decodeBase64 accepts the URL-safe alphabet browsers use, padded or not
+func decodeBase64(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(trimPadding(s))
+}
+
+func trimPadding(s string) string {
+	for len(s) > 0 && s[len(s)-1] == '=' {
+		s = s[:len(s)-1]
+	}
+	return s
+}